@@ -8,15 +8,22 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 
+	"blog-app/internal/access"
+	"blog-app/internal/blob"
+	"blog-app/internal/config"
 	"blog-app/internal/database"
 	"blog-app/internal/handlers"
 	"blog-app/internal/middleware"
+	"blog-app/internal/models"
+	"blog-app/internal/moderation"
+	"blog-app/internal/scheduler"
 )
 
 func main() {
@@ -24,39 +31,185 @@ func main() {
 	log.SetOutput(os.Stdout)
 	log.Println("Starting API server...")
 
-	// Initialize database connection
-	db, err := database.NewConnection()
+	// Load and validate the configuration
+	cfg, err := config.Load(os.Args[1:])
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	log.Printf("Running in %s mode", cfg.Env)
+
+	// Initialize the store
+	var store models.Store
+	if cfg.Database.Driver == "memory" {
+		log.Println("Using in-memory store, data will not be persisted")
+		store = models.NewMemoryStore()
+	} else {
+		db, err := database.NewConnection(cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to connect to database: %v", err)
+		}
+		defer db.Close()
+
+		// Apply pending migrations if enabled
+		applied, err := database.AutoMigrate(db, cfg.Database)
+		if err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+		sqlStore := models.NewSQLStore(db)
+		if applied > 0 {
+			log.Printf("Applied %d database migrations", applied)
+
+			// Posts written before search existed have no search text or word count yet
+			if _, err := sqlStore.FillSearchText(); err != nil {
+				log.Fatalf("Failed to fill in search text: %v", err)
+			}
+		}
+
+		store = sqlStore
+	}
+
+	// Set up token lifetimes and signing keys
+	authConfig := cfg.Auth
+	if authConfig.Keys.Ephemeral() {
+		log.Printf("JWT_SIGNING_KEY_PATH is not set; signing tokens with a generated key that is lost on restart")
+	}
+
+	// Set up comment moderation
+	spamScorer := moderation.NewScorer(cfg.Spam)
+	moderators := moderation.NewPolicy()
+
+	// Promote the users named in ADMIN_USERNAMES, who moderated every comment before roles existed
+	if err := promoteAdmins(store, cfg.AdminUsernames); err != nil {
+		log.Fatalf("Failed to promote admins: %v", err)
+	}
+
+	// Set up website permissions
+	sites := access.NewPolicy(store)
+
+	// Set up media storage
+	mediaConfig := cfg.Media
+	mediaFiles, err := blob.NewStorage(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to set up file storage: %v", err)
 	}
-	defer db.Close()
 
 	// Initialize router
 	router := mux.NewRouter()
 	
 	// Public routes (no authentication required)
 	router.HandleFunc("/api/health", handlers.HealthCheck).Methods("GET")
-	router.HandleFunc("/api/auth/register", handlers.RegisterHandler(db)).Methods("POST")
-	router.HandleFunc("/api/auth/login", handlers.LoginHandler(db)).Methods("POST")
+	router.HandleFunc("/api/auth/register", handlers.RegisterHandler(store, authConfig)).Methods("POST")
+	router.HandleFunc("/api/auth/login", handlers.LoginHandler(store, authConfig)).Methods("POST")
+	router.HandleFunc("/api/auth/refresh", handlers.RefreshHandler(store, authConfig)).Methods("POST")
+	router.HandleFunc("/.well-known/jwks.json", handlers.JWKSHandler(authConfig.Keys)).Methods("GET")
+	router.HandleFunc("/media/{id:[0-9]+}", handlers.ServeMediaHandler(store, mediaFiles)).Methods("GET", "HEAD")
+	router.HandleFunc("/media/{id:[0-9]+}/{variant}", handlers.ServeMediaHandler(store, mediaFiles)).Methods("GET", "HEAD")
 
-	// Protected routes (authentication required)
+	// Protected routes (authentication required). Personal access tokens only
+	// reach the routes given a scope with scopes.Require.
+	scopes := middleware.NewTokenScopes()
 	apiRouter := router.PathPrefix("/api").Subrouter()
-	apiRouter.Use(middleware.AuthMiddleware(db))
+	apiRouter.Use(middleware.AuthMiddleware(store, authConfig.Keys, scopes))
+	apiRouter.HandleFunc("/auth/logout", handlers.LogoutHandler(store)).Methods("POST")
+
+	// Personal access token routes
+	apiRouter.HandleFunc("/tokens", handlers.GetPersonalTokensHandler(store)).Methods("GET")
+	apiRouter.HandleFunc("/tokens", handlers.CreatePersonalTokenHandler(store)).Methods("POST")
+	apiRouter.HandleFunc("/tokens/{id}", handlers.RevokePersonalTokenHandler(store)).Methods("DELETE")
 
 	// User routes
-	apiRouter.HandleFunc("/users", handlers.GetUsersHandler(db)).Methods("GET")
-	apiRouter.HandleFunc("/users/{id}", handlers.DeleteUserHandler(db)).Methods("DELETE")
+	scopes.Require(models.ScopeUsersAdmin, apiRouter.HandleFunc("/users", handlers.GetUsersHandler(store)).Methods("GET"))
+	scopes.Require(models.ScopeUsersAdmin, apiRouter.HandleFunc("/users/{id}", handlers.DeleteUserHandler(store, mediaFiles)).Methods("DELETE"))
+
+	// Admin routes
+	adminRouter := apiRouter.PathPrefix("/admin").Subrouter()
+	adminRouter.Use(middleware.RequirePermission(models.PermAdmin))
+	scopes.Require(models.ScopeUsersAdmin, adminRouter.HandleFunc("/users/{id}/role", handlers.SetUserRoleHandler(store)).Methods("PUT"))
 
 	// Post routes
-	apiRouter.HandleFunc("/posts", handlers.GetPostsHandler(db)).Methods("GET")
-	apiRouter.HandleFunc("/posts", handlers.CreatePostHandler(db)).Methods("POST")
-	apiRouter.HandleFunc("/posts/{id}", handlers.GetPostHandler(db)).Methods("GET")
-	apiRouter.HandleFunc("/posts/{id}", handlers.UpdatePostHandler(db)).Methods("PUT")
-	apiRouter.HandleFunc("/posts/{id}", handlers.DeletePostHandler(db)).Methods("DELETE")
+	scopes.Require(models.ScopePostsRead, apiRouter.HandleFunc("/posts", handlers.GetPostsHandler(store)).Methods("GET"))
+	scopes.Require(models.ScopePostsWrite, apiRouter.Handle("/posts", middleware.RequirePermission(models.PermCreatePosts)(handlers.CreatePostHandler(store))).Methods("POST"))
+	scopes.Require(models.ScopePostsRead, apiRouter.HandleFunc("/posts/by-slug/{slug}", handlers.GetPostBySlugHandler(store)).Methods("GET"))
+	scopes.Require(models.ScopePostsRead, apiRouter.HandleFunc("/posts/{id}", handlers.GetPostHandler(store)).Methods("GET"))
+	scopes.Require(models.ScopePostsWrite, apiRouter.HandleFunc("/posts/{id}", handlers.UpdatePostHandler(store)).Methods("PUT"))
+	scopes.Require(models.ScopePostsWrite, apiRouter.HandleFunc("/posts/{id}", handlers.DeletePostHandler(store)).Methods("DELETE"))
+
+	// Comment routes
+	scopes.Require(models.ScopePostsRead, apiRouter.HandleFunc("/posts/{id}/comments", handlers.GetCommentsHandler(store, moderators)).Methods("GET"))
+	apiRouter.HandleFunc("/posts/{id}/comments", handlers.CreateCommentHandler(store, spamScorer, moderators)).Methods("POST")
+	apiRouter.HandleFunc("/comments/{id}", handlers.UpdateCommentHandler(store, spamScorer, moderators)).Methods("PUT")
+	apiRouter.HandleFunc("/comments/{id}", handlers.DeleteCommentHandler(store)).Methods("DELETE")
+
+	// Comment moderation routes
+	apiRouter.HandleFunc("/moderation/comments", handlers.GetModerationQueueHandler(store, moderators)).Methods("GET")
+	apiRouter.HandleFunc("/comments/{id}/approve", handlers.ModerateCommentHandler(store, moderators, models.CommentApproved)).Methods("POST")
+	apiRouter.HandleFunc("/comments/{id}/reject", handlers.ModerateCommentHandler(store, moderators, models.CommentRejected)).Methods("POST")
+	apiRouter.HandleFunc("/comments/{id}/spam", handlers.ModerateCommentHandler(store, moderators, models.CommentSpam)).Methods("POST")
+
+	// Media routes
+	apiRouter.HandleFunc("/media", handlers.GetMediaListHandler(store, mediaConfig)).Methods("GET")
+	apiRouter.HandleFunc("/media", handlers.UploadMediaHandler(store, mediaFiles, mediaConfig)).Methods("POST")
+	apiRouter.HandleFunc("/media/uploads", handlers.CreateMediaUploadHandler(mediaFiles)).Methods("POST")
+	apiRouter.HandleFunc("/media/{id}", handlers.GetMediaHandler(store, mediaConfig)).Methods("GET")
+	apiRouter.HandleFunc("/media/{id}", handlers.DeleteMediaHandler(store, mediaFiles)).Methods("DELETE")
+
+	// Search routes
+	scopes.Require(models.ScopePostsRead, apiRouter.HandleFunc("/search", handlers.SearchHandler(store)).Methods("GET"))
+
+	// Tag routes
+	scopes.Require(models.ScopePostsRead, apiRouter.HandleFunc("/tags", handlers.GetTagsHandler(store)).Methods("GET"))
+
+	// Category routes
+	manageCategories := middleware.RequirePermission(models.PermManageCategories)
+	scopes.Require(models.ScopePostsRead, apiRouter.HandleFunc("/categories", handlers.GetCategoriesHandler(store)).Methods("GET"))
+	apiRouter.Handle("/categories", manageCategories(handlers.CreateCategoryHandler(store))).Methods("POST")
+	scopes.Require(models.ScopePostsRead, apiRouter.HandleFunc("/categories/{id}", handlers.GetCategoryHandler(store)).Methods("GET"))
+	apiRouter.Handle("/categories/{id}", manageCategories(handlers.UpdateCategoryHandler(store))).Methods("PUT")
+	apiRouter.Handle("/categories/{id}", manageCategories(handlers.DeleteCategoryHandler(store))).Methods("DELETE")
+	apiRouter.Handle("/categories/{id}/move", manageCategories(handlers.MoveCategoryHandler(store))).Methods("POST")
+
+	// Website routes
+	apiRouter.HandleFunc("/sites", handlers.GetWebsitesHandler(store)).Methods("GET")
+	apiRouter.HandleFunc("/sites", handlers.CreateWebsiteHandler(store)).Methods("POST")
+	apiRouter.HandleFunc("/sites/{site}", handlers.GetWebsiteHandler(store, sites)).Methods("GET")
+	apiRouter.HandleFunc("/sites/{site}", handlers.UpdateWebsiteHandler(store, sites)).Methods("PUT")
+	apiRouter.HandleFunc("/sites/{site}", handlers.DeleteWebsiteHandler(store, sites)).Methods("DELETE")
+	apiRouter.HandleFunc("/sites/{site}/pages", handlers.GetPagesHandler(store, sites)).Methods("GET")
+	apiRouter.HandleFunc("/sites/{site}/pages", handlers.CreatePageHandler(store, sites)).Methods("POST")
+	apiRouter.HandleFunc("/sites/{site}/pages/{page}", handlers.GetPageHandler(store, sites)).Methods("GET")
+	apiRouter.HandleFunc("/sites/{site}/pages/{page}", handlers.UpdatePageHandler(store, sites)).Methods("PUT")
+	apiRouter.HandleFunc("/sites/{site}/pages/{page}", handlers.DeletePageHandler(store, sites)).Methods("DELETE")
+	apiRouter.HandleFunc("/sites/{site}/pages/{page}/items", handlers.GetItemsHandler(store, sites)).Methods("GET")
+	apiRouter.HandleFunc("/sites/{site}/pages/{page}/items", handlers.CreateItemHandler(store, sites)).Methods("POST")
+	apiRouter.HandleFunc("/sites/{site}/pages/{page}/items/{item}", handlers.GetItemHandler(store, sites)).Methods("GET")
+	apiRouter.HandleFunc("/sites/{site}/pages/{page}/items/{item}", handlers.UpdateItemHandler(store, sites)).Methods("PUT")
+	apiRouter.HandleFunc("/sites/{site}/pages/{page}/items/{item}", handlers.DeleteItemHandler(store, sites)).Methods("DELETE")
+	apiRouter.HandleFunc("/sites/{site}/pages/{page}/items/{item}/move", handlers.MoveItemHandler(store, sites)).Methods("POST")
+	apiRouter.HandleFunc("/sites/{site}/members", handlers.GetMembersHandler(store, sites)).Methods("GET")
+	apiRouter.HandleFunc("/sites/{site}/members/{user}", handlers.UpdateMemberHandler(store, sites)).Methods("PUT")
+	apiRouter.HandleFunc("/sites/{site}/members/{user}", handlers.RemoveMemberHandler(store, sites)).Methods("DELETE")
+	apiRouter.HandleFunc("/sites/{site}/invites", handlers.GetWebsiteInvitesHandler(store, sites)).Methods("GET")
+	apiRouter.HandleFunc("/sites/{site}/invites", handlers.InviteMemberHandler(store, sites)).Methods("POST")
+	apiRouter.HandleFunc("/sites/{site}/invites/{invite}", handlers.DeleteWebsiteInviteHandler(store, sites)).Methods("DELETE")
+	apiRouter.HandleFunc("/invites", handlers.GetMyInvitesHandler(store)).Methods("GET")
+	apiRouter.HandleFunc("/invites/{invite}/accept", handlers.AcceptInviteHandler(store)).Methods("POST")
+	apiRouter.HandleFunc("/invites/{invite}", handlers.DeclineInviteHandler(store)).Methods("DELETE")
+
+	// Post lifecycle routes
+	scopes.Require(models.ScopePostsWrite, apiRouter.HandleFunc("/posts/{id}/publish", handlers.SetPostStatusHandler(store, models.StatusPublished)).Methods("POST"))
+	scopes.Require(models.ScopePostsWrite, apiRouter.HandleFunc("/posts/{id}/unpublish", handlers.SetPostStatusHandler(store, models.StatusDraft)).Methods("POST"))
+	scopes.Require(models.ScopePostsWrite, apiRouter.HandleFunc("/posts/{id}/schedule", handlers.SetPostStatusHandler(store, models.StatusScheduled)).Methods("POST"))
+	scopes.Require(models.ScopePostsWrite, apiRouter.HandleFunc("/posts/{id}/archive", handlers.SetPostStatusHandler(store, models.StatusArchived)).Methods("POST"))
+
+	// Post revision routes
+	scopes.Require(models.ScopePostsRead, apiRouter.HandleFunc("/posts/{id}/revisions", handlers.GetPostRevisionsHandler(store)).Methods("GET"))
+	scopes.Require(models.ScopePostsRead, apiRouter.HandleFunc("/posts/{id}/revisions/{rev}", handlers.GetPostRevisionHandler(store)).Methods("GET"))
+	scopes.Require(models.ScopePostsRead, apiRouter.HandleFunc("/posts/{id}/revisions/{rev}/diff", handlers.DiffPostRevisionHandler(store)).Methods("GET"))
+	scopes.Require(models.ScopePostsWrite, apiRouter.HandleFunc("/posts/{id}/revisions/{rev}/restore", handlers.RestorePostRevisionHandler(store)).Methods("POST"))
 
 // Configure CORS
 c := cors.New(cors.Options{
-	AllowedOrigins:   []string{"*"},  // Allow all origins for development
+	AllowedOrigins:   cfg.CORSOrigins,
 	AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
 	AllowedHeaders:   []string{"Authorization", "Content-Type", "Accept", "Origin"},
 	ExposedHeaders:   []string{"Content-Length"},
@@ -66,10 +219,7 @@ c := cors.New(cors.Options{
 	handler := c.Handler(router)
 
 	// Configure and start server
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
-	}
+	port := cfg.Port
 	srv := &http.Server{
 		Handler:      handler,
 		Addr:         fmt.Sprintf("0.0.0.0:%s", port),
@@ -78,6 +228,17 @@ c := cors.New(cors.Options{
 		IdleTimeout:  60 * time.Second,
 	}
 
+	// Start the scheduler that publishes scheduled posts
+	var publishHook scheduler.PublishHook
+	if cfg.SchedulerRebuildCmd != "" {
+		publishHook, err = scheduler.CommandHook(cfg.SchedulerRebuildCmd)
+		if err != nil {
+			log.Fatalf("Invalid SCHEDULER_REBUILD_CMD: %v", err)
+		}
+	}
+	sched := scheduler.New(store, cfg.SchedulerInterval, publishHook)
+	sched.Start()
+
 	// Start the server in a goroutine
 	go func() {
 		log.Printf("Server listening on port %s", port)
@@ -96,10 +257,42 @@ c := cors.New(cors.Options{
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Stop the scheduler, letting a run in progress finish
+	if err := sched.Stop(ctx); err != nil {
+		log.Printf("Scheduler did not stop cleanly: %v", err)
+	}
+
 	// Attempt graceful shutdown
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
 	log.Println("Server exited gracefully")
-}
\ No newline at end of file
+}
+
+// promoteAdmins gives the admin role to the existing users with the given usernames
+func promoteAdmins(store models.UserStore, usernames []string) error {
+	names := make(map[string]bool)
+	for _, username := range usernames {
+		if username = strings.TrimSpace(username); username != "" {
+			names[username] = true
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+
+	users, err := store.GetUsers()
+	if err != nil {
+		return err
+	}
+	for _, user := range users {
+		if names[user.Username] && user.Role != models.RoleAdmin {
+			if _, err := store.SetUserRole(user.ID, models.RoleAdmin); err != nil {
+				return err
+			}
+			log.Printf("Promoted %s to admin", user.Username)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+// backend/cmd/migrate/main.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"blog-app/internal/config"
+	"blog-app/internal/database"
+	"blog-app/internal/models"
+)
+
+const usage = `Usage: migrate [-config file] <command> [argument]
+
+Commands:
+  up            Apply all pending migrations
+  down [n]      Roll back the last n applied migrations (default 1)
+  status        List migrations and whether they have been applied
+  to <version>  Migrate up or down to the given version (0 rolls back everything)
+  search-text   Fill in the search text and word counts of posts written before
+                they existed; up does this after applying migrations
+
+The database is selected with the same DB_* settings as the API, read from
+the environment or the TOML or YAML file given with -config or CONFIG_FILE.
+`
+
+func main() {
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "TOML or YAML config file")
+	flag.Usage = func() { fmt.Fprint(flag.CommandLine.Output(), usage) }
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Read the database settings
+	src, err := config.NewSource(*configFile, nil)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	cfg, err := database.ConfigFromEnv(src.Get)
+	if err != nil {
+		log.Fatalf("Failed to load database config: %v", err)
+	}
+
+	// Connect to the database
+	db, err := database.NewConnection(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Failed to load migrations: %v", err)
+	}
+
+	switch flag.Arg(0) {
+	case "up":
+		applied, err := migrator.Up()
+		if err != nil {
+			log.Fatalf("Migration failed after %d applied: %v", applied, err)
+		}
+		log.Printf("Applied %d migrations", applied)
+		if applied > 0 {
+			fillSearchText(db)
+		}
+
+	case "down":
+		steps := 1
+		if flag.NArg() > 1 {
+			steps, err = strconv.Atoi(flag.Arg(1))
+			if err != nil || steps < 1 {
+				log.Fatalf("Invalid number of steps %q", flag.Arg(1))
+			}
+		}
+		rolledBack, err := migrator.Down(steps)
+		if err != nil {
+			log.Fatalf("Rollback failed after %d rolled back: %v", rolledBack, err)
+		}
+		log.Printf("Rolled back %d migrations", rolledBack)
+
+	case "to":
+		if flag.NArg() < 2 {
+			log.Fatal("Missing target version")
+		}
+		version, err := strconv.Atoi(flag.Arg(1))
+		if err != nil || version < 0 {
+			log.Fatalf("Invalid version %q", flag.Arg(1))
+		}
+		changed, err := migrator.To(version)
+		if err != nil {
+			log.Fatalf("Migration failed after %d changes: %v", changed, err)
+		}
+		log.Printf("Migrated to version %d (%d migrations run)", version, changed)
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Fatalf("Failed to get migration status: %v", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		w.Flush()
+
+	case "search-text":
+		fillSearchText(db)
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// fillSearchText fills in the search text and word counts of posts that have none
+func fillSearchText(db *database.DB) {
+	filled, err := models.NewSQLStore(db).FillSearchText()
+	if err != nil {
+		log.Fatalf("Failed to fill in search text after %d posts: %v", filled, err)
+	}
+	log.Printf("Filled in the search text and word counts of %d posts", filled)
+}
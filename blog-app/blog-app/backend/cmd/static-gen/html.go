@@ -0,0 +1,198 @@
+// backend/cmd/static-gen/html.go
+package main
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+	"time"
+
+	"blog-app/internal/media"
+	"blog-app/internal/models"
+)
+
+// StaticImage is a post's featured image, in the rendition used for link previews
+type StaticImage struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// staticImage returns the given rendition of a media item, or the original
+// if the image is small enough to have no such rendition
+func staticImage(item *models.Media, rendition string, cfg media.Config) *StaticImage {
+	v := item.Variant(rendition)
+	if v == nil {
+		v = item.Variant(media.Original)
+	}
+	if v == nil {
+		return nil
+	}
+	return &StaticImage{URL: cfg.URL(item.ID, v.Name), Width: v.Width, Height: v.Height}
+}
+
+// postHead is the metadata written into the <head> of a post page, with the
+// post's SEO fields filled in from its title, excerpt and address when empty
+type postHead struct {
+	Title         string
+	Description   string
+	CanonicalURL  string
+	OGTitle       string
+	OGDescription string
+	Image         *StaticImage // With an absolute URL
+	SiteName      string
+	Published     time.Time
+	Modified      time.Time
+	JSONLD        blogPosting
+}
+
+// blogPosting is the schema.org BlogPosting description of a post, written as JSON-LD
+type blogPosting struct {
+	Context          string      `json:"@context"`
+	Type             string      `json:"@type"`
+	Headline         string      `json:"headline"`
+	Description      string      `json:"description,omitempty"`
+	Image            string      `json:"image,omitempty"`
+	Author           schemaName  `json:"author"`
+	Publisher        *schemaName `json:"publisher,omitempty"`
+	DatePublished    string      `json:"datePublished"`
+	DateModified     string      `json:"dateModified"`
+	MainEntityOfPage schemaPage  `json:"mainEntityOfPage"`
+}
+
+// schemaName is a schema.org thing known by its name, such as a Person
+type schemaName struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+}
+
+// schemaPage is a schema.org WebPage known by its address
+type schemaPage struct {
+	Type string `json:"@type"`
+	ID   string `json:"@id"`
+}
+
+// newPostHead resolves the head metadata of a post. Links are made absolute
+// with baseURL, the public address of the site, when it is set.
+func newPostHead(post StaticPost, excerpt, baseURL, siteName string) postHead {
+	head := postHead{
+		Title:         post.SEO.MetaTitle,
+		Description:   post.SEO.MetaDescription,
+		CanonicalURL:  post.SEO.CanonicalURL,
+		OGTitle:       post.SEO.OGTitle,
+		OGDescription: post.SEO.OGDescription,
+		SiteName:      siteName,
+		Published:     post.CreatedAt,
+		Modified:      post.UpdatedAt,
+	}
+	if head.Title == "" {
+		head.Title = post.Title
+	}
+	if head.Description == "" {
+		head.Description = excerpt
+	}
+	if head.CanonicalURL == "" {
+		head.CanonicalURL = baseURL + "/posts/" + post.Slug + "/"
+	}
+	if head.OGTitle == "" {
+		head.OGTitle = head.Title
+	}
+	if head.OGDescription == "" {
+		head.OGDescription = head.Description
+	}
+	if post.PublishedAt != nil {
+		head.Published = *post.PublishedAt
+	}
+	if post.FeaturedImage != nil {
+		image := *post.FeaturedImage
+		if !strings.HasPrefix(image.URL, "http://") && !strings.HasPrefix(image.URL, "https://") {
+			image.URL = baseURL + image.URL
+		}
+		head.Image = &image
+	}
+
+	head.JSONLD = blogPosting{
+		Context:          "https://schema.org",
+		Type:             "BlogPosting",
+		Headline:         post.Title,
+		Description:      head.Description,
+		Author:           schemaName{Type: "Person", Name: post.Author},
+		DatePublished:    head.Published.UTC().Format(time.RFC3339),
+		DateModified:     head.Modified.UTC().Format(time.RFC3339),
+		MainEntityOfPage: schemaPage{Type: "WebPage", ID: head.CanonicalURL},
+	}
+	if head.Image != nil {
+		head.JSONLD.Image = head.Image.URL
+	}
+	if siteName != "" {
+		head.JSONLD.Publisher = &schemaName{Type: "Organization", Name: siteName}
+	}
+
+	return head
+}
+
+// postPage renders a post as a standalone HTML page. The JSON-LD value is
+// encoded by html/template, which escapes anything that could end the script.
+var postPage = template.Must(template.New("post").Funcs(template.FuncMap{
+	"rfc3339": func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Head.Title}}</title>
+{{- with .Head.Description}}
+<meta name="description" content="{{.}}">
+{{- end}}
+<link rel="canonical" href="{{.Head.CanonicalURL}}">
+<meta property="og:type" content="article">
+<meta property="og:title" content="{{.Head.OGTitle}}">
+{{- with .Head.OGDescription}}
+<meta property="og:description" content="{{.}}">
+{{- end}}
+<meta property="og:url" content="{{.Head.CanonicalURL}}">
+{{- with .Head.SiteName}}
+<meta property="og:site_name" content="{{.}}">
+{{- end}}
+{{- with .Head.Image}}
+<meta property="og:image" content="{{.URL}}">
+<meta property="og:image:width" content="{{.Width}}">
+<meta property="og:image:height" content="{{.Height}}">
+{{- end}}
+<meta property="article:published_time" content="{{rfc3339 .Head.Published}}">
+<meta property="article:modified_time" content="{{rfc3339 .Head.Modified}}">
+{{- range .Post.Tags}}
+<meta property="article:tag" content="{{.}}">
+{{- end}}
+<meta name="twitter:card" content="{{if .Head.Image}}summary_large_image{{else}}summary{{end}}">
+<meta name="twitter:title" content="{{.Head.OGTitle}}">
+{{- with .Head.OGDescription}}
+<meta name="twitter:description" content="{{.}}">
+{{- end}}
+{{- with .Head.Image}}
+<meta name="twitter:image" content="{{.URL}}">
+{{- end}}
+<script type="application/ld+json">{{.Head.JSONLD}}</script>
+</head>
+<body>
+<article>
+<h1>{{.Post.Title}}</h1>
+<p>By {{.Post.Author}}, <time datetime="{{rfc3339 .Head.Published}}">{{.Head.Published.Format "January 2, 2006"}}</time></p>
+{{- with .Head.Image}}
+<img src="{{.URL}}" width="{{.Width}}" height="{{.Height}}" alt="">
+{{- end}}
+{{.Post.Content}}
+</article>
+</body>
+</html>
+`))
+
+// renderPost renders the HTML page of a post
+func renderPost(post StaticPost, head postHead) ([]byte, error) {
+	var buf bytes.Buffer
+	err := postPage.Execute(&buf, struct {
+		Post StaticPost
+		Head postHead
+	}{post, head})
+	return buf.Bytes(), err
+}
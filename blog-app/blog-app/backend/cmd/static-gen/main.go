@@ -2,87 +2,168 @@
 package main
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"flag"
-	"fmt"
 	"html/template"
 	"log"
 	"os"
-	"path/filepath"
+	"path"
+	"strings"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
-
+	"blog-app/internal/blob"
+	"blog-app/internal/config"
 	"blog-app/internal/database"
+	"blog-app/internal/media"
 	"blog-app/internal/models"
 )
 
 type StaticPost struct {
-	ID        int       `json:"id"`
-	Title     string    `json:"title"`
-	Content   template.HTML `json:"content"`
-	Author    string    `json:"author"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            int            `json:"id"`
+	Title         string         `json:"title"`
+	Slug          string         `json:"slug"`
+	Content       template.HTML  `json:"content"`
+	Author        string         `json:"author"`
+	CategoryID    *int           `json:"category_id"`
+	Tags          []string       `json:"tags"`
+	FeaturedImage *StaticImage   `json:"featured_image"`
+	SEO           models.PostSEO `json:"seo"`
+	PublishedAt   *time.Time     `json:"published_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
 }
 
+// StaticPageData lists every post in the same summary shape as the API listing;
+// the content is only in the individual post files
 type StaticPageData struct {
-	Posts    []StaticPost `json:"posts"`
-	PostsMap map[int]StaticPost `json:"postsMap"`
+	Posts    []*models.PostSummary `json:"posts"`
+	PostsMap map[int]*models.PostSummary `json:"postsMap"`
+}
+
+type StaticTagPage struct {
+	Tag   string                `json:"tag"`
+	Posts []*models.PostSummary `json:"posts"`
 }
 
 func main() {
 	// Define command line flags
 	outputDir := flag.String("output", "static", "Output directory for static site")
+	toStorage := flag.Bool("storage", false, "Write to the blob storage selected by STORAGE_DRIVER, using -output as the key prefix")
+	baseURL := flag.String("base-url", "", "Public URL of the site, such as https://blog.example.com, for canonical and Open Graph links")
+	siteName := flag.String("site-name", "", "Site name for Open Graph and JSON-LD")
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "TOML or YAML config file with the same settings as the API")
 	flag.Parse()
+	*baseURL = strings.TrimRight(*baseURL, "/")
 
-	log.Printf("Generating static site in %s...", *outputDir)
+	// Read the settings shared with the API
+	src, err := config.NewSource(*configFile, nil)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	dbCfg, err := database.ConfigFromEnv(src.Get)
+	if err != nil {
+		log.Fatalf("Failed to load database config: %v", err)
+	}
+	storageCfg, err := blob.ConfigFromEnv(src.Get)
+	if err != nil {
+		log.Fatalf("Failed to load storage config: %v", err)
+	}
 
-	// Connect to the database
-	db, err := database.NewConnection()
+	// Featured images are linked the same way the API links media
+	mediaCfg, err := media.ConfigFromEnv(src.Get)
 	if err != nil {
-		log.Fatalf("Failed to connect to database: %v", err)
+		log.Fatalf("Failed to load media config: %v", err)
 	}
-	defer db.Close()
 
-	// Get all posts
-	posts, err := getAllPosts(db)
+	// Open the output, a directory unless blob storage was requested
+	var files blob.Storage
+	var prefix string
+	if *toStorage {
+		files, err = blob.NewStorage(storageCfg)
+		prefix = strings.Trim(path.Clean("/"+*outputDir), "/")
+		log.Printf("Generating static site in blob storage under %q...", prefix)
+	} else {
+		files, err = blob.NewLocalStorage(*outputDir)
+		log.Printf("Generating static site in %s...", *outputDir)
+	}
 	if err != nil {
-		log.Fatalf("Failed to get posts: %v", err)
+		log.Fatalf("Failed to open output: %v", err)
+	}
+	writeJSON := func(name string, data []byte) error {
+		return files.Put(path.Join(prefix, "data", name), "application/json", data)
 	}
 
-	// Create the output directory if it doesn't exist
-	err = os.MkdirAll(*outputDir, 0755)
+	// Connect to the database
+	db, err := database.NewConnection(dbCfg)
 	if err != nil {
-		log.Fatalf("Failed to create output directory: %v", err)
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	// Apply pending migrations if enabled
+	if _, err := database.AutoMigrate(db, dbCfg); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
-	// Create data directory
-	dataDir := filepath.Join(*outputDir, "data")
-	err = os.MkdirAll(dataDir, 0755)
+	var store models.Store = models.NewSQLStore(db)
+
+	// Get all published posts, as seen by an anonymous reader
+	posts, err := store.GetPosts(models.Viewer{})
 	if err != nil {
-		log.Fatalf("Failed to create data directory: %v", err)
+		log.Fatalf("Failed to get posts: %v", err)
 	}
 
-	// Convert to static posts
+	// Convert to static posts and their summaries
 	var staticPosts []StaticPost
-	postsMap := make(map[int]StaticPost)
+	var summaries []*models.PostSummary
+	postsMap := make(map[int]*models.PostSummary)
+	redirects := make(map[string]string) // old slug -> current slug
 	
+	heads := make(map[int]postHead)
+
 	for _, post := range posts {
 		staticPost := StaticPost{
-			ID:        post.ID,
-			Title:     post.Title,
-			Content:   template.HTML(post.Content),
-			Author:    post.Author,
-			CreatedAt: post.CreatedAt,
+			ID:          post.ID,
+			Title:       post.Title,
+			Slug:        post.Slug,
+			Content:     template.HTML(post.Content),
+			Author:      post.Author,
+			CategoryID:  post.CategoryID,
+			Tags:        post.Tags,
+			SEO:         post.SEO,
+			PublishedAt: post.PublishedAt,
+			CreatedAt:   post.CreatedAt,
+			UpdatedAt:   post.UpdatedAt,
+		}
+		if post.FeaturedImageID != nil {
+			item, err := store.GetMedia(*post.FeaturedImageID)
+			if err != nil && !errors.Is(err, models.ErrMediaNotFound) {
+				log.Fatalf("Failed to get featured image: %v", err)
+			}
+			if item != nil {
+				staticPost.FeaturedImage = staticImage(item, "large", mediaCfg)
+			}
 		}
 		staticPosts = append(staticPosts, staticPost)
-		postsMap[post.ID] = staticPost
+		summary := models.Summarize(post)
+		summaries = append(summaries, summary)
+		postsMap[post.ID] = summary
+		heads[post.ID] = newPostHead(staticPost, summary.Excerpt, *baseURL, *siteName)
+
+		// Old slugs point to the current one
+		oldSlugs, err := store.GetPostSlugHistory(post.ID)
+		if err != nil {
+			log.Fatalf("Failed to get slug history: %v", err)
+		}
+		for _, old := range oldSlugs {
+			redirects[old] = post.Slug
+		}
 	}
 
 	// Create the page data
 	pageData := StaticPageData{
-		Posts:    staticPosts,
+		Posts:    summaries,
 		PostsMap: postsMap,
 	}
 
@@ -92,7 +173,7 @@ func main() {
 		log.Fatalf("Failed to marshal posts JSON: %v", err)
 	}
 
-	err = os.WriteFile(filepath.Join(dataDir, "posts.json"), postsJSON, 0644)
+	err = writeJSON("posts.json", postsJSON)
 	if err != nil {
 		log.Fatalf("Failed to write posts JSON: %v", err)
 	}
@@ -104,40 +185,93 @@ func main() {
 			log.Fatalf("Failed to marshal post JSON: %v", err)
 		}
 
-		err = os.WriteFile(filepath.Join(dataDir, fmt.Sprintf("post-%d.json", post.ID)), postJSON, 0644)
+		err = writeJSON("posts/"+post.Slug+".json", postJSON)
 		if err != nil {
 			log.Fatalf("Failed to write post JSON: %v", err)
 		}
+
+		// Write the post page, with its metadata in the <head> for crawlers
+		page, err := renderPost(post, heads[post.ID])
+		if err != nil {
+			log.Fatalf("Failed to render post page: %v", err)
+		}
+
+		err = files.Put(path.Join(prefix, "posts", post.Slug, "index.html"), "text/html; charset=utf-8", page)
+		if err != nil {
+			log.Fatalf("Failed to write post page: %v", err)
+		}
 	}
 
-	log.Printf("Static site generation complete! Generated %d posts.", len(staticPosts))
-}
+	// Write the slug redirects, so links to old slugs can be forwarded
+	redirectsJSON, err := json.MarshalIndent(redirects, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to marshal redirects JSON: %v", err)
+	}
+
+	err = writeJSON("redirects.json", redirectsJSON)
+	if err != nil {
+		log.Fatalf("Failed to write redirects JSON: %v", err)
+	}
 
-func getAllPosts(db *sql.DB) ([]*models.Post, error) {
-	rows, err := db.Query(`
-		SELECT p.id, p.title, p.content, p.author_id, u.username, p.created_at, p.updated_at 
-		FROM posts p 
-		JOIN users u ON p.author_id = u.id 
-		ORDER BY p.created_at DESC`)
+	// Write the tag list with post counts
+	tags, err := store.GetTags(models.Viewer{})
 	if err != nil {
-		return nil, err
+		log.Fatalf("Failed to get tags: %v", err)
 	}
-	defer rows.Close()
 
-	var posts []*models.Post
-	for rows.Next() {
-		var post models.Post
-		if err := rows.Scan(
-			&post.ID, &post.Title, &post.Content, &post.AuthorID, &post.Author, &post.CreatedAt, &post.UpdatedAt,
-		); err != nil {
-			return nil, err
+	tagsJSON, err := json.MarshalIndent(tags, "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to marshal tags JSON: %v", err)
+	}
+
+	err = writeJSON("tags.json", tagsJSON)
+	if err != nil {
+		log.Fatalf("Failed to write tags JSON: %v", err)
+	}
+
+	// Write a post listing for every tag, newest first like posts.json
+	for _, tag := range tags {
+		tagPage := StaticTagPage{Tag: tag.Name, Posts: []*models.PostSummary{}}
+		for _, post := range summaries {
+			for _, name := range post.Tags {
+				if name == tag.Name {
+					tagPage.Posts = append(tagPage.Posts, post)
+				}
+			}
+		}
+
+		tagJSON, err := json.MarshalIndent(tagPage, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to marshal tag JSON: %v", err)
+		}
+
+		err = writeJSON("tags/"+tag.Name+".json", tagJSON)
+		if err != nil {
+			log.Fatalf("Failed to write tag JSON: %v", err)
 		}
-		posts = append(posts, &post)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+	// Write the category tree
+	categories, err := store.GetCategories()
+	if err != nil {
+		log.Fatalf("Failed to get categories: %v", err)
+	}
+
+	categoriesJSON, err := json.MarshalIndent(models.BuildCategoryTree(categories), "", "  ")
+	if err != nil {
+		log.Fatalf("Failed to marshal categories JSON: %v", err)
 	}
 
-	return posts, nil
-}
\ No newline at end of file
+	err = writeJSON("categories.json", categoriesJSON)
+	if err != nil {
+		log.Fatalf("Failed to write categories JSON: %v", err)
+	}
+
+	// Write the content of every managed website
+	websites, err := writeWebsites(store, files, prefix, mediaCfg)
+	if err != nil {
+		log.Fatalf("Failed to write websites: %v", err)
+	}
+
+	log.Printf("Static site generation complete! Generated %d posts and %d websites.", len(staticPosts), websites)
+}
@@ -0,0 +1,123 @@
+// backend/cmd/static-gen/sites.go
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"path"
+	"time"
+
+	"blog-app/internal/blob"
+	"blog-app/internal/media"
+	"blog-app/internal/models"
+)
+
+// StaticWebsite is a managed website with the list of its pages
+type StaticWebsite struct {
+	ID        int               `json:"id"`
+	Name      string            `json:"name"`
+	Domain    string            `json:"domain"`
+	Pages     []StaticPageEntry `json:"pages"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// StaticPageEntry is a page as listed in its website's site.json
+type StaticPageEntry struct {
+	ID    int    `json:"id"`
+	Title string `json:"title"`
+	Slug  string `json:"slug"`
+}
+
+// StaticWebsitePage is a page of a managed website with its items in order
+type StaticWebsitePage struct {
+	ID        int          `json:"id"`
+	Title     string       `json:"title"`
+	Slug      string       `json:"slug"`
+	Items     []StaticItem `json:"items"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// StaticItem is an item on a page of a managed website
+type StaticItem struct {
+	ID       int          `json:"id"`
+	Title    string       `json:"title"`
+	Subtitle string       `json:"subtitle"`
+	Image    *StaticImage `json:"image"` // Medium rendition, nil when the item has no image
+}
+
+// writeWebsites writes data/sites.json listing every managed website, and for
+// each one sites/{domain}/data/site.json and a sites/{domain}/data/pages/{slug}.json
+// file per page. It returns the number of websites written.
+func writeWebsites(store models.Store, files blob.Storage, prefix string, mediaCfg media.Config) (int, error) {
+	websites, err := store.GetWebsites()
+	if err != nil {
+		return 0, err
+	}
+
+	put := func(name string, v interface{}) error {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		return files.Put(path.Join(prefix, name), "application/json", data)
+	}
+
+	if err := put("data/sites.json", websites); err != nil {
+		return 0, err
+	}
+
+	for _, website := range websites {
+		pages, err := store.GetPages(website.ID)
+		if err != nil {
+			return 0, err
+		}
+
+		site := StaticWebsite{
+			ID:        website.ID,
+			Name:      website.Name,
+			Domain:    website.Domain,
+			Pages:     []StaticPageEntry{},
+			UpdatedAt: website.UpdatedAt,
+		}
+		dir := path.Join("sites", website.Domain, "data")
+
+		for _, page := range pages {
+			site.Pages = append(site.Pages, StaticPageEntry{ID: page.ID, Title: page.Title, Slug: page.Slug})
+
+			items, err := store.GetItems(page.ID)
+			if err != nil {
+				return 0, err
+			}
+			staticPage := StaticWebsitePage{
+				ID:        page.ID,
+				Title:     page.Title,
+				Slug:      page.Slug,
+				Items:     []StaticItem{},
+				UpdatedAt: page.UpdatedAt,
+			}
+			for _, item := range items {
+				staticItem := StaticItem{ID: item.ID, Title: item.Title, Subtitle: item.Subtitle}
+				if item.ImageID != nil {
+					image, err := store.GetMedia(*item.ImageID)
+					if err != nil && !errors.Is(err, models.ErrMediaNotFound) {
+						return 0, err
+					}
+					if image != nil {
+						staticItem.Image = staticImage(image, "medium", mediaCfg)
+					}
+				}
+				staticPage.Items = append(staticPage.Items, staticItem)
+			}
+
+			if err := put(path.Join(dir, "pages", page.Slug+".json"), staticPage); err != nil {
+				return 0, err
+			}
+		}
+
+		if err := put(path.Join(dir, "site.json"), site); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(websites), nil
+}
@@ -0,0 +1,69 @@
+// backend/internal/access/policy.go
+package access
+
+import (
+	"errors"
+	"fmt"
+
+	"blog-app/internal/models"
+)
+
+// ErrForbidden is returned when a member's role lacks the permission asked for
+var ErrForbidden = errors.New("forbidden")
+
+// Permission is something a member may do on a website
+type Permission string
+
+// Website permissions
+const (
+	View   Permission = "view"   // Read the website, its pages, items and members
+	Edit   Permission = "edit"   // Create, update and reorder pages and items
+	Delete Permission = "delete" // Delete pages and items
+	Manage Permission = "manage" // Change or delete the website and manage its members
+)
+
+// roles lists the permissions each website role grants
+var roles = map[models.WebsiteRole][]Permission{
+	models.WebsiteOwner:  {View, Edit, Delete, Manage},
+	models.WebsiteEditor: {View, Edit, Delete},
+	models.WebsiteViewer: {View},
+}
+
+// Allows reports whether the role grants the permission
+func Allows(role models.WebsiteRole, perm Permission) bool {
+	for _, granted := range roles[role] {
+		if granted == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Policy decides what users may do on the websites they are members of.
+// Handlers call Authorize before touching anything scoped to a website.
+type Policy struct {
+	members models.WebsiteMemberStore
+}
+
+// NewPolicy creates a policy reading memberships from the given store
+func NewPolicy(members models.WebsiteMemberStore) *Policy {
+	return &Policy{members: members}
+}
+
+// Authorize returns the user's membership of the website if their role grants
+// the permission. Users who are not members get models.ErrWebsiteNotFound, so
+// that websites they cannot see are not revealed; members whose role falls
+// short get an error wrapping ErrForbidden.
+func (p *Policy) Authorize(userID, websiteID int, perm Permission) (*models.WebsiteMember, error) {
+	member, err := p.members.GetWebsiteMember(websiteID, userID)
+	if err != nil {
+		if errors.Is(err, models.ErrMemberNotFound) {
+			return nil, models.ErrWebsiteNotFound
+		}
+		return nil, err
+	}
+	if !Allows(member.Role, perm) {
+		return nil, fmt.Errorf("%w: %s members cannot %s this website", ErrForbidden, member.Role, perm)
+	}
+	return member, nil
+}
@@ -3,46 +3,48 @@ package auth
 
 import (
 	"errors"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
 
-// Key for the user ID in the request context
+// Keys for the user ID, role, token claims and personal access token in the request context
 type contextKey string
 const UserIDKey contextKey = "userID"
+const RoleKey contextKey = "role"
+const ClaimsKey contextKey = "claims"
+const PersonalTokenKey contextKey = "personalToken"
 
 // Claims represents the JWT claims
 type Claims struct {
-	UserID int `json:"user_id"`
+	UserID int    `json:"user_id"`
+	Role   string `json:"role"` // The user's role when the token was issued
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a JWT token for a user
-func GenerateToken(userID int) (string, error) {
-	// Get the JWT secret from environment variables
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "your-secret-key" // Default secret for development
-	}
-
+// GenerateToken generates a JWT access token for a user with the given role,
+// valid for ttl. Each token gets a unique ID (jti) so that it can be revoked.
+// It is signed with the ring's signing key, named in the kid header.
+func (r *KeyRing) GenerateToken(userID int, role string, ttl time.Duration) (string, error) {
 	// Create the claims
-	expirationTime := time.Now().Add(24 * time.Hour) // Token expires in 24 hours
+	tokenID, err := NewID()
+	if err != nil {
+		return "", err
+	}
+	expirationTime := time.Now().Add(ttl)
 	claims := &Claims{
 		UserID: userID,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 		},
 	}
 
-	// Create the token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
 	// Sign the token
-	tokenString, err := token.SignedString([]byte(jwtSecret))
+	tokenString, err := r.sign(claims)
 	if err != nil {
 		return "", err
 	}
@@ -50,22 +52,14 @@ func GenerateToken(userID int) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateToken validates a JWT token
-func ValidateToken(tokenString string) (*Claims, error) {
-	// Get the JWT secret from environment variables
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "your-secret-key" // Default secret for development
-	}
-
-	// Parse the token
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(jwtSecret), nil
-	})
+// ValidateToken validates a JWT token against the key its kid header names
+func (r *KeyRing) ValidateToken(tokenString string) (*Claims, error) {
+	// Parse the token, accepting only the asymmetric algorithms the ring signs with
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{
+		jwt.SigningMethodRS256.Alg(),
+		jwt.SigningMethodEdDSA.Alg(),
+	}))
+	token, err := parser.ParseWithClaims(tokenString, &Claims{}, r.verificationKey)
 	if err != nil {
 		return nil, err
 	}
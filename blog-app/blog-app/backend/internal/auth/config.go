@@ -0,0 +1,89 @@
+// backend/internal/auth/config.go
+package auth
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Config holds the token lifetimes and the keys access tokens are signed with
+type Config struct {
+	AccessTTL  time.Duration // Lifetime of an access token
+	RefreshTTL time.Duration // Lifetime of a refresh token, renewed on every refresh
+	Keys       *KeyRing      // Keys signing and verifying access tokens
+}
+
+// DefaultConfig returns the settings used when nothing is configured
+func DefaultConfig() Config {
+	return Config{
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: 30 * 24 * time.Hour,
+	}
+}
+
+// ConfigFromEnv reads the token settings through getenv, falling back to DefaultConfig:
+//   - ACCESS_TOKEN_TTL: duration such as "15m"
+//   - REFRESH_TOKEN_TTL: duration such as "720h"
+//   - JWT_SIGNING_KEY_PATH: PEM file with the RSA or Ed25519 private key new
+//     tokens are signed with; a random key is generated when unset
+//   - JWT_VERIFY_KEY_PATHS: comma-separated PEM files with further public or
+//     private keys tokens are accepted from, such as the previous signing key
+func ConfigFromEnv(getenv func(string) string) (Config, error) {
+	cfg := DefaultConfig()
+
+	if v := getenv("ACCESS_TOKEN_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("invalid ACCESS_TOKEN_TTL %q", v)
+		}
+		cfg.AccessTTL = d
+	}
+	if v := getenv("REFRESH_TOKEN_TTL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("invalid REFRESH_TOKEN_TTL %q", v)
+		}
+		cfg.RefreshTTL = d
+	}
+
+	keys, err := keyRingFromEnv(getenv)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.Keys = keys
+
+	return cfg, nil
+}
+
+// keyRingFromEnv loads the signing and verification keys named by getenv
+func keyRingFromEnv(getenv func(string) string) (*KeyRing, error) {
+	var verify []*Key
+	for _, path := range strings.Split(getenv("JWT_VERIFY_KEY_PATHS"), ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		key, err := LoadKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT_VERIFY_KEY_PATHS: %w", err)
+		}
+		verify = append(verify, key)
+	}
+
+	path := getenv("JWT_SIGNING_KEY_PATH")
+	if path == "" {
+		if len(verify) > 0 {
+			return nil, fmt.Errorf("JWT_VERIFY_KEY_PATHS needs JWT_SIGNING_KEY_PATH to be set")
+		}
+		return GenerateKeyRing()
+	}
+	signing, err := LoadKey(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_SIGNING_KEY_PATH: %w", err)
+	}
+	ring, err := NewKeyRing(signing, verify...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT_SIGNING_KEY_PATH: %w", err)
+	}
+	return ring, nil
+}
@@ -0,0 +1,230 @@
+// backend/internal/auth/keys.go
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// minRSABits is the smallest RSA key accepted for signing or verification
+const minRSABits = 2048
+
+// Key is a key that verifies tokens and, when it has a private half, signs them
+type Key struct {
+	ID      string // Key ID (kid), the RFC 7638 thumbprint of the public key
+	Method  jwt.SigningMethod
+	Public  crypto.PublicKey
+	Private crypto.PrivateKey // nil for keys that only verify
+}
+
+// KeyRing holds the key new tokens are signed with and every key tokens are
+// verified against. Rotating keys means signing with a new key while the old
+// one stays on the ring until the tokens it signed have expired.
+type KeyRing struct {
+	signing   *Key
+	keys      map[string]*Key // keyed by key ID
+	ephemeral bool            // The signing key was generated rather than loaded
+}
+
+// NewKeyRing creates a key ring signing with the given key, which must have a
+// private half, and also verifying with the others
+func NewKeyRing(signing *Key, verify ...*Key) (*KeyRing, error) {
+	if signing == nil || signing.Private == nil {
+		return nil, errors.New("the signing key needs a private key")
+	}
+
+	ring := &KeyRing{signing: signing, keys: map[string]*Key{signing.ID: signing}}
+	for _, key := range verify {
+		ring.keys[key.ID] = key
+	}
+	return ring, nil
+}
+
+// GenerateKeyRing creates a key ring with a new random Ed25519 key. Tokens it
+// signs stop verifying once the process exits, so it is only fit for development.
+func GenerateKeyRing() (*KeyRing, error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	key, err := newKey(public, private)
+	if err != nil {
+		return nil, err
+	}
+	ring, err := NewKeyRing(key)
+	if err != nil {
+		return nil, err
+	}
+	ring.ephemeral = true
+	return ring, nil
+}
+
+// LoadKey reads a PEM file holding an RSA or Ed25519 key. Private keys in
+// PKCS #1 or PKCS #8 form can sign; public keys in PKIX form only verify.
+func LoadKey(path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data found", path)
+	}
+
+	var key *Key
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, perr := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if perr != nil {
+			return nil, fmt.Errorf("%s: %w", path, perr)
+		}
+		key, err = newKey(&private.PublicKey, private)
+	case "PRIVATE KEY":
+		private, perr := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if perr != nil {
+			return nil, fmt.Errorf("%s: %w", path, perr)
+		}
+		signer, ok := private.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("%s: unsupported private key", path)
+		}
+		key, err = newKey(signer.Public(), private)
+	case "PUBLIC KEY":
+		public, perr := x509.ParsePKIXPublicKey(block.Bytes)
+		if perr != nil {
+			return nil, fmt.Errorf("%s: %w", path, perr)
+		}
+		key, err = newKey(public, nil)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// newKey picks the signing method for a key pair and works out its key ID
+func newKey(public crypto.PublicKey, private crypto.PrivateKey) (*Key, error) {
+	key := &Key{Public: public, Private: private}
+	switch pub := public.(type) {
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSABits)
+		}
+		key.Method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	default:
+		return nil, errors.New("only RSA and Ed25519 keys are supported")
+	}
+
+	// RFC 7638: hash the required members of the JWK in lexicographic order
+	jwk := key.JWK()
+	var members interface{}
+	if jwk.Kty == "RSA" {
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	} else {
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+	data, err := json.Marshal(members)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	key.ID = base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return key, nil
+}
+
+// JWK is a public key in JSON Web Key form
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"` // Ed25519 only
+	X   string `json:"x,omitempty"`   // Ed25519 only
+	N   string `json:"n,omitempty"`   // RSA only
+	E   string `json:"e,omitempty"`   // RSA only
+}
+
+// JWKSet is the body of a JWKS document
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWK returns the public half of the key as a JSON Web Key
+func (k *Key) JWK() JWK {
+	jwk := JWK{Kid: k.ID, Use: "sig", Alg: k.Method.Alg()}
+	switch pub := k.Public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+	return jwk
+}
+
+// JWKS returns the public keys of every key on the ring, ordered by key ID
+func (r *KeyRing) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range r.keys {
+		set.Keys = append(set.Keys, key.JWK())
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// Ephemeral reports whether the ring signs with a generated key that is lost
+// when the process exits
+func (r *KeyRing) Ephemeral() bool {
+	return r.ephemeral
+}
+
+// sign signs the claims with the signing key, naming it in the kid header
+func (r *KeyRing) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(r.signing.Method, claims)
+	token.Header["kid"] = r.signing.ID
+	return token.SignedString(r.signing.Private)
+}
+
+// verificationKey returns the public key named by a token's kid header,
+// refusing tokens signed with another algorithm than the key's
+func (r *KeyRing) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := r.keys[kid]
+	if !ok {
+		return nil, errors.New("unknown signing key")
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, errors.New("unexpected signing method")
+	}
+	return key.Public, nil
+}
@@ -0,0 +1,238 @@
+// backend/internal/auth/keys_test.go
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// rfc7638Key is the RSA public key of the example in RFC 7638, section 3.1
+func rfc7638Key(t *testing.T) *rsa.PublicKey {
+	t.Helper()
+
+	n, err := base64.RawURLEncoding.DecodeString("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	if err != nil {
+		t.Fatalf("invalid modulus: %v", err)
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: 65537}
+}
+
+// rfc8037Key is the Ed25519 public key of the example in RFC 8037, appendix A
+func rfc8037Key(t *testing.T) ed25519.PublicKey {
+	t.Helper()
+
+	x, err := base64.RawURLEncoding.DecodeString("11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo")
+	if err != nil {
+		t.Fatalf("invalid public key: %v", err)
+	}
+	return ed25519.PublicKey(x)
+}
+
+func TestKeyID(t *testing.T) {
+	tests := []struct {
+		name    string
+		public  func(t *testing.T) interface{}
+		wantKid string
+		wantAlg string
+	}{
+		{
+			name:    "RSA",
+			public:  func(t *testing.T) interface{} { return rfc7638Key(t) },
+			wantKid: "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs",
+			wantAlg: "RS256",
+		},
+		{
+			name:    "Ed25519",
+			public:  func(t *testing.T) interface{} { return rfc8037Key(t) },
+			wantKid: "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k",
+			wantAlg: "EdDSA",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := newKey(tt.public(t), nil)
+			if err != nil {
+				t.Fatalf("newKey() error = %v", err)
+			}
+			if key.ID != tt.wantKid {
+				t.Errorf("key ID = %q, want the RFC thumbprint %q", key.ID, tt.wantKid)
+			}
+			if jwk := key.JWK(); jwk.Kid != tt.wantKid || jwk.Alg != tt.wantAlg || jwk.Use != "sig" {
+				t.Errorf("JWK() = %+v, want kid %q, alg %q and use sig", jwk, tt.wantKid, tt.wantAlg)
+			}
+		})
+	}
+}
+
+func TestNewKeyRejectsSmallRSAKeys(t *testing.T) {
+	private, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	if _, err := newKey(&private.PublicKey, private); err == nil {
+		t.Error("newKey() accepted a 1024-bit RSA key")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	ring, err := GenerateKeyRing()
+	if err != nil {
+		t.Fatalf("GenerateKeyRing() error = %v", err)
+	}
+	old, err := newKey(rfc7638Key(t), nil)
+	if err != nil {
+		t.Fatalf("newKey() error = %v", err)
+	}
+	ring, err = NewKeyRing(ring.signing, old)
+	if err != nil {
+		t.Fatalf("NewKeyRing() error = %v", err)
+	}
+
+	set := ring.JWKS()
+	if len(set.Keys) != 2 {
+		t.Fatalf("JWKS() has %d keys, want 2", len(set.Keys))
+	}
+	if set.Keys[0].Kid > set.Keys[1].Kid {
+		t.Errorf("JWKS() keys are not ordered by kid: %q, %q", set.Keys[0].Kid, set.Keys[1].Kid)
+	}
+	for _, jwk := range set.Keys {
+		switch jwk.Kty {
+		case "OKP":
+			if jwk.Crv != "Ed25519" || jwk.X == "" || jwk.N != "" || jwk.Kid != ring.signing.ID {
+				t.Errorf("Ed25519 JWK = %+v", jwk)
+			}
+		case "RSA":
+			if jwk.N == "" || jwk.E != "AQAB" || jwk.X != "" || jwk.Kid != old.ID {
+				t.Errorf("RSA JWK = %+v", jwk)
+			}
+		default:
+			t.Errorf("unexpected key type %q", jwk.Kty)
+		}
+	}
+
+	// Only public halves are published
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("failed to encode JWKS: %v", err)
+	}
+	if strings.Contains(string(data), `"d"`) {
+		t.Errorf("JWKS contains private key material: %s", data)
+	}
+}
+
+func TestTokenKeyRotation(t *testing.T) {
+	oldRing, err := GenerateKeyRing()
+	if err != nil {
+		t.Fatalf("GenerateKeyRing() error = %v", err)
+	}
+	newRing, err := GenerateKeyRing()
+	if err != nil {
+		t.Fatalf("GenerateKeyRing() error = %v", err)
+	}
+	rotated, err := NewKeyRing(newRing.signing, oldRing.signing)
+	if err != nil {
+		t.Fatalf("NewKeyRing() error = %v", err)
+	}
+
+	token, err := oldRing.GenerateToken(7, "author", time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+	if err != nil {
+		t.Fatalf("failed to parse token: %v", err)
+	}
+	if kid := parsed.Header["kid"]; kid != oldRing.signing.ID {
+		t.Errorf("token kid = %v, want %q", kid, oldRing.signing.ID)
+	}
+
+	tests := []struct {
+		name  string
+		ring  *KeyRing
+		valid bool
+	}{
+		{"signing ring", oldRing, true},
+		{"ring that kept the old key", rotated, true},
+		{"ring without the key", newRing, false},
+	}
+	for _, tt := range tests {
+		claims, err := tt.ring.ValidateToken(token)
+		if (err == nil) != tt.valid {
+			t.Errorf("%s: ValidateToken() error = %v, want valid = %v", tt.name, err, tt.valid)
+			continue
+		}
+		if tt.valid && (claims.UserID != 7 || claims.Role != "author") {
+			t.Errorf("%s: ValidateToken() = user %d role %q, want user 7 role author", tt.name, claims.UserID, claims.Role)
+		}
+	}
+}
+
+func TestLoadKey(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		t.Fatalf("failed to encode private key: %v", err)
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		t.Fatalf("failed to encode public key: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		block     *pem.Block
+		canSign   bool
+		wantError bool
+	}{
+		{name: "PKCS #8 private key", block: &pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}, canSign: true},
+		{name: "PKIX public key", block: &pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}},
+		{name: "certificate", block: &pem.Block{Type: "CERTIFICATE", Bytes: publicDER}, wantError: true},
+	}
+
+	var ids []string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "key.pem")
+			if err := os.WriteFile(path, pem.EncodeToMemory(tt.block), 0600); err != nil {
+				t.Fatalf("failed to write key: %v", err)
+			}
+
+			key, err := LoadKey(path)
+			if tt.wantError {
+				if err == nil {
+					t.Errorf("LoadKey() accepted a %s", tt.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadKey() error = %v", err)
+			}
+			if (key.Private != nil) != tt.canSign {
+				t.Errorf("LoadKey() can sign = %v, want %v", key.Private != nil, tt.canSign)
+			}
+			ids = append(ids, key.ID)
+		})
+	}
+
+	// Both halves of a key pair share the key ID
+	if len(ids) == 2 && ids[0] != ids[1] {
+		t.Errorf("private and public key IDs differ: %q, %q", ids[0], ids[1])
+	}
+}
@@ -0,0 +1,27 @@
+// backend/internal/auth/personal.go
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+)
+
+// PersonalTokenPrefix starts every personal access token, telling them apart from JWTs
+const PersonalTokenPrefix = "bpat_"
+
+// NewPersonalToken returns a random personal access token, the start of it
+// that is shown to tell tokens apart, and the hash to store for it
+func NewPersonalToken() (token, prefix, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	token = PersonalTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, token[:len(PersonalTokenPrefix)+6], HashToken(token), nil
+}
+
+// IsPersonalToken reports whether a bearer token is a personal access token
+func IsPersonalToken(token string) bool {
+	return strings.HasPrefix(token, PersonalTokenPrefix)
+}
@@ -0,0 +1,105 @@
+// backend/internal/blob/blob.go
+package blob
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Errors returned by every storage implementation
+var (
+	ErrNotExist           = errors.New("blob does not exist")
+	ErrInvalidKey         = errors.New("invalid blob key")
+	ErrPresignUnsupported = errors.New("storage does not support presigned URLs")
+)
+
+// Storage keeps files as blobs addressed by key. Keys are slash-separated
+// relative paths such as "media/4f2a/thumb.jpg".
+type Storage interface {
+	// Put writes a blob, replacing any blob with the same key
+	Put(key, contentType string, data []byte) error
+	// Open opens a blob for reading, returning ErrNotExist if it is missing
+	Open(key string) (io.ReadCloser, error)
+	// Delete removes a blob. Deleting a missing blob is not an error.
+	Delete(key string) error
+	// PresignGet returns a URL anyone can download the blob from until it expires
+	PresignGet(key string, expires time.Duration) (string, error)
+	// PresignPut returns a URL anyone can upload the blob to with a PUT request
+	// carrying the given Content-Type, until it expires
+	PresignPut(key, contentType string, expires time.Duration) (string, error)
+}
+
+// Driver identifies a storage implementation
+type Driver string
+
+// Supported drivers, named after the STORAGE_DRIVER values that select them
+const (
+	Local  Driver = "local"
+	S3     Driver = "s3"
+	Memory Driver = "memory"
+)
+
+// Config selects and configures the storage
+type Config struct {
+	Driver Driver
+	Dir    string   // Directory of the local driver
+	S3     S3Config // Bucket of the s3 driver
+}
+
+// ConfigFromEnv reads the storage settings through getenv:
+//   - STORAGE_DRIVER: local (default), s3 or memory
+//   - STORAGE_DIR: directory of the local driver (default "uploads")
+//   - S3_*: bucket of the s3 driver, see S3ConfigFromEnv
+func ConfigFromEnv(getenv func(string) string) (Config, error) {
+	cfg := Config{Driver: Driver(getenv("STORAGE_DRIVER")), Dir: getenv("STORAGE_DIR")}
+	if cfg.Driver == "" {
+		cfg.Driver = Local
+	}
+	if cfg.Dir == "" {
+		cfg.Dir = "uploads"
+	}
+
+	switch cfg.Driver {
+	case Local, Memory:
+	case S3:
+		s3, err := S3ConfigFromEnv(getenv)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.S3 = s3
+	default:
+		return cfg, fmt.Errorf("unsupported storage driver %q", cfg.Driver)
+	}
+
+	return cfg, nil
+}
+
+// NewStorage opens the storage selected by the configuration's driver
+func NewStorage(cfg Config) (Storage, error) {
+	switch cfg.Driver {
+	case Local:
+		return NewLocalStorage(cfg.Dir)
+	case S3:
+		return newCheckedS3Storage(cfg.S3)
+	case Memory:
+		return NewMemoryStorage(), nil
+	default:
+		return nil, fmt.Errorf("unsupported storage driver %q", cfg.Driver)
+	}
+}
+
+// checkKey rejects keys that are empty, absolute or contain empty, "." or ".." segments
+func checkKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// backend/internal/blob/local.go
+package blob
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LocalStorage stores blobs as files in a directory on the local filesystem.
+// It cannot presign URLs, so its blobs have to be served by the application.
+type LocalStorage struct {
+	dir string
+}
+
+// NewLocalStorage creates a storage rooted at dir, creating the directory if needed
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{dir: dir}, nil
+}
+
+// Put writes a file, replacing any file with the same key. The data is written
+// to a temporary file first so readers never see a partial file.
+func (s *LocalStorage) Put(key, contentType string, data []byte) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Open opens a file for reading
+func (s *LocalStorage) Open(key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotExist
+	}
+	return f, err
+}
+
+// Delete removes a file, and its directory once it is empty
+func (s *LocalStorage) Delete(key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	// Fails while other files remain, which is fine
+	if dir := filepath.Dir(path); dir != filepath.Clean(s.dir) {
+		os.Remove(dir)
+	}
+	return nil
+}
+
+// PresignGet is not supported by the local filesystem
+func (s *LocalStorage) PresignGet(key string, expires time.Duration) (string, error) {
+	return "", ErrPresignUnsupported
+}
+
+// PresignPut is not supported by the local filesystem
+func (s *LocalStorage) PresignPut(key, contentType string, expires time.Duration) (string, error) {
+	return "", ErrPresignUnsupported
+}
+
+// path maps a key to a path inside the storage directory
+func (s *LocalStorage) path(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,73 @@
+// backend/internal/blob/memory.go
+package blob
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+// MemoryStorage keeps blobs in memory.
+// It is intended for local development and tests where no storage is available.
+type MemoryStorage struct {
+	mu    sync.RWMutex
+	blobs map[string][]byte
+}
+
+// NewMemoryStorage creates an empty in-memory storage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{blobs: make(map[string][]byte)}
+}
+
+// Put stores a copy of the data
+func (s *MemoryStorage) Put(key, contentType string, data []byte) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blobs[key] = append([]byte(nil), data...)
+	return nil
+}
+
+// Open returns a reader over a stored blob
+func (s *MemoryStorage) Open(key string) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, ErrNotExist
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Delete removes a blob
+func (s *MemoryStorage) Delete(key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.blobs, key)
+	return nil
+}
+
+// PresignGet is not supported in memory
+func (s *MemoryStorage) PresignGet(key string, expires time.Duration) (string, error) {
+	return "", ErrPresignUnsupported
+}
+
+// PresignPut is not supported in memory
+func (s *MemoryStorage) PresignPut(key, contentType string, expires time.Duration) (string, error) {
+	return "", ErrPresignUnsupported
+}
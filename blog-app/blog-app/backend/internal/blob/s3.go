@@ -0,0 +1,243 @@
+// backend/internal/blob/s3.go
+package blob
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPresignExpiry is the longest lifetime S3 accepts for a presigned URL
+const maxPresignExpiry = 7 * 24 * time.Hour
+
+// S3Config holds the settings of an S3-compatible bucket
+type S3Config struct {
+	Endpoint        string // Such as "http://localhost:9000" for MinIO; empty for Amazon S3
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // Only needed with temporary credentials
+	PathStyle       bool   // Address the bucket in the path instead of the host name, as MinIO expects
+}
+
+// S3Storage stores blobs in a bucket of an S3-compatible object store, such as
+// Amazon S3 or MinIO. Requests are signed with AWS Signature Version 4.
+type S3Storage struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3Storage creates a storage for the configured bucket without contacting it
+func NewS3Storage(cfg S3Config) (*S3Storage, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("missing S3 bucket")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("missing S3 credentials")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+	}
+
+	return &S3Storage{
+		cfg:      cfg,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: time.Minute},
+		now:      time.Now,
+	}, nil
+}
+
+// S3ConfigFromEnv reads the bucket settings through getenv:
+//   - S3_ENDPOINT, S3_REGION, S3_BUCKET: the bucket
+//   - S3_ACCESS_KEY_ID, S3_SECRET_ACCESS_KEY, S3_SESSION_TOKEN: credentials,
+//     falling back to AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+//   - S3_PATH_STYLE: path-style addressing (default true with S3_ENDPOINT)
+func S3ConfigFromEnv(getenv func(string) string) (S3Config, error) {
+	cfg := S3Config{
+		Endpoint:        getenv("S3_ENDPOINT"),
+		Region:          getenv("S3_REGION"),
+		Bucket:          getenv("S3_BUCKET"),
+		AccessKeyID:     getenv("S3_ACCESS_KEY_ID"),
+		SecretAccessKey: getenv("S3_SECRET_ACCESS_KEY"),
+		SessionToken:    getenv("S3_SESSION_TOKEN"),
+	}
+
+	// Fall back to the standard AWS credentials
+	if cfg.AccessKeyID == "" {
+		cfg.AccessKeyID = getenv("AWS_ACCESS_KEY_ID")
+		cfg.SecretAccessKey = getenv("AWS_SECRET_ACCESS_KEY")
+		cfg.SessionToken = getenv("AWS_SESSION_TOKEN")
+	}
+
+	// Custom endpoints such as MinIO usually expect path-style addressing
+	cfg.PathStyle = cfg.Endpoint != ""
+	if v := getenv("S3_PATH_STYLE"); v != "" {
+		pathStyle, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid S3_PATH_STYLE %q", v)
+		}
+		cfg.PathStyle = pathStyle
+	}
+
+	return cfg, nil
+}
+
+// newCheckedS3Storage creates a storage for the bucket and checks that it can be reached
+func newCheckedS3Storage(cfg S3Config) (*S3Storage, error) {
+	s, err := NewS3Storage(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// Verify the bucket exists and the credentials are accepted
+	if err := s.checkBucket(); err != nil {
+		return nil, fmt.Errorf("failed to reach S3 bucket: %w", err)
+	}
+
+	return s, nil
+}
+
+// Put uploads an object
+func (s *S3Storage) Put(key, contentType string, data []byte) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	resp, err := s.do(http.MethodPut, s.objectURL(key), contentType, data)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Open downloads an object, streaming its body
+func (s *S3Storage) Open(key string) (io.ReadCloser, error) {
+	if err := checkKey(key); err != nil {
+		return nil, err
+	}
+	resp, err := s.do(http.MethodGet, s.objectURL(key), "", nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete removes an object
+func (s *S3Storage) Delete(key string) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	resp, err := s.do(http.MethodDelete, s.objectURL(key), "", nil)
+	if err == ErrNotExist {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// PresignGet returns a URL the object can be downloaded from until it expires, at most 7 days
+func (s *S3Storage) PresignGet(key string, expires time.Duration) (string, error) {
+	return s.presign(http.MethodGet, key, "", expires)
+}
+
+// PresignPut returns a URL the object can be uploaded to until it expires, at most 7 days.
+// The upload must send the same Content-Type.
+func (s *S3Storage) PresignPut(key, contentType string, expires time.Duration) (string, error) {
+	return s.presign(http.MethodPut, key, contentType, expires)
+}
+
+// checkBucket sends a HEAD request for the bucket
+func (s *S3Storage) checkBucket() error {
+	resp, err := s.do(http.MethodHead, s.bucketURL(), "", nil)
+	if err == ErrNotExist {
+		return fmt.Errorf("bucket %q does not exist", s.cfg.Bucket)
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// bucketURL returns the address of the bucket
+func (s *S3Storage) bucketURL() *url.URL {
+	u := *s.endpoint
+	if s.cfg.PathStyle {
+		u.Path = "/" + s.cfg.Bucket
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+		u.Path = "/"
+	}
+	u.RawPath = uriEncode(u.Path, false)
+	return &u
+}
+
+// objectURL returns the address of an object
+func (s *S3Storage) objectURL(key string) *url.URL {
+	u := s.bucketURL()
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	u.RawPath = uriEncode(u.Path, false)
+	return u
+}
+
+// do sends a signed request, turning error responses into errors.
+// The caller must close the body of the returned response.
+func (s *S3Storage) do(method string, u *url.URL, contentType string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = int64(len(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	sum := sha256.Sum256(body)
+	s.sign(req, hex.EncodeToString(sum[:]))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	// S3 describes errors in a small XML document, except for HEAD requests
+	var s3Err struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	xml.Unmarshal(data, &s3Err)
+
+	if resp.StatusCode == http.StatusNotFound && (s3Err.Code == "" || s3Err.Code == "NoSuchKey") {
+		return nil, ErrNotExist
+	}
+	if s3Err.Code == "" {
+		s3Err.Code = http.StatusText(resp.StatusCode)
+	}
+	return nil, fmt.Errorf("s3 %s %s: %d %s: %s", method, u.Path, resp.StatusCode, s3Err.Code, s3Err.Message)
+}
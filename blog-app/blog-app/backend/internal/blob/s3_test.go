@@ -0,0 +1,228 @@
+// backend/internal/blob/s3_test.go
+package blob
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeS3 is an S3 server with a single bucket that keeps objects in memory.
+// It checks the signature of every request the same way S3 does, by signing
+// the request again with the secret key.
+type fakeS3 struct {
+	bucket string
+	signer *S3Storage // Holds the credentials the server accepts
+
+	mu      sync.Mutex
+	objects map[string]fakeObject // keyed by object path, such as "/media/a.jpg"
+}
+
+// fakeObject is an object stored by fakeS3
+type fakeObject struct {
+	contentType string
+	data        []byte
+}
+
+// newFakeS3 starts a fake S3 server and returns a storage using it
+func newFakeS3(t *testing.T) (*fakeS3, *S3Storage) {
+	t.Helper()
+
+	cfg := S3Config{
+		Region:          "eu-west-1",
+		Bucket:          "media",
+		AccessKeyID:     "test-key",
+		SecretAccessKey: "test-secret",
+		PathStyle:       true,
+	}
+	fake := &fakeS3{bucket: cfg.Bucket, objects: make(map[string]fakeObject)}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	cfg.Endpoint = server.URL
+	s, err := NewS3Storage(cfg)
+	if err != nil {
+		t.Fatalf("NewS3Storage() error = %v", err)
+	}
+	fake.signer, err = NewS3Storage(cfg)
+	if err != nil {
+		t.Fatalf("NewS3Storage() error = %v", err)
+	}
+
+	// Both sides sign with the same clock, as S3 allows a few minutes of skew
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	fake.signer.now = s.now
+	return fake, s
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Check the payload hash and the signature
+	sum := sha256.Sum256(body)
+	if got := r.Header.Get("X-Amz-Content-Sha256"); got != hex.EncodeToString(sum[:]) {
+		s3Error(w, http.StatusBadRequest, "XAmzContentSHA256Mismatch")
+		return
+	}
+	check, err := http.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for name, values := range r.Header {
+		if name != "Authorization" {
+			check.Header[name] = values
+		}
+	}
+	f.signer.sign(check, r.Header.Get("X-Amz-Content-Sha256"))
+	if r.Header.Get("Authorization") != check.Header.Get("Authorization") {
+		s3Error(w, http.StatusForbidden, "SignatureDoesNotMatch")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// Requests for the bucket itself only check it exists
+	if r.URL.Path == "/"+f.bucket {
+		if r.Method != http.MethodHead {
+			s3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+		}
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/"+f.bucket+"/") {
+		w.WriteHeader(http.StatusNotFound) // HEAD responses carry no error document
+		return
+	}
+
+	key := strings.TrimPrefix(r.URL.Path, "/"+f.bucket)
+	switch r.Method {
+	case http.MethodPut:
+		f.objects[key] = fakeObject{r.Header.Get("Content-Type"), body}
+	case http.MethodGet:
+		obj, ok := f.objects[key]
+		if !ok {
+			s3Error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Type", obj.contentType)
+		w.Write(obj.data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		s3Error(w, http.StatusMethodNotAllowed, "MethodNotAllowed")
+	}
+}
+
+// s3Error writes an S3 error document
+func s3Error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	io.WriteString(w, "<Error><Code>"+code+"</Code><Message>"+code+" message</Message></Error>")
+}
+
+func TestS3Storage(t *testing.T) {
+	fake, s := newFakeS3(t)
+
+	if err := s.checkBucket(); err != nil {
+		t.Fatalf("checkBucket() error = %v", err)
+	}
+
+	// Keys are escaped in the path and signed as escaped
+	const key = "media/2024/photo one+€.jpg"
+	if err := s.Put(key, "image/jpeg", []byte("jpeg data")); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if obj := fake.objects["/"+key]; obj.contentType != "image/jpeg" || string(obj.data) != "jpeg data" {
+		t.Errorf("stored object = %q of type %q, want the uploaded data", obj.data, obj.contentType)
+	}
+
+	body, err := s.Open(key)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || string(data) != "jpeg data" {
+		t.Errorf("Open() read %q, %v; want the uploaded data", data, err)
+	}
+
+	if err := s.Delete(key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Open(key); !errors.Is(err, ErrNotExist) {
+		t.Errorf("Open() after Delete() error = %v, want %v", err, ErrNotExist)
+	}
+
+	// Deleting a missing object is not an error
+	if err := s.Delete(key); err != nil {
+		t.Errorf("Delete() of a missing object error = %v", err)
+	}
+
+	// Invalid keys never reach the server
+	if err := s.Put("../escape", "text/plain", nil); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Put() with an invalid key error = %v, want %v", err, ErrInvalidKey)
+	}
+}
+
+func TestS3StorageErrors(t *testing.T) {
+	// A wrong secret is reported with the S3 error code
+	t.Run("bad signature", func(t *testing.T) {
+		_, s := newFakeS3(t)
+		s.cfg.SecretAccessKey = "wrong-secret"
+
+		err := s.Put("a.txt", "text/plain", []byte("a"))
+		if err == nil || !strings.Contains(err.Error(), "403 SignatureDoesNotMatch") {
+			t.Errorf("Put() error = %v, want a 403 SignatureDoesNotMatch error", err)
+		}
+	})
+
+	// A missing bucket is reported by the check made when the storage is created
+	t.Run("missing bucket", func(t *testing.T) {
+		fake, s := newFakeS3(t)
+		fake.bucket = "other"
+
+		err := s.checkBucket()
+		if err == nil || !strings.Contains(err.Error(), `bucket "media" does not exist`) {
+			t.Errorf("checkBucket() error = %v, want a missing bucket error", err)
+		}
+	})
+}
+
+func TestNewS3StorageValidation(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  S3Config
+	}{
+		{"missing bucket", S3Config{AccessKeyID: "key", SecretAccessKey: "secret"}},
+		{"missing credentials", S3Config{Bucket: "media"}},
+		{"bad endpoint", S3Config{Bucket: "media", AccessKeyID: "key", SecretAccessKey: "secret", Endpoint: "ftp://example.com"}},
+	}
+	for _, tt := range tests {
+		if _, err := NewS3Storage(tt.cfg); err == nil {
+			t.Errorf("%s: NewS3Storage() succeeded, want an error", tt.name)
+		}
+	}
+
+	// Amazon S3 is addressed by region, with the bucket in the host name
+	s, err := NewS3Storage(S3Config{Bucket: "media", AccessKeyID: "key", SecretAccessKey: "secret", Region: "eu-west-1"})
+	if err != nil {
+		t.Fatalf("NewS3Storage() error = %v", err)
+	}
+	if got, want := s.objectURL("a/b.jpg").String(), "https://media.s3.eu-west-1.amazonaws.com/a/b.jpg"; got != want {
+		t.Errorf("objectURL() = %q, want %q", got, want)
+	}
+}
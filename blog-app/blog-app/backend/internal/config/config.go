@@ -0,0 +1,187 @@
+// backend/internal/config/config.go
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-app/internal/auth"
+	"blog-app/internal/blob"
+	"blog-app/internal/database"
+	"blog-app/internal/media"
+	"blog-app/internal/moderation"
+)
+
+// Environments selected by APP_ENV
+const (
+	Development = "development"
+	Production  = "production"
+)
+
+// Config holds every setting of the API server
+type Config struct {
+	Env                 string
+	Port                string
+	CORSOrigins         []string // "*" allows every origin
+	AdminUsernames      []string
+	SchedulerInterval   time.Duration
+	SchedulerRebuildCmd string
+
+	Database database.Config
+	Storage  blob.Config
+	Auth     auth.Config
+	Media    media.Config
+	Spam     moderation.Config
+}
+
+// Load reads the configuration of the API server from the command line
+// arguments, the environment and the config file named by -config or
+// CONFIG_FILE, then validates it. Every setting has a flag named after it, so
+// DB_HOST is set with -db-host.
+func Load(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("api", flag.ExitOnError)
+	file := fs.String("config", os.Getenv("CONFIG_FILE"), "TOML or YAML config file")
+	for _, s := range settings {
+		fs.String(flagName(s.Name), "", s.Usage)
+	}
+	fs.Parse(args)
+
+	// Only flags given on the command line override other sources
+	flags := make(map[string]string)
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name != "config" {
+			flags[strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))] = f.Value.String()
+		}
+	})
+
+	src, err := NewSource(*file, flags)
+	if err != nil {
+		return nil, err
+	}
+	return FromSource(src)
+}
+
+// flagName returns the flag that sets a setting, such as -db-host for DB_HOST
+func flagName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+}
+
+// FromSource reads the configuration from a source and validates it
+func FromSource(src *Source) (*Config, error) {
+	cfg := &Config{
+		Env:                 src.Get("APP_ENV"),
+		Port:                src.Get("PORT"),
+		CORSOrigins:         splitList(src.Get("CORS_ALLOWED_ORIGINS")),
+		AdminUsernames:      splitList(src.Get("ADMIN_USERNAMES")),
+		SchedulerInterval:   30 * time.Second,
+		SchedulerRebuildCmd: src.Get("SCHEDULER_REBUILD_CMD"),
+	}
+	if cfg.Env == "" {
+		cfg.Env = Development
+	}
+	if cfg.Port == "" {
+		cfg.Port = "8080"
+	}
+	if len(cfg.CORSOrigins) == 0 && cfg.Env != Production {
+		cfg.CORSOrigins = []string{"*"}
+	}
+	if v := src.Get("SCHEDULER_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid SCHEDULER_INTERVAL %q", v)
+		}
+		cfg.SchedulerInterval = d
+	}
+
+	var err error
+	if cfg.Database, err = database.ConfigFromEnv(src.Get); err != nil {
+		return nil, err
+	}
+	if cfg.Storage, err = blob.ConfigFromEnv(src.Get); err != nil {
+		return nil, err
+	}
+	if cfg.Auth, err = auth.ConfigFromEnv(src.Get); err != nil {
+		return nil, err
+	}
+	if cfg.Media, err = media.ConfigFromEnv(src.Get); err != nil {
+		return nil, err
+	}
+	if cfg.Spam, err = moderation.ConfigFromEnv(src.Get); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks the settings that the packages do not check themselves and,
+// in production, refuses the defaults that are only fit for development.
+// Every problem found is reported at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if c.Env != Development && c.Env != Production {
+		errs = append(errs, fmt.Errorf("invalid APP_ENV %q, expected %s or %s", c.Env, Development, Production))
+	}
+	if port, err := strconv.Atoi(c.Port); err != nil || port < 1 || port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid PORT %q", c.Port))
+	}
+	switch database.Dialect(c.Database.Driver) {
+	case database.MySQL, database.Postgres, database.SQLite, "memory":
+	default:
+		errs = append(errs, fmt.Errorf("unsupported DB_DRIVER %q", c.Database.Driver))
+	}
+	for _, origin := range c.CORSOrigins {
+		if origin == "*" {
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+			errs = append(errs, fmt.Errorf("invalid CORS_ALLOWED_ORIGINS entry %q, expected an origin such as https://blog.example.com", origin))
+		}
+	}
+
+	if c.Env == Production {
+		if c.Auth.Keys.Ephemeral() {
+			errs = append(errs, errors.New("JWT_SIGNING_KEY_PATH must be set in production"))
+		}
+		if c.Database.Driver == "memory" {
+			errs = append(errs, errors.New("DB_DRIVER must not be memory in production, as everything is lost on restart"))
+		}
+		if c.Database.UsesServer() && c.Database.Password == database.DefaultPassword {
+			errs = append(errs, errors.New("DB_PASSWORD must be set to a password other than the default in production"))
+		}
+		if c.Database.UsesServer() && c.Database.User == "root" {
+			errs = append(errs, errors.New("DB_USER must be set to a user other than root in production"))
+		}
+		if len(c.CORSOrigins) == 0 {
+			errs = append(errs, errors.New("CORS_ALLOWED_ORIGINS must be set in production"))
+		}
+		for _, origin := range c.CORSOrigins {
+			if origin == "*" {
+				errs = append(errs, errors.New("CORS_ALLOWED_ORIGINS must list the allowed origins instead of * in production"))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// splitList splits a comma-separated list, dropping empty items
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,73 @@
+// backend/internal/config/config_test.go
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestProductionRefusesDevelopmentDatabases(t *testing.T) {
+	tests := []struct {
+		name   string
+		values map[string]string
+		want   []string // Parts of the expected error
+		unwant []string // Parts the error must not contain
+	}{
+		{
+			name:   "memory store",
+			values: map[string]string{"DB_DRIVER": "memory"},
+			want:   []string{"DB_DRIVER must not be memory"},
+			unwant: []string{"DB_USER", "DB_PASSWORD"},
+		},
+		{
+			name:   "default MySQL user and password",
+			values: map[string]string{"DB_DRIVER": "mysql"},
+			want:   []string{"DB_USER must be set to a user other than root", "DB_PASSWORD must be set"},
+		},
+		{
+			name:   "root set explicitly",
+			values: map[string]string{"DB_DRIVER": "postgres", "DB_USER": "root", "DB_PASSWORD": "secret"},
+			want:   []string{"DB_USER must be set to a user other than root"},
+			unwant: []string{"DB_PASSWORD"},
+		},
+		{
+			name:   "own user and password",
+			values: map[string]string{"DB_DRIVER": "mysql", "DB_USER": "blog", "DB_PASSWORD": "secret"},
+			unwant: []string{"DB_DRIVER", "DB_USER", "DB_PASSWORD"},
+		},
+		{
+			name:   "SQLite",
+			values: map[string]string{"DB_DRIVER": "sqlite"},
+			unwant: []string{"DB_DRIVER", "DB_USER", "DB_PASSWORD"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := &Source{values: map[string]string{
+				"APP_ENV":              Production,
+				"CORS_ALLOWED_ORIGINS": "https://blog.example.com",
+			}}
+			for name, v := range tt.values {
+				src.values[name] = v
+			}
+
+			// Without a signing key the configuration is always refused, so
+			// every case has an error to look into
+			_, err := FromSource(src)
+			if err == nil {
+				t.Fatal("FromSource() succeeded without a signing key")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("FromSource() error = %v, want it to contain %q", err, want)
+				}
+			}
+			for _, unwant := range tt.unwant {
+				if strings.Contains(err.Error(), unwant) {
+					t.Errorf("FromSource() error = %v, want it not to mention %s", err, unwant)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+// backend/internal/config/file.go
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// readFile reads a TOML (.toml) or YAML (.yaml, .yml) config file. The file
+// holds settings at the top level or in tables of one level, and a key in a
+// table is named after the environment variable it sets, so "password" in
+// the db table sets DB_PASSWORD. Values are strings, numbers, booleans or
+// arrays of them; arrays become comma-separated lists. Deeper tables, dates
+// and other values are rejected rather than misread.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	switch ext := filepath.Ext(path); ext {
+	case ".toml":
+		err = toml.Unmarshal(data, &doc)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	default:
+		return nil, fmt.Errorf("%s: unsupported config file type %q, expected .toml, .yaml or .yml", path, ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flatten(values, doc, "", path); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// flatten adds the settings of a table to values, naming each after its
+// table and key. Keys are visited in order, so errors do not depend on map
+// iteration.
+func flatten(values map[string]string, table map[string]interface{}, prefix, path string) error {
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !isBareKey(key) {
+			return fmt.Errorf("%s: invalid key %q", path, key)
+		}
+		name := prefix + strings.ToUpper(key)
+
+		// Tables hold settings named after them, one level deep
+		if sub, ok := table[key].(map[string]interface{}); ok {
+			if prefix != "" {
+				return fmt.Errorf("%s: %s: nested tables are not supported", path, name)
+			}
+			if err := flatten(values, sub, name+"_", path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !isSetting(name) && !isSetting(strings.TrimSuffix(name, "_FILE")) {
+			return fmt.Errorf("%s: unknown setting %s", path, name)
+		}
+		if _, ok := values[name]; ok {
+			return fmt.Errorf("%s: %s is set twice", path, name)
+		}
+		value, err := formatValue(table[key])
+		if err != nil {
+			return fmt.Errorf("%s: invalid value for %s: %w", path, name, err)
+		}
+		values[name] = value
+	}
+	return nil
+}
+
+// formatValue returns a value as the string its environment variable would
+// hold, joining arrays with commas
+func formatValue(v interface{}) (string, error) {
+	items, ok := v.([]interface{})
+	if !ok {
+		return formatScalar(v)
+	}
+
+	parts := make([]string, len(items))
+	for i, item := range items {
+		s, err := formatScalar(item)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = s
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// formatScalar returns a string, number or boolean as a string
+func formatScalar(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case nil:
+		return "", errors.New("missing value")
+	case []interface{}:
+		return "", errors.New("nested arrays are not supported")
+	case map[string]interface{}:
+		return "", errors.New("tables in arrays are not supported")
+	default:
+		return "", fmt.Errorf("values of type %T, such as dates, are not supported", v)
+	}
+}
+
+// isBareKey reports whether s is made of letters, digits and underscores
+func isBareKey(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
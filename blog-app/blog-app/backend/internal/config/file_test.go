@@ -0,0 +1,123 @@
+// backend/internal/config/file_test.go
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeConfig writes a config file with the given name to a temporary directory
+func writeConfig(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestReadFile(t *testing.T) {
+	// The same settings in every supported format
+	want := map[string]string{
+		"PORT":                 "8080",
+		"DB_DRIVER":            "sqlite",
+		"DB_PASSWORD_FILE":     "/run/secrets/db_password",
+		"DB_AUTO_MIGRATE":      "true",
+		"CORS_ALLOWED_ORIGINS": "https://a.example.com,https://b.example.com",
+		"SPAM_MAX_LINKS":       "3",
+	}
+	files := map[string]string{
+		"config.toml": `# Blog settings
+port = 8_080 # trailing comment
+spam_max_links = 3
+
+[db]
+driver = "sqlite"
+password_file = '/run/secrets/db_password'
+auto_migrate = true
+
+[cors]
+allowed_origins = [
+  "https://a.example.com",
+  'https://b.example.com',
+]
+`,
+		"config.yaml": `# Blog settings
+port: 8080
+spam_max_links: 3
+db:
+  driver: sqlite
+  password_file: /run/secrets/db_password
+  auto_migrate: true
+cors:
+  allowed_origins:
+    - https://a.example.com
+    - "https://b.example.com"
+`,
+		"config.yml": `{port: 8080, spam_max_links: 3, db: {driver: sqlite, password_file: /run/secrets/db_password, auto_migrate: true},
+  cors: {allowed_origins: [https://a.example.com, https://b.example.com]}}`,
+	}
+
+	for name, content := range files {
+		t.Run(name, func(t *testing.T) {
+			got, err := readFile(writeConfig(t, name, content))
+			if err != nil {
+				t.Fatalf("readFile() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("readFile() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestReadFileStrings(t *testing.T) {
+	got, err := readFile(writeConfig(t, "config.toml", `[db]
+password = "tab\there \"quoted\" \\ \u00e9\U0001F600"
+host = 'C:\hosts\db'
+`))
+	if err != nil {
+		t.Fatalf("readFile() error = %v", err)
+	}
+	want := map[string]string{
+		"DB_PASSWORD": "tab\there \"quoted\" \\ \u00e9\U0001F600",
+		"DB_HOST":     `C:\hosts\db`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readFile() = %v, want %v", got, want)
+	}
+}
+
+func TestReadFileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		wantErr string // Part of the expected error
+	}{
+		{"unknown setting", "config.toml", "colour = \"blue\"", "unknown setting COLOUR"},
+		{"unknown setting in YAML", "config.yaml", "db:\n  colour: blue", "unknown setting DB_COLOUR"},
+		{"setting twice", "config.toml", "db_host = \"a\"\n[db]\nhost = \"b\"", "DB_HOST is set twice"},
+		{"nested table", "config.toml", "[db.replica]\nhost = \"db\"", "DB_REPLICA: nested tables are not supported"},
+		{"nested array", "config.toml", "[cors]\nallowed_origins = [[\"a\"]]", "invalid value for CORS_ALLOWED_ORIGINS: nested arrays are not supported"},
+		{"date", "config.toml", "app_env = 2024-01-01", "invalid value for APP_ENV: values of type"},
+		{"missing value", "config.yaml", "port:", "invalid value for PORT: missing value"},
+		{"invalid key", "config.yaml", "db-host: db", `invalid key "db-host"`},
+		{"TOML syntax", "config.toml", "[db]\nhost = localhost", "config.toml: toml: line 2"},
+		{"YAML syntax", "config.yaml", "port: [8080", "config.yaml: yaml:"},
+		{"unsupported format", "config.json", `{"port": 8080}`, `unsupported config file type ".json", expected .toml, .yaml or .yml`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readFile(writeConfig(t, tt.file, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("readFile() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+// backend/internal/config/source.go
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Setting is a configuration value, named after the environment variable that sets it
+type Setting struct {
+	Name  string
+	Usage string
+}
+
+// settings lists every value the binaries read. Each can be set in the config
+// file, in the environment or, for the API, with a flag; NAME_FILE variants
+// read the value from a file, such as a Docker secret.
+var settings = []Setting{
+	{"APP_ENV", "development (default) or production, which refuses default secrets"},
+	{"PORT", "port the API listens on (default 8080)"},
+	{"CORS_ALLOWED_ORIGINS", "comma-separated origins allowed to call the API (default * outside production)"},
+	{"ADMIN_USERNAMES", "comma-separated users promoted to admin on start"},
+	{"SCHEDULER_INTERVAL", "how often scheduled posts are published (default 30s)"},
+	{"SCHEDULER_REBUILD_CMD", "command run after scheduled posts are published"},
+
+	{"DB_DRIVER", "mysql (default), postgres, sqlite or memory"},
+	{"DB_HOST", "database server host"},
+	{"DB_PORT", "database server port"},
+	{"DB_USER", "database user"},
+	{"DB_PASSWORD", "database password"},
+	{"DB_NAME", "database name (default blogapp)"},
+	{"DB_SSLMODE", "PostgreSQL SSL mode (default disable)"},
+	{"DB_PATH", "SQLite database file (default blogapp.db)"},
+	{"DB_AUTO_MIGRATE", "apply pending migrations on start (default true for SQLite only)"},
+
+	{"STORAGE_DRIVER", "local (default), s3 or memory"},
+	{"STORAGE_DIR", "directory of the local storage (default uploads)"},
+	{"S3_ENDPOINT", "endpoint of an S3-compatible store (default Amazon S3)"},
+	{"S3_REGION", "S3 region (default us-east-1)"},
+	{"S3_BUCKET", "S3 bucket"},
+	{"S3_ACCESS_KEY_ID", "S3 access key ID"},
+	{"S3_SECRET_ACCESS_KEY", "S3 secret access key"},
+	{"S3_SESSION_TOKEN", "S3 session token for temporary credentials"},
+	{"S3_PATH_STYLE", "address the bucket in the URL path"},
+	{"AWS_ACCESS_KEY_ID", "fallback for S3_ACCESS_KEY_ID"},
+	{"AWS_SECRET_ACCESS_KEY", "fallback for S3_SECRET_ACCESS_KEY"},
+	{"AWS_SESSION_TOKEN", "fallback for S3_SESSION_TOKEN"},
+
+	{"ACCESS_TOKEN_TTL", "lifetime of access tokens (default 15m)"},
+	{"REFRESH_TOKEN_TTL", "lifetime of refresh tokens (default 720h)"},
+	{"JWT_SIGNING_KEY_PATH", "PEM file with the private key access tokens are signed with"},
+	{"JWT_VERIFY_KEY_PATHS", "comma-separated PEM files with further keys tokens are accepted from"},
+
+	{"MEDIA_MAX_BYTES", "largest media upload in bytes"},
+	{"MEDIA_BASE_URL", "prefix for media URLs"},
+
+	{"SPAM_MAX_LINKS", "links allowed in a comment before it is penalised"},
+	{"SPAM_BLOCKLIST", "comma-separated blocklisted words"},
+	{"COMMENT_RATE_LIMIT", "comments allowed per user within COMMENT_RATE_WINDOW"},
+	{"COMMENT_RATE_WINDOW", "duration of the comment rate window"},
+}
+
+// isSetting reports whether name is a known setting
+func isSetting(name string) bool {
+	for _, s := range settings {
+		if s.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Source looks up settings by name. Flags take precedence over the
+// environment, which takes precedence over the config file.
+type Source struct {
+	values map[string]string
+}
+
+// NewSource builds a source from the config file at path, which may be empty,
+// the environment and the given flag values
+func NewSource(path string, flags map[string]string) (*Source, error) {
+	src := &Source{values: make(map[string]string)}
+
+	if path != "" {
+		file, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := src.merge(file, path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only non-empty variables count, so that an empty one does not clear the file's value
+	env := make(map[string]string)
+	for _, s := range settings {
+		for _, name := range []string{s.Name, s.Name + "_FILE"} {
+			if v := os.Getenv(name); v != "" {
+				env[name] = v
+			}
+		}
+	}
+	if err := src.merge(env, "environment"); err != nil {
+		return nil, err
+	}
+
+	if err := src.merge(flags, "flags"); err != nil {
+		return nil, err
+	}
+
+	return src, nil
+}
+
+// merge adds the values of one layer over those already in the source,
+// reading NAME_FILE values from their files
+func (s *Source) merge(layer map[string]string, origin string) error {
+	for name, v := range layer {
+		if base := strings.TrimSuffix(name, "_FILE"); base != name && isSetting(base) {
+			if _, ok := layer[base]; ok {
+				return fmt.Errorf("%s: both %s and %s are set", origin, base, name)
+			}
+			data, err := os.ReadFile(v)
+			if err != nil {
+				return fmt.Errorf("%s: %s: %w", origin, name, err)
+			}
+			// Secret files usually end with a newline that is not part of the value
+			s.values[base] = strings.TrimRight(string(data), "\r\n")
+			continue
+		}
+		if !isSetting(name) {
+			return fmt.Errorf("%s: unknown setting %s", origin, name)
+		}
+		s.values[name] = v
+	}
+	return nil
+}
+
+// Get returns the value of a setting, or "" when it is not set. It has the
+// signature of os.Getenv, which the packages' ConfigFromEnv functions take.
+func (s *Source) Get(name string) string {
+	return s.values[name]
+}
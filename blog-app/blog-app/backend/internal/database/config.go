@@ -0,0 +1,92 @@
+// backend/internal/database/config.go
+package database
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DefaultPassword is the password used when DB_PASSWORD is not set. It matches
+// the development containers and must never reach production.
+const DefaultPassword = "password"
+
+// Config holds the database connection settings
+type Config struct {
+	Driver      string // A Dialect, or "memory" for the API's in-memory store
+	Host        string
+	Port        string
+	User        string
+	Password    string
+	Name        string
+	SSLMode     string // PostgreSQL only
+	Path        string // SQLite only
+	AutoMigrate bool   // Apply pending migrations when a binary starts
+}
+
+// ConfigFromEnv reads the database settings through getenv. Unset values fall
+// back to defaults for the development containers of the selected driver:
+//   - DB_DRIVER: mysql (default), postgres, sqlite or memory
+//   - DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME: server connection
+//   - DB_SSLMODE: PostgreSQL SSL mode (default "disable")
+//   - DB_PATH: SQLite database file (default "blogapp.db")
+//   - DB_AUTO_MIGRATE: migrate on start; by default only SQLite does, so a
+//     fresh database file is usable without any setup
+func ConfigFromEnv(getenv func(string) string) (Config, error) {
+	cfg := Config{
+		Driver:   getenv("DB_DRIVER"),
+		Host:     getenv("DB_HOST"),
+		Port:     getenv("DB_PORT"),
+		User:     getenv("DB_USER"),
+		Password: getenv("DB_PASSWORD"),
+		Name:     getenv("DB_NAME"),
+		SSLMode:  getenv("DB_SSLMODE"),
+		Path:     getenv("DB_PATH"),
+	}
+	if cfg.Driver == "" {
+		cfg.Driver = string(MySQL)
+	}
+
+	// Fill in the defaults of the driver's development container
+	host, port, user := "mysql", "3306", "root"
+	if Dialect(cfg.Driver) == Postgres {
+		host, port, user = "postgres", "5432", "postgres"
+	}
+	if cfg.Host == "" {
+		cfg.Host = host
+	}
+	if cfg.Port == "" {
+		cfg.Port = port
+	}
+	if cfg.User == "" {
+		cfg.User = user
+	}
+	if cfg.Password == "" {
+		cfg.Password = DefaultPassword
+	}
+	if cfg.Name == "" {
+		cfg.Name = "blogapp"
+	}
+	if cfg.SSLMode == "" {
+		cfg.SSLMode = "disable"
+	}
+	if cfg.Path == "" {
+		cfg.Path = "blogapp.db"
+	}
+
+	cfg.AutoMigrate = Dialect(cfg.Driver) == SQLite
+	if v := getenv("DB_AUTO_MIGRATE"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid DB_AUTO_MIGRATE %q", v)
+		}
+		cfg.AutoMigrate = enabled
+	}
+
+	return cfg, nil
+}
+
+// UsesServer reports whether the driver connects to a database server, which
+// needs credentials, rather than a local file or memory
+func (c Config) UsesServer() bool {
+	return Dialect(c.Driver) == MySQL || Dialect(c.Driver) == Postgres
+}
@@ -4,43 +4,114 @@ package database
 import (
 	"database/sql"
 	"fmt"
-	"os"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 )
 
-// NewConnection establishes a connection to the MySQL database
-func NewConnection() (*sql.DB, error) {
-	// Get database configuration from environment variables
-	dbUser := os.Getenv("DB_USER")
-	if dbUser == "" {
-		dbUser = "root"
+// DB wraps a database connection together with the SQL dialect it speaks
+type DB struct {
+	*sql.DB
+	Dialect Dialect
+}
+
+// Exec executes a query without returning rows, rebinding placeholders for the dialect
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.Exec(db.Dialect.Rebind(query), args...)
+}
+
+// Query executes a query that returns rows, rebinding placeholders for the dialect
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.Query(db.Dialect.Rebind(query), args...)
+}
+
+// QueryRow executes a query that returns at most one row, rebinding placeholders for the dialect
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRow(db.Dialect.Rebind(query), args...)
+}
+
+// Insert executes an INSERT statement and returns the generated ID of the new row.
+// The table must have an "id" primary key column.
+func (db *DB) Insert(query string, args ...interface{}) (int64, error) {
+	if !db.Dialect.SupportsLastInsertID() {
+		var id int64
+		err := db.QueryRow(query+" RETURNING id", args...).Scan(&id)
+		return id, err
 	}
-	
-	dbPassword := os.Getenv("DB_PASSWORD")
-	if dbPassword == "" {
-		dbPassword = "password"
+
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, err
 	}
-	
-	dbHost := os.Getenv("DB_HOST")
-	if dbHost == "" {
-		dbHost = "mysql"
+	return result.LastInsertId()
+}
+
+// Begin starts a transaction that rebinds placeholders like DB does
+func (db *DB) Begin() (*Tx, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
 	}
-	
-	dbPort := os.Getenv("DB_PORT")
-	if dbPort == "" {
-		dbPort = "3306"
+	return &Tx{Tx: tx, Dialect: db.Dialect}, nil
+}
+
+// Tx wraps a transaction together with the SQL dialect it speaks
+type Tx struct {
+	*sql.Tx
+	Dialect Dialect
+}
+
+// Exec executes a query without returning rows, rebinding placeholders for the dialect
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.Exec(tx.Dialect.Rebind(query), args...)
+}
+
+// Query executes a query that returns rows, rebinding placeholders for the dialect
+func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.Query(tx.Dialect.Rebind(query), args...)
+}
+
+// QueryRow executes a query that returns at most one row, rebinding placeholders for the dialect
+func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRow(tx.Dialect.Rebind(query), args...)
+}
+
+// Insert executes an INSERT statement and returns the generated ID of the new row.
+// The table must have an "id" primary key column.
+func (tx *Tx) Insert(query string, args ...interface{}) (int64, error) {
+	if !tx.Dialect.SupportsLastInsertID() {
+		var id int64
+		err := tx.QueryRow(query+" RETURNING id", args...).Scan(&id)
+		return id, err
 	}
-	
-	dbName := os.Getenv("DB_NAME")
-	if dbName == "" {
-		dbName = "blogapp"
+
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// NewConnection establishes a connection to the database selected by the
+// configuration's driver
+func NewConnection(cfg Config) (*DB, error) {
+	switch Dialect(cfg.Driver) {
+	case MySQL:
+		return newMySQLConnection(cfg)
+	case SQLite:
+		return newSQLiteConnection(cfg)
+	case Postgres:
+		return newPostgresConnection(cfg)
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
 	}
+}
 
+// newMySQLConnection establishes a connection to the MySQL database
+func newMySQLConnection(cfg Config) (*DB, error) {
 	// Create the DSN (Data Source Name)
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true", 
-		dbUser, dbPassword, dbHost, dbPort, dbName)
+	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?parseTime=true",
+		cfg.User, cfg.Password, cfg.Host, cfg.Port, cfg.Name)
 
 	// Connect to the database
 	db, err := sql.Open("mysql", dsn)
@@ -59,5 +130,5 @@ func NewConnection() (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	return db, nil
-}
\ No newline at end of file
+	return &DB{DB: db, Dialect: MySQL}, nil
+}
@@ -0,0 +1,162 @@
+// backend/internal/database/database_test.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// recordingDriver is a database/sql driver that records the statements it is
+// given. Queries return a single row holding the ID 42, and Exec results have
+// no last insert ID, like the PostgreSQL driver.
+type recordingDriver struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+func (d *recordingDriver) Open(name string) (driver.Conn, error) { return &recordingConn{d}, nil }
+
+func (d *recordingDriver) record(query string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, query)
+}
+
+// recordingConn is a connection of a recordingDriver
+type recordingConn struct{ d *recordingDriver }
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return &recordingStmt{c.d, query}, nil
+}
+func (c *recordingConn) Close() error              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error) { return recordingTx{}, nil }
+
+// recordingTx is a transaction that does nothing
+type recordingTx struct{}
+
+func (recordingTx) Commit() error   { return nil }
+func (recordingTx) Rollback() error { return nil }
+
+// recordingStmt records its query when it is run
+type recordingStmt struct {
+	d     *recordingDriver
+	query string
+}
+
+func (s *recordingStmt) Close() error  { return nil }
+func (s *recordingStmt) NumInput() int { return -1 }
+
+func (s *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.record(s.query)
+	return noInsertID{}, nil
+}
+
+func (s *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.record(s.query)
+	return &idRows{}, nil
+}
+
+// noInsertID is a result without a last insert ID
+type noInsertID struct{}
+
+func (noInsertID) LastInsertId() (int64, error) {
+	return 0, errors.New("LastInsertId is not supported")
+}
+func (noInsertID) RowsAffected() (int64, error) { return 1, nil }
+
+// idRows is a result set with one row holding the ID 42
+type idRows struct{ done bool }
+
+func (r *idRows) Columns() []string { return []string{"id"} }
+func (r *idRows) Close() error      { return nil }
+func (r *idRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = int64(42)
+	return nil
+}
+
+// newRecordingDB opens a Postgres-dialect DB on a fresh recordingDriver
+func newRecordingDB(t *testing.T) (*DB, *recordingDriver) {
+	t.Helper()
+
+	d := &recordingDriver{}
+	db := sql.OpenDB(connector{d})
+	t.Cleanup(func() { db.Close() })
+	return &DB{DB: db, Dialect: Postgres}, d
+}
+
+// connector opens connections of a given recordingDriver
+type connector struct{ d *recordingDriver }
+
+func (c connector) Connect(context.Context) (driver.Conn, error) { return c.d.Open("") }
+func (c connector) Driver() driver.Driver                        { return c.d }
+
+func TestInsertPostgres(t *testing.T) {
+	db, d := newRecordingDB(t)
+
+	id, err := db.Insert("INSERT INTO posts (title, author_id) VALUES (?, ?)", "Hello", 1)
+	if err != nil {
+		t.Fatalf("Insert() error = %v", err)
+	}
+	if id != 42 {
+		t.Errorf("Insert() = %d, want 42", id)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	id, err = tx.Insert("INSERT INTO tags (name) VALUES (?)", "go")
+	if err != nil {
+		t.Fatalf("Tx.Insert() error = %v", err)
+	}
+	if id != 42 {
+		t.Errorf("Tx.Insert() = %d, want 42", id)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	want := []string{
+		"INSERT INTO posts (title, author_id) VALUES ($1, $2) RETURNING id",
+		"INSERT INTO tags (name) VALUES ($1) RETURNING id",
+	}
+	if len(d.queries) != len(want) {
+		t.Fatalf("driver got queries %q, want %q", d.queries, want)
+	}
+	for i := range want {
+		if d.queries[i] != want[i] {
+			t.Errorf("query %d = %q, want %q", i, d.queries[i], want[i])
+		}
+	}
+}
+
+func TestInsertSQLite(t *testing.T) {
+	db, err := NewConnection(Config{Driver: string(SQLite), Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("NewConnection() error = %v", err)
+	}
+	defer db.Close()
+
+	if _, err := db.Exec("CREATE TABLE things (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT NOT NULL)"); err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	for want := int64(1); want <= 2; want++ {
+		id, err := db.Insert("INSERT INTO things (name) VALUES (?)", "thing")
+		if err != nil {
+			t.Fatalf("Insert() error = %v", err)
+		}
+		if id != want {
+			t.Errorf("Insert() = %d, want %d", id, want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// backend/internal/database/dialect.go
+package database
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dialect identifies the SQL flavour spoken by a connection
+type Dialect string
+
+// Supported dialects, named after the DB_DRIVER values that select them
+const (
+	MySQL    Dialect = "mysql"
+	SQLite   Dialect = "sqlite"
+	Postgres Dialect = "postgres"
+)
+
+// Rebind rewrites the "?" placeholders used throughout the models into the
+// form expected by the dialect. Postgres uses numbered "$1" placeholders;
+// question marks inside single-quoted literals are left untouched.
+func (d Dialect) Rebind(query string) string {
+	if d != Postgres {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 10)
+	n := 0
+	inQuote := false
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\'':
+			inQuote = !inQuote
+		case c == '?' && !inQuote:
+			n++
+			b.WriteByte('$')
+			b.WriteString(strconv.Itoa(n))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// SupportsLastInsertID reports whether the driver implements sql.Result.LastInsertId.
+// Postgres does not, and generated IDs must be read back with RETURNING instead.
+func (d Dialect) SupportsLastInsertID() bool {
+	return d != Postgres
+}
+
+// Time normalises a timestamp before it is bound to a query.
+// SQLite stores timestamps as text, so every value is written in UTC
+// to keep ordering and range comparisons on those columns correct.
+func (d Dialect) Time(t time.Time) time.Time {
+	if d == SQLite {
+		return t.UTC()
+	}
+	return t
+}
@@ -0,0 +1,74 @@
+// backend/internal/database/dialect_test.go
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		query   string
+		want    string
+	}{
+		{
+			name:    "mysql is unchanged",
+			dialect: MySQL,
+			query:   "SELECT * FROM posts WHERE id = ? AND status = ?",
+			want:    "SELECT * FROM posts WHERE id = ? AND status = ?",
+		},
+		{
+			name:    "sqlite is unchanged",
+			dialect: SQLite,
+			query:   "SELECT * FROM posts WHERE id = ?",
+			want:    "SELECT * FROM posts WHERE id = ?",
+		},
+		{
+			name:    "postgres numbers placeholders",
+			dialect: Postgres,
+			query:   "UPDATE posts SET title = ?, content = ? WHERE id = ?",
+			want:    "UPDATE posts SET title = $1, content = $2 WHERE id = $3",
+		},
+		{
+			name:    "postgres skips quoted question marks",
+			dialect: Postgres,
+			query:   "SELECT '?' || title FROM posts WHERE title <> 'why?' AND id = ?",
+			want:    "SELECT '?' || title FROM posts WHERE title <> 'why?' AND id = $1",
+		},
+		{
+			name:    "postgres handles escaped quotes",
+			dialect: Postgres,
+			query:   "SELECT 'it''s ?' FROM posts WHERE id = ?",
+			want:    "SELECT 'it''s ?' FROM posts WHERE id = $1",
+		},
+		{
+			name:    "postgres without placeholders",
+			dialect: Postgres,
+			query:   "SELECT COUNT(*) FROM posts",
+			want:    "SELECT COUNT(*) FROM posts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.Rebind(tt.query); got != tt.want {
+				t.Errorf("Rebind() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTime(t *testing.T) {
+	local := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	if got := SQLite.Time(local); got.Location() != time.UTC || !got.Equal(local) {
+		t.Errorf("SQLite.Time() = %v, want the same instant in UTC", got)
+	}
+	for _, d := range []Dialect{MySQL, Postgres} {
+		if got := d.Time(local); got != local {
+			t.Errorf("%s.Time() = %v, want %v", d, got, local)
+		}
+	}
+}
@@ -0,0 +1,423 @@
+// backend/internal/database/migrate.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Migration is a single numbered schema change with its up and down scripts
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus reports whether a migration has been applied
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator applies and rolls back the embedded migrations for a connection
+type Migrator struct {
+	db         *DB
+	migrations []Migration
+}
+
+// NewMigrator loads the migrations for the connection's dialect
+func NewMigrator(db *DB) (*Migrator, error) {
+	migrations, err := loadMigrations(db.Dialect)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// AutoMigrate applies pending migrations if the configuration enables it
+// and returns how many were applied
+func AutoMigrate(db *DB, cfg Config) (int, error) {
+	if !cfg.AutoMigrate {
+		return 0, nil
+	}
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return 0, err
+	}
+	return migrator.Up()
+}
+
+// Latest returns the highest known migration version
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Version returns the highest applied migration version, or 0 if none
+func (m *Migrator) Version() (int, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	version := 0
+	for v := range applied {
+		if v > version {
+			version = v
+		}
+	}
+	return version, nil
+}
+
+// Up applies every pending migration and returns how many were applied
+func (m *Migrator) Up() (int, error) {
+	return m.migrate(m.Latest())
+}
+
+// Down rolls back the given number of applied migrations, newest first. Only
+// down scripts run: pending migrations below the rolled back ones stay pending.
+func (m *Migrator) Down(steps int) (int, error) {
+	unlock, err := m.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	var versions []int
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	if steps < len(versions) {
+		versions = versions[:steps]
+	}
+
+	count := 0
+	for _, v := range versions {
+		mig := m.find(v)
+		if mig == nil {
+			return count, fmt.Errorf("cannot roll back unknown migration version %d", v)
+		}
+		if err := m.run(*mig, false); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// To migrates up or down until the given version is the latest applied one
+func (m *Migrator) To(version int) (int, error) {
+	if version != 0 && m.find(version) == nil {
+		return 0, fmt.Errorf("unknown migration version %d", version)
+	}
+	return m.migrate(version)
+}
+
+// Status lists every known migration and whether it has been applied
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		appliedAt, ok := applied[mig.Version]
+		statuses = append(statuses, MigrationStatus{
+			Version:   mig.Version,
+			Name:      mig.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+	return statuses, nil
+}
+
+// migrate applies pending migrations up to target and rolls back applied
+// migrations above it
+func (m *Migrator) migrate(target int) (int, error) {
+	unlock, err := m.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	applied, err := m.applied()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+
+	// Roll back, newest first
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok || mig.Version <= target {
+			continue
+		}
+		if err := m.run(mig, false); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	// Apply, oldest first
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; ok || mig.Version > target {
+			continue
+		}
+		if err := m.run(mig, true); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// lockName and lockKey identify the lock held while migrating, so that API
+// servers starting together do not apply the same migrations at once
+const (
+	lockName    = "blogapp_schema_migrations" // MySQL named lock
+	lockKey     = 3927419602                  // PostgreSQL advisory lock
+	lockTimeout = 10 * time.Minute
+)
+
+// lock waits for the database-wide migration lock and returns the function
+// that releases it. MySQL and PostgreSQL hold the lock on a connection of its
+// own. SQLite takes no lock, as a database file is not shared between servers.
+func (m *Migrator) lock() (func(), error) {
+	if m.db.Dialect != MySQL && m.db.Dialect != Postgres {
+		return func() {}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), lockTimeout)
+	defer cancel()
+	conn, err := m.db.DB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	release := func() {
+		if m.db.Dialect == MySQL {
+			conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+		} else {
+			conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+		}
+		conn.Close()
+	}
+
+	if m.db.Dialect == MySQL {
+		// GET_LOCK returns 1 once the lock is held and 0 on timeout
+		var held sql.NullInt64
+		err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(lockTimeout.Seconds())).Scan(&held)
+		if err == nil && held.Int64 != 1 {
+			err = errors.New("timed out")
+		}
+	} else {
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to take the migration lock: %w", err)
+	}
+
+	return release, nil
+}
+
+// run executes one migration script and records the result in schema_migrations.
+// MySQL commits each DDL statement on its own, so a MySQL migration that fails
+// partway leaves the statements before the failing one applied but unrecorded;
+// they must be undone by hand before retrying.
+func (m *Migrator) run(mig Migration, up bool) error {
+	script, direction := mig.Down, "down"
+	if up {
+		script, direction = mig.Up, "up"
+	}
+
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, stmt := range splitStatements(script) {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migration %04d_%s (%s) failed: %w", mig.Version, mig.Name, direction, err)
+		}
+	}
+
+	if up {
+		_, err = tx.Exec(
+			"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+			mig.Version, mig.Name, time.Now().UTC(),
+		)
+	} else {
+		_, err = tx.Exec("DELETE FROM schema_migrations WHERE version = ?", mig.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// applied returns the applied migration versions and when they were applied,
+// creating the tracking table if needed
+func (m *Migrator) applied() (map[int]time.Time, error) {
+	_, err := m.db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			applied_at TIMESTAMP NOT NULL
+		)`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	rows, err := m.db.Query("SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// find returns the migration with the given version, or nil
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+// loadMigrations reads the embedded NNNN_name.up.sql / NNNN_name.down.sql
+// pairs for a dialect, ordered by version
+func loadMigrations(d Dialect) ([]Migration, error) {
+	dir := path.Join("migrations", string(d))
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %q: %w", d, err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		file := entry.Name()
+
+		var direction string
+		switch {
+		case strings.HasSuffix(file, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(file, ".down.sql"):
+			direction = "down"
+		default:
+			continue
+		}
+
+		base := strings.TrimSuffix(file, "."+direction+".sql")
+		prefix, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name %q", file)
+		}
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", file, err)
+		}
+
+		contents, err := fs.ReadFile(migrationFiles, path.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: name}
+			byVersion[version] = mig
+		}
+		if direction == "up" {
+			mig.Up = string(contents)
+		} else {
+			mig.Down = string(contents)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both up and down scripts", mig.Version, mig.Name)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// splitStatements splits a migration script into individual statements.
+// Statements end with a semicolon at the end of a line; "--" comment lines are dropped.
+func splitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+
+		current.WriteString(line)
+		current.WriteString("\n")
+
+		if strings.HasSuffix(trimmed, ";") {
+			statements = append(statements, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		statements = append(statements, rest)
+	}
+
+	return statements
+}
@@ -0,0 +1,222 @@
+// backend/internal/database/migrate_test.go
+package database
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newSQLiteMigrator opens a new SQLite file and a migrator for it
+func newSQLiteMigrator(t *testing.T) (*DB, *Migrator) {
+	t.Helper()
+
+	db, err := NewConnection(Config{Driver: string(SQLite), Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("NewConnection() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator() error = %v", err)
+	}
+	return db, migrator
+}
+
+// schema returns the SQL of every table and index in a SQLite database,
+// except the migration bookkeeping
+func schema(t *testing.T, db *DB) []string {
+	t.Helper()
+
+	rows, err := db.Query(`
+		SELECT sql FROM sqlite_master
+		WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%' AND name <> 'schema_migrations'
+		ORDER BY name`)
+	if err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+	defer rows.Close()
+
+	var statements []string
+	for rows.Next() {
+		var sql string
+		if err := rows.Scan(&sql); err != nil {
+			t.Fatalf("failed to read schema: %v", err)
+		}
+		statements = append(statements, sql)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("failed to read schema: %v", err)
+	}
+	return statements
+}
+
+// mustVersion fails the test unless the migrator is at the given version
+func mustVersion(t *testing.T, m *Migrator, want int) {
+	t.Helper()
+
+	got, err := m.Version()
+	if err != nil {
+		t.Fatalf("Version() error = %v", err)
+	}
+	if got != want {
+		t.Fatalf("Version() = %d, want %d", got, want)
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	// Every dialect must have the same numbered migrations, so that a version
+	// means the same schema whatever the database
+	sqlite, err := loadMigrations(SQLite)
+	if err != nil {
+		t.Fatalf("loadMigrations(%s) error = %v", SQLite, err)
+	}
+	for i, mig := range sqlite {
+		if mig.Version != i+1 {
+			t.Fatalf("migration %d has version %d, want %d", i, mig.Version, i+1)
+		}
+	}
+
+	for _, d := range []Dialect{MySQL, Postgres} {
+		migrations, err := loadMigrations(d)
+		if err != nil {
+			t.Fatalf("loadMigrations(%s) error = %v", d, err)
+		}
+		if len(migrations) != len(sqlite) {
+			t.Fatalf("%s has %d migrations, want %d", d, len(migrations), len(sqlite))
+		}
+		for i, mig := range migrations {
+			if mig.Version != sqlite[i].Version || mig.Name != sqlite[i].Name {
+				t.Errorf("%s migration %04d_%s does not match %04d_%s", d, mig.Version, mig.Name, sqlite[i].Version, sqlite[i].Name)
+			}
+		}
+	}
+}
+
+func TestMigrateRoundTrip(t *testing.T) {
+	db, m := newSQLiteMigrator(t)
+
+	applied, err := m.Up()
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if applied != m.Latest() {
+		t.Fatalf("Up() applied %d migrations, want %d", applied, m.Latest())
+	}
+	mustVersion(t, m, m.Latest())
+	want := schema(t, db)
+
+	// Roll back one migration at a time, down to an empty database
+	for version := m.Latest(); version > 0; version-- {
+		if _, err := m.Down(1); err != nil {
+			t.Fatalf("Down(1) from version %d error = %v", version, err)
+		}
+		mustVersion(t, m, version-1)
+	}
+	if got := schema(t, db); len(got) != 0 {
+		t.Fatalf("schema after rolling everything back = %q, want none", got)
+	}
+
+	if _, err := m.Up(); err != nil {
+		t.Fatalf("Up() again error = %v", err)
+	}
+	if got := schema(t, db); !reflect.DeepEqual(got, want) {
+		t.Errorf("schema after a round trip differs:\n got %q\nwant %q", got, want)
+	}
+}
+
+func TestMigrateDown(t *testing.T) {
+	tests := []struct {
+		name        string
+		from        int
+		steps       int
+		wantCount   int
+		wantVersion int
+	}{
+		{name: "one step", from: 5, steps: 1, wantCount: 1, wantVersion: 4},
+		{name: "several steps", from: 5, steps: 3, wantCount: 3, wantVersion: 2},
+		{name: "more steps than applied", from: 2, steps: 10, wantCount: 2, wantVersion: 0},
+		{name: "nothing applied", from: 0, steps: 1, wantCount: 0, wantVersion: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, m := newSQLiteMigrator(t)
+			if _, err := m.To(tt.from); err != nil {
+				t.Fatalf("To(%d) error = %v", tt.from, err)
+			}
+
+			count, err := m.Down(tt.steps)
+			if err != nil {
+				t.Fatalf("Down(%d) error = %v", tt.steps, err)
+			}
+			if count != tt.wantCount {
+				t.Errorf("Down(%d) rolled back %d migrations, want %d", tt.steps, count, tt.wantCount)
+			}
+			mustVersion(t, m, tt.wantVersion)
+
+			// Rolling back never applies the pending migrations above
+			statuses, err := m.Status()
+			if err != nil {
+				t.Fatalf("Status() error = %v", err)
+			}
+			for _, status := range statuses {
+				if status.Applied != (status.Version <= tt.wantVersion) {
+					t.Errorf("migration %d applied = %v", status.Version, status.Applied)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateToUnknownVersion(t *testing.T) {
+	_, m := newSQLiteMigrator(t)
+
+	if _, err := m.To(m.Latest() + 1); err == nil || !strings.Contains(err.Error(), "unknown migration version") {
+		t.Errorf("To() error = %v, want an unknown version error", err)
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []string
+	}{
+		{
+			name:   "one statement per line",
+			script: "CREATE TABLE a (id INT);\nCREATE TABLE b (id INT);\n",
+			want:   []string{"CREATE TABLE a (id INT)", "CREATE TABLE b (id INT)"},
+		},
+		{
+			name:   "statement over several lines",
+			script: "CREATE TABLE a (\n    id INT\n);",
+			want:   []string{"CREATE TABLE a (\n    id INT\n)"},
+		},
+		{
+			name:   "comments and blank lines are dropped",
+			script: "-- A comment\n\nDROP TABLE a;\n  -- Another\n",
+			want:   []string{"DROP TABLE a"},
+		},
+		{
+			name:   "comment-only script",
+			script: "-- Nothing to do for this dialect\n",
+			want:   nil,
+		},
+		{
+			name:   "missing final semicolon",
+			script: "DROP TABLE a;\nDROP TABLE b",
+			want:   []string{"DROP TABLE a", "DROP TABLE b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := splitStatements(tt.script); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitStatements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
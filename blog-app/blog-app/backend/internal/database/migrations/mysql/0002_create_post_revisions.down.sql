@@ -0,0 +1,3 @@
+ALTER TABLE posts DROP COLUMN revision;
+
+DROP TABLE IF EXISTS post_revisions;
@@ -0,0 +1,5 @@
+DROP INDEX idx_posts_status ON posts;
+
+ALTER TABLE posts
+    DROP COLUMN status,
+    DROP COLUMN published_at;
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS post_slugs;
+
+DROP INDEX uq_posts_slug ON posts;
+
+ALTER TABLE posts DROP COLUMN slug;
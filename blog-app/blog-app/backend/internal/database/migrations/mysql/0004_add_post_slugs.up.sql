@@ -0,0 +1,17 @@
+-- Add a unique, URL-friendly slug to every post
+ALTER TABLE posts ADD COLUMN slug VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Existing posts get a slug based on their ID, which authors can change later
+UPDATE posts SET slug = CONCAT('post-', id);
+
+CREATE UNIQUE INDEX uq_posts_slug ON posts (slug);
+
+-- Previous slugs of each post, kept so old links can redirect to the current one
+CREATE TABLE IF NOT EXISTS post_slugs (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    post_id INT NOT NULL,
+    slug VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE KEY uq_post_slugs_slug (slug),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE
+);
@@ -0,0 +1,5 @@
+ALTER TABLE posts
+    DROP FOREIGN KEY fk_posts_category,
+    DROP COLUMN category_id;
+
+DROP TABLE IF EXISTS categories;
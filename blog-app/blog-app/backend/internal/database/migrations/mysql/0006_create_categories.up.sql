@@ -0,0 +1,17 @@
+-- Create categories table, a tree of ordered siblings
+CREATE TABLE IF NOT EXISTS categories (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    parent_id INT NULL,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (parent_id) REFERENCES categories(id)
+);
+
+CREATE INDEX idx_categories_parent ON categories (parent_id, position);
+
+-- Every post may belong to one category
+ALTER TABLE posts
+    ADD COLUMN category_id INT NULL,
+    ADD CONSTRAINT fk_posts_category FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE SET NULL;
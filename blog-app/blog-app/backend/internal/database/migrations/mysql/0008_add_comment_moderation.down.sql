@@ -0,0 +1,8 @@
+DROP INDEX idx_comments_status ON comments;
+
+ALTER TABLE comments
+    DROP FOREIGN KEY fk_comments_moderator,
+    DROP COLUMN status,
+    DROP COLUMN spam_score,
+    DROP COLUMN moderated_by,
+    DROP COLUMN moderated_at;
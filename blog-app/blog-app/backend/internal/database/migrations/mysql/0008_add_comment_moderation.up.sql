@@ -0,0 +1,9 @@
+-- Add the moderation state of comments; comments written before moderation existed stay visible
+ALTER TABLE comments
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'approved',
+    ADD COLUMN spam_score DOUBLE NOT NULL DEFAULT 0,
+    ADD COLUMN moderated_by INT NULL,
+    ADD COLUMN moderated_at TIMESTAMP NULL,
+    ADD CONSTRAINT fk_comments_moderator FOREIGN KEY (moderated_by) REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_comments_status ON comments (status, created_at);
@@ -0,0 +1,3 @@
+DROP INDEX ft_posts_search ON posts;
+
+ALTER TABLE posts DROP COLUMN search_text;
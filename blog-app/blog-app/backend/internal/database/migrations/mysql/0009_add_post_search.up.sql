@@ -0,0 +1,7 @@
+-- Plain text of each post with the HTML stripped, kept up to date by the API.
+-- Existing posts are filled in by `migrate search-text`, which `migrate up` and
+-- the API run after applying this migration.
+ALTER TABLE posts ADD COLUMN search_text LONGTEXT NULL;
+
+-- Full-text index used by post search
+CREATE FULLTEXT INDEX ft_posts_search ON posts (title, search_text);
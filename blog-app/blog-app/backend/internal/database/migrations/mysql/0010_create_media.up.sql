@@ -0,0 +1,23 @@
+-- Create media table; each item has one row per stored rendition in media_variants,
+-- including the "original"
+CREATE TABLE IF NOT EXISTS media (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    owner_id INT NOT NULL,
+    filename VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (owner_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_media_owner ON media (owner_id, created_at);
+
+CREATE TABLE IF NOT EXISTS media_variants (
+    media_id INT NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    size BIGINT NOT NULL,
+    storage_key VARCHAR(255) NOT NULL,
+    PRIMARY KEY (media_id, name),
+    FOREIGN KEY (media_id) REFERENCES media(id) ON DELETE CASCADE
+);
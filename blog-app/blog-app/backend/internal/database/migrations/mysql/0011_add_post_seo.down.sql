@@ -0,0 +1,8 @@
+ALTER TABLE posts
+    DROP FOREIGN KEY fk_posts_featured_image,
+    DROP COLUMN featured_image_id,
+    DROP COLUMN meta_title,
+    DROP COLUMN meta_description,
+    DROP COLUMN canonical_url,
+    DROP COLUMN og_title,
+    DROP COLUMN og_description;
@@ -0,0 +1,9 @@
+-- Add the featured image and SEO metadata of posts; empty strings fall back to the post's own fields
+ALTER TABLE posts
+    ADD COLUMN featured_image_id INT NULL,
+    ADD COLUMN meta_title VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN meta_description VARCHAR(300) NOT NULL DEFAULT '',
+    ADD COLUMN canonical_url VARCHAR(2000) NOT NULL DEFAULT '',
+    ADD COLUMN og_title VARCHAR(100) NOT NULL DEFAULT '',
+    ADD COLUMN og_description VARCHAR(300) NOT NULL DEFAULT '',
+    ADD CONSTRAINT fk_posts_featured_image FOREIGN KEY (featured_image_id) REFERENCES media(id) ON DELETE SET NULL;
@@ -0,0 +1,17 @@
+-- Create website_members table; each member has a role on the website, and
+-- the users who created existing websites become their owners
+CREATE TABLE IF NOT EXISTS website_members (
+    website_id INT NOT NULL,
+    user_id INT NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (website_id, user_id),
+    FOREIGN KEY (website_id) REFERENCES websites(id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_website_members_user ON website_members (user_id);
+
+INSERT INTO website_members (website_id, user_id, role, created_at, updated_at)
+SELECT id, owner_id, 'owner', created_at, created_at FROM websites WHERE owner_id IS NOT NULL;
@@ -0,0 +1,16 @@
+-- Create website_invites table; an invite offers a role on a website to the
+-- user with an email address, registered now or later, who becomes a member by
+-- accepting it
+CREATE TABLE IF NOT EXISTS website_invites (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    website_id INT NOT NULL,
+    email VARCHAR(100) NOT NULL,
+    role VARCHAR(20) NOT NULL,
+    invited_by INT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (website_id, email),
+    FOREIGN KEY (website_id) REFERENCES websites(id) ON DELETE CASCADE,
+    FOREIGN KEY (invited_by) REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_website_invites_email ON website_invites (email);
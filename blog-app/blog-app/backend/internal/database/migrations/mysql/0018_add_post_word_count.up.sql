@@ -0,0 +1,5 @@
+-- Number of words in the plain text of each post, kept up to date by the API
+-- so that post listings need not load the content. Existing posts are filled
+-- in by `migrate search-text`, which `migrate up` and the API run after
+-- applying this migration.
+ALTER TABLE posts ADD COLUMN word_count INT NULL;
@@ -0,0 +1,24 @@
+-- Create post revisions table, one immutable row per saved version of a post
+CREATE TABLE IF NOT EXISTS post_revisions (
+    id SERIAL PRIMARY KEY,
+    post_id INT NOT NULL,
+    revision INT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    content TEXT NOT NULL,
+    editor_id INT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    UNIQUE (post_id, revision),
+    FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    FOREIGN KEY (editor_id) REFERENCES users(id) ON DELETE SET NULL
+);
+
+-- Record the current state of existing posts as their first revision
+INSERT INTO post_revisions (post_id, revision, title, content, editor_id, created_at)
+SELECT id, 1, title, content, author_id, updated_at FROM posts;
+
+-- Number of the latest revision of each post. Revision numbers are taken by
+-- incrementing it, which holds concurrent saves of the same post back until
+-- the first one commits.
+ALTER TABLE posts ADD COLUMN revision INT NOT NULL DEFAULT 0;
+
+UPDATE posts SET revision = 1;
@@ -0,0 +1,4 @@
+DROP INDEX idx_posts_status;
+
+ALTER TABLE posts DROP COLUMN status;
+ALTER TABLE posts DROP COLUMN published_at;
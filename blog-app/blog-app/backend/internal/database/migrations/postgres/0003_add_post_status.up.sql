@@ -0,0 +1,8 @@
+-- Add the post lifecycle status and publication time
+ALTER TABLE posts ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'draft';
+ALTER TABLE posts ADD COLUMN published_at TIMESTAMPTZ NULL;
+
+-- Posts created before statuses existed were already public
+UPDATE posts SET status = 'published', published_at = created_at;
+
+CREATE INDEX idx_posts_status ON posts (status, created_at);
@@ -0,0 +1,17 @@
+-- Create categories table, a tree of ordered siblings
+CREATE TABLE IF NOT EXISTS categories (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    parent_id INT NULL,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    FOREIGN KEY (parent_id) REFERENCES categories(id)
+);
+
+CREATE INDEX idx_categories_parent ON categories (parent_id, position);
+
+-- Every post may belong to one category
+ALTER TABLE posts ADD COLUMN category_id INT NULL REFERENCES categories(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_posts_category ON posts (category_id);
@@ -0,0 +1,7 @@
+-- Add the moderation state of comments; comments written before moderation existed stay visible
+ALTER TABLE comments ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'approved';
+ALTER TABLE comments ADD COLUMN spam_score DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN moderated_by INT NULL REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE comments ADD COLUMN moderated_at TIMESTAMPTZ NULL;
+
+CREATE INDEX idx_comments_status ON comments (status, created_at);
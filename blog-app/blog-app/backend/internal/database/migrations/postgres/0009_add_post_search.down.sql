@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_posts_search;
+ALTER TABLE posts DROP COLUMN search_vector;
+ALTER TABLE posts DROP COLUMN search_text;
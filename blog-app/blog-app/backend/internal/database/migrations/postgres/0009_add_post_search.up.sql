@@ -0,0 +1,14 @@
+-- Plain text of each post with the HTML stripped, kept up to date by the API.
+-- Existing posts are filled in by `migrate search-text`, which `migrate up` and
+-- the API run after applying this migration; the API builds its search index
+-- from this column.
+ALTER TABLE posts ADD COLUMN search_text TEXT NULL;
+
+-- Full-text search vector of each post, kept up to date by PostgreSQL from the
+-- title and the plain text, with title words ranked above body words
+ALTER TABLE posts ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', COALESCE(title, '')), 'A') ||
+    setweight(to_tsvector('simple', COALESCE(search_text, '')), 'B')
+) STORED;
+
+CREATE INDEX idx_posts_search ON posts USING GIN (search_vector);
@@ -0,0 +1,7 @@
+-- Add the featured image and SEO metadata of posts; empty strings fall back to the post's own fields
+ALTER TABLE posts ADD COLUMN featured_image_id INT NULL REFERENCES media(id) ON DELETE SET NULL;
+ALTER TABLE posts ADD COLUMN meta_title VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN meta_description VARCHAR(300) NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN canonical_url VARCHAR(2000) NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN og_title VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN og_description VARCHAR(300) NOT NULL DEFAULT '';
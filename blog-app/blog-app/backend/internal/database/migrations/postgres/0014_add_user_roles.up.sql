@@ -0,0 +1,2 @@
+-- Add the role of users; existing users become authors and ADMIN_USERNAMES promotes admins at startup
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'author';
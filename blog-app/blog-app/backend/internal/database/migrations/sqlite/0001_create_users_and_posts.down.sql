@@ -0,0 +1,3 @@
+-- Drop posts first, it references users
+DROP TABLE IF EXISTS posts;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,18 @@
+-- Create categories table, a tree of ordered siblings
+CREATE TABLE IF NOT EXISTS categories (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name VARCHAR(100) NOT NULL,
+    parent_id INTEGER NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    FOREIGN KEY (parent_id) REFERENCES categories(id)
+);
+
+CREATE INDEX idx_categories_parent ON categories (parent_id, position);
+
+-- Every post may belong to one category. SQLite cannot drop a column that is
+-- part of a foreign key, so the store clears category_id itself on delete.
+ALTER TABLE posts ADD COLUMN category_id INTEGER NULL;
+
+CREATE INDEX idx_posts_category ON posts (category_id);
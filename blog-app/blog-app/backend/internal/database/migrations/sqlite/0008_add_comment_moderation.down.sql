@@ -0,0 +1,6 @@
+DROP INDEX idx_comments_status;
+
+ALTER TABLE comments DROP COLUMN status;
+ALTER TABLE comments DROP COLUMN spam_score;
+ALTER TABLE comments DROP COLUMN moderated_by;
+ALTER TABLE comments DROP COLUMN moderated_at;
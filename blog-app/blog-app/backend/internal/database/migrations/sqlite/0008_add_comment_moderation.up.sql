@@ -0,0 +1,8 @@
+-- Add the moderation state of comments; comments written before moderation existed stay visible.
+-- moderated_by has no foreign key, as SQLite could not drop the column again.
+ALTER TABLE comments ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'approved';
+ALTER TABLE comments ADD COLUMN spam_score REAL NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN moderated_by INTEGER NULL;
+ALTER TABLE comments ADD COLUMN moderated_at TIMESTAMP NULL;
+
+CREATE INDEX idx_comments_status ON comments (status, created_at);
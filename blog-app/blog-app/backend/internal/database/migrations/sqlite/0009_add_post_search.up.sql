@@ -0,0 +1,5 @@
+-- Plain text of each post with the HTML stripped, kept up to date by the API.
+-- Existing posts are filled in by `migrate search-text`, which `migrate up` and
+-- the API run after applying this migration; the API builds its search index
+-- from this column.
+ALTER TABLE posts ADD COLUMN search_text TEXT NULL;
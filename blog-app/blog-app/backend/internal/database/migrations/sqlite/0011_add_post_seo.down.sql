@@ -0,0 +1,6 @@
+ALTER TABLE posts DROP COLUMN featured_image_id;
+ALTER TABLE posts DROP COLUMN meta_title;
+ALTER TABLE posts DROP COLUMN meta_description;
+ALTER TABLE posts DROP COLUMN canonical_url;
+ALTER TABLE posts DROP COLUMN og_title;
+ALTER TABLE posts DROP COLUMN og_description;
@@ -0,0 +1,8 @@
+-- Add the featured image and SEO metadata of posts; empty strings fall back to the post's own fields.
+-- featured_image_id has no foreign key, as SQLite could not drop the column again.
+ALTER TABLE posts ADD COLUMN featured_image_id INTEGER NULL;
+ALTER TABLE posts ADD COLUMN meta_title VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN meta_description VARCHAR(300) NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN canonical_url VARCHAR(2000) NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN og_title VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE posts ADD COLUMN og_description VARCHAR(300) NOT NULL DEFAULT '';
@@ -0,0 +1,33 @@
+// backend/internal/database/sqlite.go
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite"
+)
+
+// newSQLiteConnection opens the SQLite database file at the configured path.
+// The file is created if it does not exist yet.
+func newSQLiteConnection(cfg Config) (*DB, error) {
+	// Foreign keys are off by default in SQLite, and a busy timeout avoids
+	// spurious "database is locked" errors when writes overlap
+	dsn := fmt.Sprintf("file:%s?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", cfg.Path)
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database connection: %w", err)
+	}
+
+	// SQLite allows a single writer, so serialise access through one connection
+	db.SetMaxOpenConns(1)
+
+	// Verify the connection
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	return &DB{DB: db, Dialect: SQLite}, nil
+}
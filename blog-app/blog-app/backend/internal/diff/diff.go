@@ -0,0 +1,242 @@
+// backend/internal/diff/diff.go
+package diff
+
+import (
+	"errors"
+	"strings"
+)
+
+// Op is the kind of change a Chunk represents
+type Op string
+
+// Chunk operations
+const (
+	Equal  Op = "equal"
+	Insert Op = "insert"
+	Delete Op = "delete"
+)
+
+// Chunk is a run of consecutive lines sharing the same operation
+type Chunk struct {
+	Op    Op       `json:"op"`
+	Lines []string `json:"lines"`
+}
+
+// Limits on the texts Lines compares, which bound the time and memory a diff
+// takes. The cost grows with the number of lines times the number of changes.
+const (
+	MaxBytes = 1 << 20 // Combined size of both texts
+	MaxLines = 10000   // Combined number of lines after splitting
+)
+
+// ErrTooLarge is returned by Lines when the texts exceed MaxBytes or MaxLines
+var ErrTooLarge = errors.New("texts are too large to compare")
+
+// Lines computes a line-by-line diff turning a into b.
+// HTML content is split after each closing block tag as well as on newlines,
+// so posts saved by the editor on a single line still produce a readable diff.
+func Lines(a, b string) ([]Chunk, error) {
+	if len(a)+len(b) > MaxBytes {
+		return nil, ErrTooLarge
+	}
+	aLines, bLines := splitLines(a), splitLines(b)
+	if len(aLines)+len(bLines) > MaxLines {
+		return nil, ErrTooLarge
+	}
+	return Compute(aLines, bLines), nil
+}
+
+// Compute returns the shortest edit script turning a into b using the
+// linear-space variant of Myers' algorithm, which finds the middle of the
+// edit path and recurses on both halves instead of keeping every step
+func Compute(a, b []string) []Chunk {
+	if len(a)+len(b) == 0 {
+		return nil
+	}
+
+	// Compare lines as numbers, so equal lines are only hashed once
+	ids := make(map[string]int)
+	intern := func(lines []string) []int {
+		out := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+
+	// Each search needs diagonals -D-1 to D+1, and D is at most half of n+m
+	size := (len(a)+len(b)+1)/2 + 2
+	d := &differ{
+		a: a, b: b,
+		ai: intern(a), bi: intern(b),
+		offset: size,
+		vf:     make([]int, 2*size+1),
+		vb:     make([]int, 2*size+1),
+	}
+	d.compare(0, len(a), 0, len(b))
+	return d.chunks
+}
+
+// differ holds the state of one Compute call. vf and vb hold, per diagonal,
+// the furthest x reached by the forward and backward searches.
+type differ struct {
+	a, b   []string
+	ai, bi []int
+	offset int
+	vf, vb []int
+	chunks []Chunk
+}
+
+// compare appends the edit script turning a[a0:a1] into b[b0:b1]
+func (d *differ) compare(a0, a1, b0, b1 int) {
+	// Lines shared at both ends are unchanged
+	for a0 < a1 && b0 < b1 && d.ai[a0] == d.bi[b0] {
+		d.emit(Equal, d.a[a0])
+		a0++
+		b0++
+	}
+	suffix := 0
+	for a1 > a0 && b1 > b0 && d.ai[a1-1] == d.bi[b1-1] {
+		a1--
+		b1--
+		suffix++
+	}
+
+	switch {
+	case a0 == a1:
+		for y := b0; y < b1; y++ {
+			d.emit(Insert, d.b[y])
+		}
+	case b0 == b1:
+		for x := a0; x < a1; x++ {
+			d.emit(Delete, d.a[x])
+		}
+	default:
+		// Split around the middle snake, which is part of a shortest path
+		x, y, u, v := d.middleSnake(a0, a1, b0, b1)
+		d.compare(a0, x, b0, y)
+		for ; x < u; x++ {
+			d.emit(Equal, d.a[x])
+		}
+		d.compare(u, a1, v, b1)
+	}
+
+	for i := a1; i < a1+suffix; i++ {
+		d.emit(Equal, d.a[i])
+	}
+}
+
+// middleSnake runs the forward and backward searches on a[a0:a1] and b[b0:b1]
+// until they overlap, and returns the start (x, y) and end (u, v) of the run of
+// equal lines where they meet
+func (d *differ) middleSnake(a0, a1, b0, b1 int) (x, y, u, v int) {
+	n, m := a1-a0, b1-b0
+	delta := n - m
+	odd := delta&1 != 0
+	vf, vb, off := d.vf, d.vb, d.offset
+	vf[off+1] = 0
+	vb[off+1] = 0
+
+	for D := 0; D <= (n+m+1)/2; D++ {
+		// Forward search from the top left corner
+		for k := -D; k <= D; k += 2 {
+			var x int
+			if k == -D || (k != D && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			} else {
+				x = vf[off+k-1] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < m && d.ai[a0+x] == d.bi[b0+y] {
+				x++
+				y++
+			}
+			vf[off+k] = x
+			if odd && k >= delta-(D-1) && k <= delta+(D-1) && x+vb[off+delta-k] >= n {
+				return a0 + sx, b0 + sy, a0 + x, b0 + y
+			}
+		}
+
+		// Backward search from the bottom right corner, with x and y
+		// counted from the end
+		for k := -D; k <= D; k += 2 {
+			var x int
+			if k == -D || (k != D && vb[off+k-1] < vb[off+k+1]) {
+				x = vb[off+k+1]
+			} else {
+				x = vb[off+k-1] + 1
+			}
+			y := x - k
+			sx, sy := x, y
+			for x < n && y < m && d.ai[a1-1-x] == d.bi[b1-1-y] {
+				x++
+				y++
+			}
+			vb[off+k] = x
+			if !odd && delta-k >= -D && delta-k <= D && x+vf[off+delta-k] >= n {
+				return a1 - x, b1 - y, a1 - sx, b1 - sy
+			}
+		}
+	}
+
+	// The searches always meet by the time half of n+m edits are made
+	panic("diff: middle snake not found")
+}
+
+// emit appends a line to the edit script, grouping consecutive operations into chunks
+func (d *differ) emit(op Op, line string) {
+	if n := len(d.chunks); n > 0 && d.chunks[n-1].Op == op {
+		d.chunks[n-1].Lines = append(d.chunks[n-1].Lines, line)
+		return
+	}
+	d.chunks = append(d.chunks, Chunk{Op: op, Lines: []string{line}})
+}
+
+// Unified renders chunks in a unified-diff-like text form, prefixing each
+// line with " ", "+" or "-"
+func Unified(chunks []Chunk) string {
+	var b strings.Builder
+	for _, chunk := range chunks {
+		prefix := " "
+		switch chunk.Op {
+		case Insert:
+			prefix = "+"
+		case Delete:
+			prefix = "-"
+		}
+		for _, line := range chunk.Lines {
+			b.WriteString(prefix)
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// blockEnds are closing tags after which HTML content is split into a new line
+var blockEnds = []string{"</p>", "</h1>", "</h2>", "</h3>", "</h4>", "</h5>", "</h6>", "</li>", "</ul>", "</ol>", "</blockquote>", "</pre>", "</div>", "<br>", "<br/>", "<br />"}
+
+// splitLines splits text into lines, also breaking after HTML block elements
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+
+	for _, tag := range blockEnds {
+		s = strings.ReplaceAll(s, tag, tag+"\n")
+	}
+
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,153 @@
+// backend/internal/diff/diff_test.go
+package diff
+
+import (
+	"errors"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Chunk
+	}{
+		{
+			name: "both empty",
+			want: nil,
+		},
+		{
+			name: "unchanged",
+			a:    "one\ntwo",
+			b:    "one\ntwo",
+			want: []Chunk{{Equal, []string{"one", "two"}}},
+		},
+		{
+			name: "line added",
+			a:    "one\nthree",
+			b:    "one\ntwo\nthree",
+			want: []Chunk{{Equal, []string{"one"}}, {Insert, []string{"two"}}, {Equal, []string{"three"}}},
+		},
+		{
+			name: "line removed",
+			a:    "one\ntwo\nthree",
+			b:    "one\nthree",
+			want: []Chunk{{Equal, []string{"one"}}, {Delete, []string{"two"}}, {Equal, []string{"three"}}},
+		},
+		{
+			name: "everything replaced",
+			a:    "old",
+			b:    "new",
+			want: []Chunk{{Delete, []string{"old"}}, {Insert, []string{"new"}}},
+		},
+		{
+			name: "HTML split after block elements",
+			a:    "<p>One</p><p>Two</p>",
+			b:    "<p>One</p><p>2</p>",
+			want: []Chunk{{Equal, []string{"<p>One</p>"}}, {Delete, []string{"<p>Two</p>"}}, {Insert, []string{"<p>2</p>"}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Lines(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("Lines() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinesTooLarge(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"too many bytes", strings.Repeat("x", MaxBytes), "y"},
+		{"too many lines", strings.Repeat("x\n", MaxLines/2+1), strings.Repeat("y\n", MaxLines/2)},
+	}
+	for _, tt := range tests {
+		if _, err := Lines(tt.a, tt.b); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("%s: Lines() error = %v, want %v", tt.name, err, ErrTooLarge)
+		}
+	}
+}
+
+func TestComputeIsShortest(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	words := []string{"a", "b", "c", "d"}
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = words[rng.Intn(len(words))]
+		}
+		return lines
+	}
+
+	for i := 0; i < 2000; i++ {
+		a, b := randomLines(), randomLines()
+		chunks := Compute(a, b)
+
+		// The script must turn a into b
+		var fromA, toB []string
+		changes := 0
+		for j, chunk := range chunks {
+			if j > 0 && chunks[j-1].Op == chunk.Op {
+				t.Fatalf("consecutive %s chunks are not merged", chunk.Op)
+			}
+			switch chunk.Op {
+			case Equal:
+				fromA = append(fromA, chunk.Lines...)
+				toB = append(toB, chunk.Lines...)
+			case Delete:
+				fromA = append(fromA, chunk.Lines...)
+				changes += len(chunk.Lines)
+			case Insert:
+				toB = append(toB, chunk.Lines...)
+				changes += len(chunk.Lines)
+			}
+		}
+		if strings.Join(fromA, "\n") != strings.Join(a, "\n") || strings.Join(toB, "\n") != strings.Join(b, "\n") {
+			t.Fatalf("Compute(%q, %q) = %v does not turn a into b", a, b, chunks)
+		}
+
+		// And be as short as the longest common subsequence allows
+		if want := len(a) + len(b) - 2*lcs(a, b); changes != want {
+			t.Fatalf("Compute(%q, %q) makes %d changes, want %d", a, b, changes, want)
+		}
+	}
+}
+
+// lcs returns the length of the longest common subsequence of a and b
+func lcs(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	for i := range a {
+		cur := make([]int, len(b)+1)
+		for j := range b {
+			switch {
+			case a[i] == b[j]:
+				cur[j+1] = prev[j] + 1
+			case prev[j+1] > cur[j]:
+				cur[j+1] = prev[j+1]
+			default:
+				cur[j+1] = cur[j]
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func TestUnified(t *testing.T) {
+	chunks := []Chunk{{Equal, []string{"same"}}, {Delete, []string{"old"}}, {Insert, []string{"new"}}}
+	want := " same\n-old\n+new\n"
+	if got := Unified(chunks); got != want {
+		t.Errorf("Unified() = %q, want %q", got, want)
+	}
+}
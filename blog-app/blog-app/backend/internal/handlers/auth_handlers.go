@@ -2,9 +2,10 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"time"
 
 	"blog-app/internal/auth"
 	"blog-app/internal/models"
@@ -23,14 +24,21 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+// RefreshRequest represents the request body for refreshing or logging out a session
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 // AuthResponse represents the response body for authentication
 type AuthResponse struct {
-	Token string           `json:"token"`
-	User  *models.UserResponse `json:"user"`
+	Token        string               `json:"token"`         // Short-lived access token
+	ExpiresAt    time.Time            `json:"expires_at"`    // When the access token expires
+	RefreshToken string               `json:"refresh_token"` // Single-use token for POST /api/auth/refresh
+	User         *models.UserResponse `json:"user"`
 }
 
 // RegisterHandler handles user registration
-func RegisterHandler(db *sql.DB) http.HandlerFunc {
+func RegisterHandler(store models.Store, cfg auth.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Parse the request body
 		var req RegisterRequest
@@ -46,30 +54,27 @@ func RegisterHandler(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Create the user
-		user, err := models.CreateUser(db, req.Username, req.Email, req.Password)
+		user, err := store.CreateUser(req.Username, req.Email, req.Password)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
-		// Generate a token
-		token, err := auth.GenerateToken(user.ID)
+		// Start a session
+		resp, err := issueTokens(store, cfg, user, "")
 		if err != nil {
 			http.Error(w, "Failed to generate token", http.StatusInternalServerError)
 			return
 		}
 
-		// Respond with the token and user
+		// Respond with the tokens and user
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(AuthResponse{
-			Token: token,
-			User:  user.ToResponse(),
-		})
+		json.NewEncoder(w).Encode(resp)
 	}
 }
 
 // LoginHandler handles user login
-func LoginHandler(db *sql.DB) http.HandlerFunc {
+func LoginHandler(store models.Store, cfg auth.Config) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Parse the request body
 		var req LoginRequest
@@ -85,7 +90,7 @@ func LoginHandler(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Get the user by email
-		user, err := models.GetUserByEmail(db, req.Email)
+		user, err := store.GetUserByEmail(req.Email)
 		if err != nil {
 			http.Error(w, "Invalid email or password", http.StatusUnauthorized)
 			return
@@ -97,18 +102,138 @@ func LoginHandler(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		// Generate a token
-		token, err := auth.GenerateToken(user.ID)
+		// Start a session
+		resp, err := issueTokens(store, cfg, user, "")
 		if err != nil {
 			http.Error(w, "Failed to generate token", http.StatusInternalServerError)
 			return
 		}
 
-		// Respond with the token and user
+		// Respond with the tokens and user
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(AuthResponse{
-			Token: token,
-			User:  user.ToResponse(),
-		})
+		json.NewEncoder(w).Encode(resp)
 	}
-}
\ No newline at end of file
+}
+
+// RefreshHandler exchanges a refresh token for a new access token and a new
+// refresh token. Each refresh token works once; replaying one logs out its session.
+func RefreshHandler(store models.Store, cfg auth.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse the request body
+		var req RefreshRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+			http.Error(w, "Refresh token is required", http.StatusBadRequest)
+			return
+		}
+
+		// Use up the refresh token
+		old, err := store.UseRefreshToken(auth.HashToken(req.RefreshToken))
+		if err != nil {
+			if errors.Is(err, models.ErrRefreshTokenInvalid) || errors.Is(err, models.ErrRefreshTokenReused) {
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+			http.Error(w, "Failed to refresh token", http.StatusInternalServerError)
+			return
+		}
+
+		// Continue the session with the user's current role
+		user, err := store.GetUserByID(old.UserID)
+		if err != nil {
+			http.Error(w, models.ErrRefreshTokenInvalid.Error(), http.StatusUnauthorized)
+			return
+		}
+		resp, err := issueTokens(store, cfg, user, old.FamilyID)
+		if err != nil {
+			http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+			return
+		}
+
+		// Respond with the tokens and user
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// LogoutHandler ends a session: the access token used for the request is
+// revoked, and so is the refresh token in the body, if one is sent
+func LogoutHandler(store models.TokenStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID and token claims from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		claims, ok := r.Context().Value(auth.ClaimsKey).(*auth.Claims)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Parse the optional request body
+		var req RefreshRequest
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+		}
+
+		// Revoke the refresh token's session, then the access token
+		if req.RefreshToken != "" {
+			err := store.RevokeRefreshToken(auth.HashToken(req.RefreshToken), userID)
+			if err != nil && !errors.Is(err, models.ErrRefreshTokenInvalid) {
+				http.Error(w, "Failed to log out", http.StatusInternalServerError)
+				return
+			}
+		}
+		if err := store.RevokeToken(claims.ID, claims.ExpiresAt.Time); err != nil {
+			http.Error(w, "Failed to log out", http.StatusInternalServerError)
+			return
+		}
+
+		// Respond with success
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// JWKSHandler serves the public keys access tokens are verified with, so that
+// other services can check tokens without sharing a secret
+func JWKSHandler(keys *auth.KeyRing) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		json.NewEncoder(w).Encode(keys.JWKS())
+	}
+}
+
+// issueTokens creates an access token and a refresh token for the user. The
+// refresh token continues the given family, or starts a new one when it is empty.
+func issueTokens(store models.TokenStore, cfg auth.Config, user *models.User, familyID string) (*AuthResponse, error) {
+	token, err := cfg.Keys.GenerateToken(user.ID, string(user.Role), cfg.AccessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	if familyID == "" {
+		if familyID, err = auth.NewID(); err != nil {
+			return nil, err
+		}
+	}
+	refreshToken, hash, err := auth.NewRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err := store.CreateRefreshToken(user.ID, familyID, hash, now.Add(cfg.RefreshTTL)); err != nil {
+		return nil, err
+	}
+
+	return &AuthResponse{
+		Token:        token,
+		ExpiresAt:    now.Add(cfg.AccessTTL),
+		RefreshToken: refreshToken,
+		User:         user.ToResponse(),
+	}, nil
+}
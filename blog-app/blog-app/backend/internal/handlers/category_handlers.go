@@ -0,0 +1,215 @@
+// backend/internal/handlers/category_handlers.go
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"blog-app/internal/models"
+)
+
+// CategoryRequest represents the request body for creating or renaming a category
+type CategoryRequest struct {
+	Name     string `json:"name"`
+	ParentID *int   `json:"parent_id,omitempty"` // Optional, only used on create; omit for a top-level category
+}
+
+// MoveCategoryRequest represents the request body for moving a category
+type MoveCategoryRequest struct {
+	ParentID *int `json:"parent_id"`          // New parent, null or omitted for the top level
+	Position *int `json:"position,omitempty"` // 0-based position among the new siblings, defaults to last
+}
+
+// GetCategoriesHandler returns the whole category tree
+func GetCategoriesHandler(store models.CategoryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		categories, err := store.GetCategories()
+		if err != nil {
+			http.Error(w, "Failed to get categories", http.StatusInternalServerError)
+			return
+		}
+
+		// Respond with the tree
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.BuildCategoryTree(categories))
+	}
+}
+
+// GetCategoryHandler returns a single category
+func GetCategoryHandler(store models.CategoryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := categoryID(w, r)
+		if !ok {
+			return
+		}
+
+		category, err := store.GetCategory(id)
+		if err != nil {
+			categoryError(w, err)
+			return
+		}
+
+		// Respond with the category
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(category)
+	}
+}
+
+// CreateCategoryHandler creates a new category
+func CreateCategoryHandler(store models.CategoryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Parse the request body
+		var req CategoryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Validate the request
+		req.Name = strings.TrimSpace(req.Name)
+		if req.Name == "" || len(req.Name) > 100 {
+			http.Error(w, "Name is required and must be at most 100 characters", http.StatusBadRequest)
+			return
+		}
+
+		// Create the category
+		category, err := store.CreateCategory(req.Name, topLevel(req.ParentID))
+		if err != nil {
+			categoryError(w, err)
+			return
+		}
+
+		// Respond with the category
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(category)
+	}
+}
+
+// UpdateCategoryHandler renames a category
+func UpdateCategoryHandler(store models.CategoryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := categoryID(w, r)
+		if !ok {
+			return
+		}
+
+		// Parse the request body
+		var req CategoryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Validate the request
+		req.Name = strings.TrimSpace(req.Name)
+		if req.Name == "" || len(req.Name) > 100 {
+			http.Error(w, "Name is required and must be at most 100 characters", http.StatusBadRequest)
+			return
+		}
+
+		// Rename the category
+		category, err := store.UpdateCategory(id, req.Name)
+		if err != nil {
+			categoryError(w, err)
+			return
+		}
+
+		// Respond with the updated category
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(category)
+	}
+}
+
+// MoveCategoryHandler moves a category to a new parent and/or position
+func MoveCategoryHandler(store models.CategoryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := categoryID(w, r)
+		if !ok {
+			return
+		}
+
+		// Parse the request body
+		var req MoveCategoryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		position := -1
+		if req.Position != nil {
+			if *req.Position < 0 {
+				http.Error(w, "Position must not be negative", http.StatusBadRequest)
+				return
+			}
+			position = *req.Position
+		}
+
+		// Move the category
+		category, err := store.MoveCategory(id, topLevel(req.ParentID), position)
+		if err != nil {
+			categoryError(w, err)
+			return
+		}
+
+		// Respond with the moved category
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(category)
+	}
+}
+
+// DeleteCategoryHandler deletes a category that has no subcategories
+func DeleteCategoryHandler(store models.CategoryStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := categoryID(w, r)
+		if !ok {
+			return
+		}
+
+		if err := store.DeleteCategory(id); err != nil {
+			categoryError(w, err)
+			return
+		}
+
+		// Respond with success
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// categoryID parses the category ID from the URL,
+// writing an error response and returning false if it is invalid
+func categoryID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid category ID", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// topLevel treats a parent ID of 0 the same as no parent
+func topLevel(parentID *int) *int {
+	if parentID != nil && *parentID == 0 {
+		return nil
+	}
+	return parentID
+}
+
+// categoryError writes the response for an error returned by a category store
+func categoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrCategoryNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrCategoryCycle):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, models.ErrCategoryHasChildren):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
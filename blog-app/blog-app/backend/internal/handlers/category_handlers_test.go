@@ -0,0 +1,73 @@
+// backend/internal/handlers/category_handlers_test.go
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"blog-app/internal/auth"
+	"blog-app/internal/middleware"
+	"blog-app/internal/models"
+)
+
+// withUser returns the request as AuthMiddleware passes it on for a user with the given role
+func withUser(r *http.Request, userID int, role models.Role) *http.Request {
+	ctx := context.WithValue(r.Context(), auth.UserIDKey, userID)
+	ctx = context.WithValue(ctx, auth.RoleKey, role)
+	return r.WithContext(ctx)
+}
+
+func TestCategoryWritesNeedPermission(t *testing.T) {
+	store := models.NewMemoryStore()
+	existing, err := store.CreateCategory("Existing", nil)
+	if err != nil {
+		t.Fatalf("failed to create category: %v", err)
+	}
+
+	// The category write routes, as the API serves them
+	manageCategories := middleware.RequirePermission(models.PermManageCategories)
+	router := mux.NewRouter()
+	router.Handle("/categories", manageCategories(CreateCategoryHandler(store))).Methods("POST")
+	router.Handle("/categories/{id}", manageCategories(UpdateCategoryHandler(store))).Methods("PUT")
+	router.Handle("/categories/{id}", manageCategories(DeleteCategoryHandler(store))).Methods("DELETE")
+	router.Handle("/categories/{id}/move", manageCategories(MoveCategoryHandler(store))).Methods("POST")
+
+	tests := []struct {
+		method, path, body string
+	}{
+		{"POST", "/categories", `{"name": "Go"}`},
+		{"PUT", "/categories/{id}", `{"name": "Renamed"}`},
+		{"POST", "/categories/{id}/move", `{"parent_id": null}`},
+		{"DELETE", "/categories/{id}", ""},
+	}
+	for _, tt := range tests {
+		path := strings.Replace(tt.path, "{id}", strconv.Itoa(existing.ID), 1)
+
+		// Readers and authors are turned away before the handler runs
+		for _, role := range []models.Role{models.RoleReader, models.RoleAuthor} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, withUser(httptest.NewRequest(tt.method, path, strings.NewReader(tt.body)), 2, role))
+			if w.Code != http.StatusForbidden {
+				t.Errorf("%s %s as %s: status = %d, want %d", tt.method, tt.path, role, w.Code, http.StatusForbidden)
+			}
+		}
+
+		// Editors get through
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, withUser(httptest.NewRequest(tt.method, path, strings.NewReader(tt.body)), 3, models.RoleEditor))
+		if w.Code >= 300 {
+			t.Errorf("%s %s as editor: status = %d: %s", tt.method, tt.path, w.Code, w.Body)
+		}
+	}
+
+	// The category is gone only after the editor's delete
+	if _, err := store.GetCategory(existing.ID); err == nil {
+		t.Errorf("category %d still exists after the editor deleted it", existing.ID)
+	}
+}
@@ -0,0 +1,368 @@
+// backend/internal/handlers/comment_handlers.go
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"blog-app/internal/auth"
+	"blog-app/internal/models"
+	"blog-app/internal/moderation"
+)
+
+// CommentRequest represents the request body for creating or editing a comment
+type CommentRequest struct {
+	Content  string `json:"content"`
+	ParentID *int   `json:"parent_id,omitempty"` // Optional, only used on create to reply to a comment
+}
+
+// CommentPage is a page of comments: top-level comments with their replies
+// when reading a post, or a flat list in the moderation queue
+type CommentPage struct {
+	Comments   []*models.Comment `json:"comments"`
+	Page       int               `json:"page"`
+	PerPage    int               `json:"per_page"`
+	Total      int               `json:"total"` // Number of top-level comments when reading a post
+	TotalPages int               `json:"total_pages"`
+}
+
+// GetCommentsHandler returns a page of comment threads on a post.
+// The "page" and "per_page" query parameters select the page of top-level comments.
+// Readers see approved comments and their own; moderators of the post see them all.
+func GetCommentsHandler(store models.Store, policy *moderation.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Get the ID from the URL
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, "Invalid post ID", http.StatusBadRequest)
+			return
+		}
+
+		// Comments on unpublished posts are only visible to their author
+		post := findVisiblePost(w, r, store, id)
+		if post == nil {
+			return
+		}
+
+		page, perPage, ok := pageParams(w, r)
+		if !ok {
+			return
+		}
+
+		// Get the comments
+		comments, total, err := store.GetComments(id, userID, canModerate(store, policy, userID, post), page, perPage)
+		if err != nil {
+			http.Error(w, "Failed to get comments", http.StatusInternalServerError)
+			return
+		}
+
+		// Respond with the page
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(newCommentPage(comments, page, perPage, total))
+	}
+}
+
+// CreateCommentHandler adds a comment, or a reply to one, on a post.
+// The comment waits in the moderation queue, or in the spam folder if the scorer
+// flags it, unless its author moderates the post.
+func CreateCommentHandler(store models.Store, scorer moderation.Scorer, policy *moderation.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Get the ID from the URL
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, "Invalid post ID", http.StatusBadRequest)
+			return
+		}
+
+		// Only posts the user can see can be commented on
+		post := findVisiblePost(w, r, store, id)
+		if post == nil {
+			return
+		}
+
+		// Parse the request body
+		var req CommentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Score the comment and create it
+		input := moderatedInput(store, scorer, policy, userID, post, req.Content)
+		input.ParentID = req.ParentID
+		comment, err := store.CreateComment(id, input, userID)
+		if err != nil {
+			commentError(w, err)
+			return
+		}
+
+		// Respond with the comment
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(comment)
+	}
+}
+
+// UpdateCommentHandler edits a comment, which then goes through moderation again
+func UpdateCommentHandler(store models.Store, scorer moderation.Scorer, policy *moderation.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Get the ID from the URL
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+			return
+		}
+
+		// Parse the request body
+		var req CommentRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Find the post the comment belongs to, which must still be visible
+		existing, err := store.GetComment(id)
+		if err != nil {
+			commentError(w, err)
+			return
+		}
+		post := findVisiblePost(w, r, store, existing.PostID)
+		if post == nil {
+			return
+		}
+
+		// Only the author can edit their comment; check before scoring, so that
+		// edits that are turned away do not count towards the rate limit
+		if existing.AuthorID != userID {
+			commentError(w, fmt.Errorf("%w: you can only edit your own comments", models.ErrNotAuthor))
+			return
+		}
+		if existing.Deleted {
+			commentError(w, models.ErrCommentDeleted)
+			return
+		}
+
+		// Score the new content and update the comment
+		input := moderatedInput(store, scorer, policy, userID, post, req.Content)
+		comment, err := store.UpdateComment(id, input, userID)
+		if err != nil {
+			commentError(w, err)
+			return
+		}
+
+		// Respond with the updated comment
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(comment)
+	}
+}
+
+// DeleteCommentHandler deletes a comment
+func DeleteCommentHandler(store models.CommentStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Get the ID from the URL
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+			return
+		}
+
+		// Delete the comment
+		if err := store.DeleteComment(id, userID); err != nil {
+			commentError(w, err)
+			return
+		}
+
+		// Respond with success
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetModerationQueueHandler returns the comments waiting for the current user's decision.
+// The "status" query parameter selects the queue (pending by default, or spam,
+// rejected and approved). Editors and admins see every post's comments.
+func GetModerationQueueHandler(store models.Store, policy *moderation.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		user, err := store.GetUserByID(userID)
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		status := models.CommentPending
+		if v := r.URL.Query().Get("status"); v != "" {
+			status = models.CommentStatus(v)
+			if !status.Valid() {
+				http.Error(w, "Invalid status", http.StatusBadRequest)
+				return
+			}
+		}
+
+		page, perPage, ok := pageParams(w, r)
+		if !ok {
+			return
+		}
+
+		// Authors only see comments on their own posts
+		postAuthorID := userID
+		if policy.ModeratesAll(user) {
+			postAuthorID = 0
+		}
+
+		comments, total, err := store.GetModerationQueue(status, postAuthorID, page, perPage)
+		if err != nil {
+			http.Error(w, "Failed to get comments", http.StatusInternalServerError)
+			return
+		}
+
+		// Respond with the page
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(newCommentPage(comments, page, perPage, total))
+	}
+}
+
+// ModerateCommentHandler approves, rejects or marks a comment as spam.
+// Only the author of the post, editors and admins may moderate a comment.
+func ModerateCommentHandler(store models.Store, policy *moderation.Policy, status models.CommentStatus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Get the ID from the URL
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, "Invalid comment ID", http.StatusBadRequest)
+			return
+		}
+
+		// Check that the user moderates the post the comment belongs to
+		comment, err := store.GetComment(id)
+		if err != nil {
+			commentError(w, err)
+			return
+		}
+		post, err := store.GetPostByID(comment.PostID)
+		if err != nil {
+			http.Error(w, "Failed to get post", http.StatusInternalServerError)
+			return
+		}
+		if !canModerate(store, policy, userID, post) {
+			http.Error(w, "You can only moderate comments on your own posts", http.StatusForbidden)
+			return
+		}
+
+		// Record the decision
+		comment, err = store.ModerateComment(id, status, userID)
+		if err != nil {
+			commentError(w, err)
+			return
+		}
+
+		// Respond with the moderated comment
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(comment)
+	}
+}
+
+// canModerate reports whether the user moderates comments on the post
+func canModerate(store models.UserStore, policy *moderation.Policy, userID int, post *models.Post) bool {
+	user, err := store.GetUserByID(userID)
+	if err != nil {
+		return false
+	}
+	return policy.CanModerate(user, post)
+}
+
+// moderatedInput scores a new or edited comment and picks its moderation status.
+// Comments by moderators of the post are approved straight away.
+func moderatedInput(store models.UserStore, scorer moderation.Scorer, policy *moderation.Policy, userID int, post *models.Post, content string) models.CommentInput {
+	input := models.CommentInput{Content: content, Status: models.CommentApproved}
+	if canModerate(store, policy, userID, post) {
+		return input
+	}
+
+	result := scorer.Score(moderation.Candidate{PostID: post.ID, AuthorID: userID, Content: content})
+	input.SpamScore = result.Score
+	input.Status = models.CommentPending
+	if result.IsSpam() {
+		input.Status = models.CommentSpam
+	}
+	return input
+}
+
+// newCommentPage wraps a page of comments with its pagination details
+func newCommentPage(comments []*models.Comment, page, perPage, total int) CommentPage {
+	return CommentPage{
+		Comments:   comments,
+		Page:       page,
+		PerPage:    perPage,
+		Total:      total,
+		TotalPages: totalPages(total, perPage),
+	}
+}
+
+// commentError writes the response for an error returned by a comment store write
+func commentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrCommentNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrNotAuthor):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, models.ErrCommentEmpty),
+		errors.Is(err, models.ErrCommentTooLong),
+		errors.Is(err, models.ErrInvalidParent),
+		errors.Is(err, models.ErrInvalidModeration):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, models.ErrCommentDeleted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,77 @@
+// backend/internal/handlers/comment_handlers_test.go
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"blog-app/internal/models"
+	"blog-app/internal/moderation"
+)
+
+// countingScorer counts the comments it scores and never flags one
+type countingScorer struct {
+	scored int
+}
+
+// Score implements moderation.Scorer
+func (s *countingScorer) Score(c moderation.Candidate) moderation.Result {
+	s.scored++
+	return moderation.Result{}
+}
+
+func TestUpdateCommentChecksAuthorBeforeScoring(t *testing.T) {
+	store := models.NewMemoryStore()
+	alice, err := store.CreateUser("alice", "alice@example.com", "password123")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	bob, err := store.CreateUser("bob", "bob@example.com", "password123")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	carol, err := store.CreateUser("carol", "carol@example.com", "password123")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	post, err := store.CreatePost(models.PostInput{Title: "Post", Content: "Content", Status: models.StatusPublished}, alice.ID)
+	if err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+	comment, err := store.CreateComment(post.ID, models.CommentInput{Content: "First", Status: models.CommentApproved}, bob.ID)
+	if err != nil {
+		t.Fatalf("failed to create comment: %v", err)
+	}
+
+	scorer := &countingScorer{}
+	router := mux.NewRouter()
+	router.HandleFunc("/comments/{id}", UpdateCommentHandler(store, scorer, moderation.NewPolicy())).Methods("PUT")
+	path := "/comments/" + strconv.Itoa(comment.ID)
+	edit := func(user *models.User) int {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("PUT", path, strings.NewReader(`{"content": "Edited"}`))
+		router.ServeHTTP(w, withUser(r, user.ID, user.Role))
+		return w.Code
+	}
+
+	// Someone else's edit is turned away without being scored
+	if code := edit(carol); code != http.StatusForbidden {
+		t.Errorf("edit by carol: status = %d, want %d", code, http.StatusForbidden)
+	}
+	if scorer.scored != 0 {
+		t.Errorf("scored %d comments after a refused edit, want 0", scorer.scored)
+	}
+
+	// The author's edit is scored once
+	if code := edit(bob); code != http.StatusOK {
+		t.Errorf("edit by bob: status = %d, want %d", code, http.StatusOK)
+	}
+	if scorer.scored != 1 {
+		t.Errorf("scored %d comments after bob's edit, want 1", scorer.scored)
+	}
+}
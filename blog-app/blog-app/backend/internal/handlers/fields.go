@@ -0,0 +1,90 @@
+// backend/internal/handlers/fields.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"blog-app/internal/models"
+)
+
+// PostView is a post as returned by listing endpoints: its summary, or the
+// fields picked with the "fields" query parameter
+type PostView struct {
+	summary *models.PostSummary
+	fields  map[string]bool // nil for the summary
+}
+
+// MarshalJSON implements json.Marshaler
+func (v PostView) MarshalJSON() ([]byte, error) {
+	if v.fields == nil {
+		return json.Marshal(v.summary)
+	}
+
+	view := summaryFields(v.summary)
+	if v.fields["content"] {
+		view["content"], _ = json.Marshal(v.summary.Content)
+	}
+	for name := range view {
+		if !v.fields[name] {
+			delete(view, name)
+		}
+	}
+	return json.Marshal(view)
+}
+
+// postFieldNames holds every field a listing can return: the summary fields plus "content"
+var postFieldNames = func() map[string]bool {
+	names := map[string]bool{"content": true}
+	for name := range summaryFields(&models.PostSummary{}) {
+		names[name] = true
+	}
+	return names
+}()
+
+// postFields parses the "fields" query parameter, a comma-separated list of
+// post fields such as "id,title,content". It returns nil when the parameter is
+// absent, writing an error response and returning false if a field is unknown.
+func postFields(w http.ResponseWriter, r *http.Request) (map[string]bool, bool) {
+	v := r.URL.Query().Get("fields")
+	if v == "" {
+		return nil, true
+	}
+
+	fields := map[string]bool{"id": true}
+	for _, name := range strings.Split(v, ",") {
+		name = strings.TrimSpace(name)
+		if !postFieldNames[name] {
+			known := make([]string, 0, len(postFieldNames))
+			for n := range postFieldNames {
+				known = append(known, n)
+			}
+			sort.Strings(known)
+			http.Error(w, "Unknown field "+name+"; fields are "+strings.Join(known, ", "), http.StatusBadRequest)
+			return nil, false
+		}
+		fields[name] = true
+	}
+	return fields, true
+}
+
+// viewPost returns the summary of a post, or only the given fields if fields is not nil
+func viewPost(post *models.Post, fields map[string]bool) PostView {
+	return viewSummary(models.Summarize(post), fields)
+}
+
+// viewSummary returns a post summary, or only the given fields if fields is
+// not nil. The summary must include the content if fields asks for it.
+func viewSummary(summary *models.PostSummary, fields map[string]bool) PostView {
+	return PostView{summary: summary, fields: fields}
+}
+
+// summaryFields encodes a post summary field by field
+func summaryFields(summary *models.PostSummary) map[string]json.RawMessage {
+	data, _ := json.Marshal(summary)
+	var view map[string]json.RawMessage
+	json.Unmarshal(data, &view)
+	return view
+}
@@ -0,0 +1,502 @@
+// backend/internal/handlers/media_handlers.go
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+
+	"blog-app/internal/auth"
+	"blog-app/internal/blob"
+	"blog-app/internal/media"
+	"blog-app/internal/models"
+)
+
+// maxFilenameLength is the longest uploaded file name kept, in bytes
+const maxFilenameLength = 255
+
+// MediaUploadRequest asks for a presigned URL to upload an image to directly
+type MediaUploadRequest struct {
+	ContentType string `json:"content_type"`
+}
+
+// MediaUpload is a presigned URL the client uploads an image to with a PUT
+// request, sending the listed headers, before registering it with UploadMediaHandler
+type MediaUpload struct {
+	UploadKey string            `json:"upload_key"`
+	URL       string            `json:"url"`
+	Method    string            `json:"method"`
+	Headers   map[string]string `json:"headers"`
+	ExpiresAt time.Time         `json:"expires_at"`
+}
+
+// UploadedMediaRequest registers an image uploaded to a presigned URL
+type UploadedMediaRequest struct {
+	UploadKey string `json:"upload_key"`
+	Filename  string `json:"filename,omitempty"` // Optional, name of the original file
+}
+
+// Lifetimes of presigned URLs
+const (
+	uploadURLExpiry   = 15 * time.Minute
+	downloadURLExpiry = time.Hour
+)
+
+// CreateMediaUploadHandler returns a presigned URL the current user can upload
+// an image to without going through the API, for storage that supports it.
+// The upload is only checked and processed once it is registered with
+// UploadMediaHandler.
+func CreateMediaUploadHandler(files blob.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Parse the request body
+		var req MediaUploadRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		switch req.ContentType {
+		case "image/jpeg", "image/png", "image/gif":
+		default:
+			mediaError(w, media.ErrUnsupportedType)
+			return
+		}
+
+		// Presign an upload into the user's incoming area
+		suffix, err := randomKey()
+		if err != nil {
+			http.Error(w, "Failed to create upload", http.StatusInternalServerError)
+			return
+		}
+		key := incomingPrefix(userID) + suffix
+		url, err := files.PresignPut(key, req.ContentType, uploadURLExpiry)
+		if err != nil {
+			if errors.Is(err, blob.ErrPresignUnsupported) {
+				http.Error(w, "Direct uploads are not supported by the configured storage; upload a multipart form instead", http.StatusNotImplemented)
+				return
+			}
+			http.Error(w, "Failed to create upload", http.StatusInternalServerError)
+			return
+		}
+
+		// Respond with the upload URL
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(MediaUpload{
+			UploadKey: key,
+			URL:       url,
+			Method:    http.MethodPut,
+			Headers:   map[string]string{"Content-Type": req.ContentType},
+			ExpiresAt: time.Now().Add(uploadURLExpiry).UTC(),
+		})
+	}
+}
+
+// UploadMediaHandler stores an image sent as the "file" field of a multipart
+// form, or registers one uploaded to a presigned URL when sent a JSON body with
+// its upload key. The image is checked by its content, not its declared type,
+// stripped of its metadata and resized into the variants listed in media.Variants.
+func UploadMediaHandler(store models.MediaStore, files blob.Storage, cfg media.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Read the image
+		var data []byte
+		var filename string
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "application/json") {
+			data, filename, ok = readUploadedFile(w, r, files, cfg, userID)
+		} else {
+			data, filename, ok = readMultipartFile(w, r, cfg)
+		}
+		if !ok {
+			return
+		}
+
+		// Validate the image and generate its renditions
+		renditions, err := media.Process(data)
+		if err != nil {
+			mediaError(w, err)
+			return
+		}
+
+		// Store the renditions under a random prefix, so their addresses cannot be guessed
+		prefix, err := randomKey()
+		if err != nil {
+			http.Error(w, "Failed to store file", http.StatusInternalServerError)
+			return
+		}
+		var variants []*models.MediaVariant
+		for _, rendition := range renditions {
+			key := "media/" + prefix + "/" + rendition.Name + rendition.Extension()
+			if err := files.Put(key, rendition.ContentType, rendition.Data); err != nil {
+				deleteFiles(files, variants)
+				http.Error(w, "Failed to store file", http.StatusInternalServerError)
+				return
+			}
+			variants = append(variants, &models.MediaVariant{
+				Name:        rendition.Name,
+				ContentType: rendition.ContentType,
+				Width:       rendition.Width,
+				Height:      rendition.Height,
+				Size:        int64(len(rendition.Data)),
+				StorageKey:  key,
+			})
+		}
+
+		// Record the upload
+		item, err := store.CreateMedia(userID, uploadFilename(filename), variants)
+		if err != nil {
+			deleteFiles(files, variants)
+			mediaError(w, err)
+			return
+		}
+
+		// Respond with the new media
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(withMediaURLs(item, cfg))
+	}
+}
+
+// readMultipartFile reads the "file" field of a multipart form and the name it
+// was uploaded with, writing an error response and returning false if it is missing
+// or too large
+func readMultipartFile(w http.ResponseWriter, r *http.Request, cfg media.Config) ([]byte, string, bool) {
+	// Allow some room for the rest of the form
+	r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxBytes+1<<20)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "File is too large", http.StatusRequestEntityTooLarge)
+			return nil, "", false
+		}
+		http.Error(w, "Missing file field in multipart form", http.StatusBadRequest)
+		return nil, "", false
+	}
+	defer file.Close()
+	if header.Size > cfg.MaxBytes {
+		http.Error(w, "File is too large", http.StatusRequestEntityTooLarge)
+		return nil, "", false
+	}
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		http.Error(w, "Failed to read file", http.StatusBadRequest)
+		return nil, "", false
+	}
+	return data, header.Filename, true
+}
+
+// readUploadedFile reads a file the user uploaded to a presigned URL, then
+// deletes it from the incoming area, writing an error response and returning
+// false if it is missing, too large or belongs to someone else
+func readUploadedFile(w http.ResponseWriter, r *http.Request, files blob.Storage, cfg media.Config, userID int) ([]byte, string, bool) {
+	var req UploadedMediaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return nil, "", false
+	}
+	if !strings.HasPrefix(req.UploadKey, incomingPrefix(userID)) {
+		http.Error(w, "Invalid upload key", http.StatusBadRequest)
+		return nil, "", false
+	}
+
+	f, err := files.Open(req.UploadKey)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotExist) || errors.Is(err, blob.ErrInvalidKey) {
+			http.Error(w, "Upload not found; it may not have finished or was already registered", http.StatusBadRequest)
+			return nil, "", false
+		}
+		http.Error(w, "Failed to read upload", http.StatusInternalServerError)
+		return nil, "", false
+	}
+	data, err := io.ReadAll(io.LimitReader(f, cfg.MaxBytes+1))
+	f.Close()
+	if err != nil {
+		http.Error(w, "Failed to read upload", http.StatusInternalServerError)
+		return nil, "", false
+	}
+
+	// The upload is only a staging copy; processing stores new files
+	if err := files.Delete(req.UploadKey); err != nil {
+		log.Printf("Failed to delete upload %s: %v", req.UploadKey, err)
+	}
+	if int64(len(data)) > cfg.MaxBytes {
+		http.Error(w, "File is too large", http.StatusRequestEntityTooLarge)
+		return nil, "", false
+	}
+	return data, req.Filename, true
+}
+
+// incomingPrefix returns the key prefix of a user's uploads to presigned URLs
+func incomingPrefix(userID int) string {
+	return "incoming/" + strconv.Itoa(userID) + "/"
+}
+
+// GetMediaListHandler returns the media uploaded by the current user, newest first
+func GetMediaListHandler(store models.MediaStore, cfg media.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Get the media
+		items, err := store.GetMediaByOwner(userID)
+		if err != nil {
+			http.Error(w, "Failed to get media", http.StatusInternalServerError)
+			return
+		}
+		for _, item := range items {
+			withMediaURLs(item, cfg)
+		}
+
+		// Respond with the media
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(items)
+	}
+}
+
+// GetMediaHandler returns a single media item with the addresses of its renditions
+func GetMediaHandler(store models.MediaStore, cfg media.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		_, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Get the ID from the URL
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, "Invalid media ID", http.StatusBadRequest)
+			return
+		}
+
+		// Get the media
+		item, err := store.GetMedia(id)
+		if err != nil {
+			mediaError(w, err)
+			return
+		}
+
+		// Respond with the media
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(withMediaURLs(item, cfg))
+	}
+}
+
+// DeleteMediaHandler deletes a media item owned by the current user along with its files.
+// Posts still referencing it will show broken images.
+func DeleteMediaHandler(store models.MediaStore, files blob.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Get the ID from the URL
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, "Invalid media ID", http.StatusBadRequest)
+			return
+		}
+
+		// Look up the files before the record is gone
+		item, err := store.GetMedia(id)
+		if err != nil {
+			mediaError(w, err)
+			return
+		}
+
+		// Delete the media, then its files
+		if err := store.DeleteMedia(id, userID); err != nil {
+			mediaError(w, err)
+			return
+		}
+		deleteFiles(files, item.Variants)
+
+		// Respond with success
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// ServeMediaHandler serves a rendition of a media item without authentication,
+// at /media/{id} for the original or /media/{id}/{variant}. A variant that was
+// skipped because the original already fits inside it is served as the original.
+// Stored files never change, so they can be cached indefinitely. Storage that
+// presigns URLs serves the file itself, through a redirect.
+func ServeMediaHandler(store models.MediaStore, files blob.Storage) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the ID and variant from the URL
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		name := vars["variant"]
+		if name == "" {
+			name = media.Original
+		}
+
+		// Get the media
+		item, err := store.GetMedia(id)
+		if err != nil {
+			if errors.Is(err, models.ErrMediaNotFound) {
+				http.NotFound(w, r)
+				return
+			}
+			http.Error(w, "Failed to get media", http.StatusInternalServerError)
+			return
+		}
+
+		// Pick the rendition
+		variant := item.Variant(name)
+		if variant == nil && isVariant(name) {
+			variant = item.Variant(media.Original)
+		}
+		if variant == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		// Send the client to the storage when it can serve the file
+		if url, err := files.PresignGet(variant.StorageKey, downloadURLExpiry); err == nil {
+			w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(downloadURLExpiry.Seconds())/2))
+			http.Redirect(w, r, url, http.StatusFound)
+			return
+		} else if !errors.Is(err, blob.ErrPresignUnsupported) {
+			http.Error(w, "Failed to read media", http.StatusInternalServerError)
+			return
+		}
+
+		// The storage key is unique to the file, so it doubles as its ETag
+		etag := `"` + variant.StorageKey + `"`
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		// Send the file
+		f, err := files.Open(variant.StorageKey)
+		if err != nil {
+			w.Header().Del("Cache-Control")
+			w.Header().Del("ETag")
+			if errors.Is(err, blob.ErrNotExist) {
+				http.NotFound(w, r)
+				return
+			}
+			http.Error(w, "Failed to read media", http.StatusInternalServerError)
+			return
+		}
+		defer f.Close()
+
+		w.Header().Set("Content-Type", variant.ContentType)
+		w.Header().Set("Content-Length", strconv.FormatInt(variant.Size, 10))
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		io.Copy(w, f)
+	}
+}
+
+// isVariant reports whether name is one of the variants generated for uploads
+func isVariant(name string) bool {
+	for _, v := range media.Variants {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// withMediaURLs fills in the addresses of a media item and its renditions
+func withMediaURLs(item *models.Media, cfg media.Config) *models.Media {
+	item.URL = cfg.URL(item.ID, media.Original)
+	for _, v := range item.Variants {
+		v.URL = cfg.URL(item.ID, v.Name)
+	}
+	return item
+}
+
+// uploadFilename strips any directory from the name a client gave an uploaded
+// file and shortens it, keeping the extension
+func uploadFilename(name string) string {
+	name = name[strings.LastIndexAny(name, `/\`)+1:]
+	if len(name) > maxFilenameLength {
+		cut := len(name) - maxFilenameLength
+		for !utf8.RuneStart(name[cut]) {
+			cut++
+		}
+		name = name[cut:]
+	}
+	return name
+}
+
+// randomKey returns a random storage key prefix
+func randomKey() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// deleteFiles removes the stored files of renditions. Failures are only logged,
+// leaving an orphaned file rather than failing the request.
+func deleteFiles(files blob.Storage, variants []*models.MediaVariant) {
+	for _, v := range variants {
+		if err := files.Delete(v.StorageKey); err != nil {
+			log.Printf("Failed to delete media file %s: %v", v.StorageKey, err)
+		}
+	}
+}
+
+// mediaError writes the response for an error from a media operation
+func mediaError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrMediaNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrNotAuthor):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, media.ErrUnsupportedType):
+		http.Error(w, err.Error(), http.StatusUnsupportedMediaType)
+	case errors.Is(err, media.ErrInvalidImage):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, media.ErrImageTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,275 @@
+// backend/internal/handlers/member_handlers.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"blog-app/internal/access"
+	"blog-app/internal/auth"
+	"blog-app/internal/models"
+)
+
+// InviteRequest represents the request body for inviting someone to a website
+type InviteRequest struct {
+	Email string             `json:"email"` // Email address of the user to invite, registered or not
+	Role  models.WebsiteRole `json:"role"`
+}
+
+// UpdateMemberRequest represents the request body for changing a member's role
+type UpdateMemberRequest struct {
+	Role models.WebsiteRole `json:"role"`
+}
+
+// GetMembersHandler returns the members of a website with their roles
+func GetMembersHandler(store models.Store, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		website := findWebsite(w, r, store, sites, access.View)
+		if website == nil {
+			return
+		}
+
+		members, err := store.GetWebsiteMembers(website.ID)
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with the members
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(members)
+	}
+}
+
+// InviteMemberHandler invites the user with an email address to a website.
+// The response is the same whether or not anyone has registered with the
+// address, so that it cannot be used to find out who has an account.
+func InviteMemberHandler(store models.Store, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		website := findWebsite(w, r, store, sites, access.Manage)
+		if website == nil {
+			return
+		}
+
+		// Parse and validate the request body
+		var req InviteRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		req.Email = strings.TrimSpace(req.Email)
+		if req.Email == "" || len(req.Email) > 100 {
+			http.Error(w, "Email is required and must be at most 100 characters", http.StatusBadRequest)
+			return
+		}
+		if !req.Role.Valid() {
+			http.Error(w, models.ErrInvalidRole.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Store the invite
+		invite, err := store.InviteWebsiteMember(website.ID, req.Email, req.Role, userID)
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with the invite
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(invite)
+	}
+}
+
+// GetWebsiteInvitesHandler returns the pending invites of a website
+func GetWebsiteInvitesHandler(store models.Store, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		website := findWebsite(w, r, store, sites, access.Manage)
+		if website == nil {
+			return
+		}
+
+		invites, err := store.GetWebsiteInvites(website.ID)
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with the invites
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(invites)
+	}
+}
+
+// DeleteWebsiteInviteHandler withdraws a pending invite to a website
+func DeleteWebsiteInviteHandler(store models.Store, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		website := findWebsite(w, r, store, sites, access.Manage)
+		if website == nil {
+			return
+		}
+		id, ok := urlID(w, r, "invite", "Invalid invite ID")
+		if !ok {
+			return
+		}
+
+		if err := store.DeleteWebsiteInvite(website.ID, id); err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with success
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetMyInvitesHandler returns the pending invites of the current user's email address
+func GetMyInvitesHandler(store models.WebsiteInviteStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		invites, err := store.GetUserInvites(userID)
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with the invites
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(invites)
+	}
+}
+
+// AcceptInviteHandler makes the current user a member of the website they were invited to
+func AcceptInviteHandler(store models.WebsiteInviteStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		id, ok := urlID(w, r, "invite", "Invalid invite ID")
+		if !ok {
+			return
+		}
+
+		member, err := store.AcceptWebsiteInvite(id, userID)
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with the new membership
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(member)
+	}
+}
+
+// DeclineInviteHandler deletes an invite of the current user's email address
+func DeclineInviteHandler(store models.WebsiteInviteStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		id, ok := urlID(w, r, "invite", "Invalid invite ID")
+		if !ok {
+			return
+		}
+
+		if err := store.DeclineWebsiteInvite(id, userID); err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with success
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// UpdateMemberHandler changes the role of a website member
+func UpdateMemberHandler(store models.Store, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		website := findWebsite(w, r, store, sites, access.Manage)
+		if website == nil {
+			return
+		}
+		userID, ok := urlID(w, r, "user", "Invalid user ID")
+		if !ok {
+			return
+		}
+
+		// Parse and validate the request body
+		var req UpdateMemberRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if !req.Role.Valid() {
+			http.Error(w, models.ErrInvalidRole.Error(), http.StatusBadRequest)
+			return
+		}
+
+		// Update the member
+		member, err := store.UpdateWebsiteMember(website.ID, userID, req.Role)
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with the updated member
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(member)
+	}
+}
+
+// RemoveMemberHandler takes a member off a website. Owners remove anyone and
+// every member may remove themselves to leave the website.
+func RemoveMemberHandler(store models.Store, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		currentID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		userID, ok := urlID(w, r, "user", "Invalid user ID")
+		if !ok {
+			return
+		}
+
+		// Leaving only needs membership, removing someone else needs manage
+		perm := access.Manage
+		if userID == currentID {
+			perm = access.View
+		}
+		website := findWebsite(w, r, store, sites, perm)
+		if website == nil {
+			return
+		}
+
+		if err := store.RemoveWebsiteMember(website.ID, userID); err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with success
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,41 @@
+// backend/internal/handlers/pagination.go
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// Page size limits shared by every paginated endpoint
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+// pageParams parses the "page" and "per_page" query parameters,
+// writing an error response and returning false if either is invalid
+func pageParams(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	page, perPage := 1, defaultPerPage
+	if v := r.URL.Query().Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "Invalid page", http.StatusBadRequest)
+			return 0, 0, false
+		}
+		page = n
+	}
+	if v := r.URL.Query().Get("per_page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPerPage {
+			http.Error(w, "per_page must be between 1 and 100", http.StatusBadRequest)
+			return 0, 0, false
+		}
+		perPage = n
+	}
+	return page, perPage, true
+}
+
+// totalPages returns the number of pages needed for total items
+func totalPages(total, perPage int) int {
+	return (total + perPage - 1) / perPage
+}
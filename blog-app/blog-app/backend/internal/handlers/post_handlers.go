@@ -2,10 +2,11 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -15,28 +16,185 @@ import (
 
 // PostRequest represents the request body for creating or updating a post
 type PostRequest struct {
-	Title   string `json:"title"`
-	Content string `json:"content"`
+	Title           string            `json:"title"`
+	Slug            string            `json:"slug,omitempty"` // Optional, generated from the title when empty
+	Content         string            `json:"content"`
+	CategoryID      *int              `json:"category_id,omitempty"`       // Optional, 0 removes the category
+	Tags            []string          `json:"tags,omitempty"`              // Optional, replaces the post's tags when present
+	Status          models.PostStatus `json:"status,omitempty"`            // Optional, new posts default to draft
+	PublishAt       *time.Time        `json:"publish_at,omitempty"`        // Optional, schedules the post
+	FeaturedImageID *int              `json:"featured_image_id,omitempty"` // Optional media ID, 0 removes the image
+	SEO             *models.PostSEO   `json:"seo,omitempty"`               // Optional, replaces all of the post's metadata when present
 }
 
-// GetPostsHandler returns a list of all posts
-func GetPostsHandler(db *sql.DB) http.HandlerFunc {
+// validate checks the fields of a post request, returning an error to show the client
+func (req PostRequest) validate() error {
+	if req.Title == "" || req.Content == "" {
+		return errors.New("Title and content are required")
+	}
+	if req.Slug != "" && !models.ValidSlug(req.Slug) {
+		return models.ErrInvalidSlug
+	}
+	if req.Status != "" && !req.Status.Valid() {
+		return errors.New("Invalid status")
+	}
+	if req.PublishAt != nil && req.Status != "" && req.Status != models.StatusScheduled {
+		return errors.New("publish_at can only be used with the scheduled status")
+	}
+	if req.FeaturedImageID != nil && *req.FeaturedImageID < 0 {
+		return errors.New("Invalid featured image ID")
+	}
+	if req.SEO != nil {
+		if err := req.SEO.Normalize().Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// input converts the request into the fields a post store writes
+func (req PostRequest) input() models.PostInput {
+	return models.PostInput{
+		Title:           req.Title,
+		Slug:            req.Slug,
+		Content:         req.Content,
+		CategoryID:      req.CategoryID,
+		Tags:            req.Tags,
+		Status:          req.Status,
+		PublishAt:       req.PublishAt,
+		FeaturedImageID: req.FeaturedImageID,
+		SEO:             req.SEO,
+	}
+}
+
+// ScheduleRequest represents the request body for scheduling a post
+type ScheduleRequest struct {
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+// PostList is a page of a post listing
+type PostList struct {
+	Posts      []PostView `json:"posts"`
+	NextCursor string     `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// GetPostsHandler returns a page of the posts visible to the current user,
+// summarised without their content unless "fields" asks for it.
+// Query parameters:
+//   - limit: page size, 1 to 100 (default 20)
+//   - cursor: the next_cursor of the previous page
+//   - sort: "created" (default, newest first), "updated" or "title"
+//   - author: only posts by this user ID
+//   - tag: only posts with this tag
+//   - category: only posts in this category or any of its subcategories
+//   - since, until: only posts created in this RFC 3339 time range, until excluded
+//   - fields: comma-separated fields to return instead of the summary, e.g. "id,title,content"
+func GetPostsHandler(store models.PostStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		// Get all posts
-		posts, err := models.GetPosts(db)
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Parse the filter and the fields to return
+		filter, ok := postFilter(w, r)
+		if !ok {
+			return
+		}
+		fields, ok := postFields(w, r)
+		if !ok {
+			return
+		}
+		filter.Content = fields["content"]
+
+		// Get the page of posts
+		page, err := store.ListPosts(filter, viewer(r, userID))
 		if err != nil {
-			http.Error(w, "Failed to get posts", http.StatusInternalServerError)
+			switch {
+			case errors.Is(err, models.ErrInvalidCursor):
+				http.Error(w, "Invalid cursor", http.StatusBadRequest)
+			case errors.Is(err, models.ErrCategoryNotFound):
+				http.Error(w, "Category not found", http.StatusNotFound)
+			default:
+				http.Error(w, "Failed to get posts", http.StatusInternalServerError)
+			}
 			return
 		}
 
-		// Respond with the posts
+		// Respond with the page
+		list := PostList{Posts: make([]PostView, len(page.Posts)), NextCursor: page.NextCursor}
+		for i, post := range page.Posts {
+			list.Posts[i] = viewSummary(post, fields)
+		}
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(posts)
+		json.NewEncoder(w).Encode(list)
+	}
+}
+
+// postFilter parses the query parameters of GetPostsHandler,
+// writing an error response and returning false if any is invalid
+func postFilter(w http.ResponseWriter, r *http.Request) (models.PostFilter, bool) {
+	query := r.URL.Query()
+	filter := models.PostFilter{
+		Sort:   models.PostSort(query.Get("sort")),
+		Limit:  defaultPerPage,
+		Cursor: query.Get("cursor"),
+	}
+
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxPerPage {
+			http.Error(w, "limit must be between 1 and 100", http.StatusBadRequest)
+			return filter, false
+		}
+		filter.Limit = n
+	}
+	if filter.Sort != "" && !filter.Sort.Valid() {
+		http.Error(w, "sort must be created, updated or title", http.StatusBadRequest)
+		return filter, false
+	}
+	if v := query.Get("author"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid author ID", http.StatusBadRequest)
+			return filter, false
+		}
+		filter.AuthorID = n
+	}
+	if v := query.Get("tag"); v != "" {
+		tags, err := models.NormalizeTags([]string{v})
+		if err != nil {
+			http.Error(w, "Invalid tag", http.StatusBadRequest)
+			return filter, false
+		}
+		filter.Tag = tags[0]
+	}
+	if v := query.Get("category"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "Invalid category ID", http.StatusBadRequest)
+			return filter, false
+		}
+		filter.CategoryID = n
+	}
+	for name, dest := range map[string]**time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := query.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				http.Error(w, name+" must be an RFC 3339 time", http.StatusBadRequest)
+				return filter, false
+			}
+			*dest = &t
+		}
 	}
+
+	return filter, true
 }
 
 // GetPostHandler returns a single post
-func GetPostHandler(db *sql.DB) http.HandlerFunc {
+func GetPostHandler(store models.PostStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get the ID from the URL
 		vars := mux.Vars(r)
@@ -46,13 +204,43 @@ func GetPostHandler(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		// Get the post
-		post, err := models.GetPostByID(db, id)
-		if err != nil {
+		// Get the post, hiding unpublished posts from everyone but their author
+		post := findVisiblePost(w, r, store, id)
+		if post == nil {
+			return
+		}
+
+		// Respond with the post
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(post)
+	}
+}
+
+// GetPostBySlugHandler returns a single post by its slug.
+// Old slugs of a post answer with a permanent redirect to the current one.
+func GetPostBySlugHandler(store models.PostStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Get the post, hiding unpublished posts from everyone but their author and editors
+		slug := mux.Vars(r)["slug"]
+		post, err := store.GetPostBySlug(slug)
+		if err != nil || !canSee(r, post, userID) {
 			http.Error(w, "Post not found", http.StatusNotFound)
 			return
 		}
 
+		// Send readers of an old slug to the current one
+		if post.Slug != slug {
+			http.Redirect(w, r, "/api/posts/by-slug/"+post.Slug, http.StatusMovedPermanently)
+			return
+		}
+
 		// Respond with the post
 		w.Header().Set("Content-Type", "application/json")
 		json.NewEncoder(w).Encode(post)
@@ -60,7 +248,7 @@ func GetPostHandler(db *sql.DB) http.HandlerFunc {
 }
 
 // CreatePostHandler creates a new post
-func CreatePostHandler(db *sql.DB) http.HandlerFunc {
+func CreatePostHandler(store models.PostStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get the user ID from the context
 		userID, ok := r.Context().Value(auth.UserIDKey).(int)
@@ -77,15 +265,15 @@ func CreatePostHandler(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Validate the request
-		if req.Title == "" || req.Content == "" {
-			http.Error(w, "Title and content are required", http.StatusBadRequest)
+		if err := req.validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
 		// Create the post
-		post, err := models.CreatePost(db, req.Title, req.Content, userID)
+		post, err := store.CreatePost(req.input(), userID)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			postError(w, err)
 			return
 		}
 
@@ -97,7 +285,7 @@ func CreatePostHandler(db *sql.DB) http.HandlerFunc {
 }
 
 // UpdatePostHandler updates an existing post
-func UpdatePostHandler(db *sql.DB) http.HandlerFunc {
+func UpdatePostHandler(store models.PostStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get the user ID from the context
 		userID, ok := r.Context().Value(auth.UserIDKey).(int)
@@ -122,15 +310,15 @@ func UpdatePostHandler(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Validate the request
-		if req.Title == "" || req.Content == "" {
-			http.Error(w, "Title and content are required", http.StatusBadRequest)
+		if err := req.validate(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
 
 		// Update the post
-		post, err := models.UpdatePost(db, id, req.Title, req.Content, userID)
+		post, err := store.UpdatePost(id, req.input(), userID)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			postError(w, err)
 			return
 		}
 
@@ -141,7 +329,7 @@ func UpdatePostHandler(db *sql.DB) http.HandlerFunc {
 }
 
 // DeletePostHandler deletes a post
-func DeletePostHandler(db *sql.DB) http.HandlerFunc {
+func DeletePostHandler(store models.PostStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get the user ID from the context
 		userID, ok := r.Context().Value(auth.UserIDKey).(int)
@@ -159,13 +347,109 @@ func DeletePostHandler(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Delete the post
-		err = models.DeletePost(db, id, userID)
+		err = store.DeletePost(id, userID)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			postError(w, err)
 			return
 		}
 
 		// Respond with success
 		w.WriteHeader(http.StatusNoContent)
 	}
-}
\ No newline at end of file
+}
+
+// SetPostStatusHandler moves a post to the given lifecycle state.
+// Scheduling reads the publication time from a ScheduleRequest body.
+func SetPostStatusHandler(store models.PostStore, status models.PostStatus) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Get the ID from the URL
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, "Invalid post ID", http.StatusBadRequest)
+			return
+		}
+
+		// Parse the publication time when scheduling
+		var publishAt *time.Time
+		if status == models.StatusScheduled {
+			var req ScheduleRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			publishAt = req.PublishAt
+		}
+
+		// Change the status
+		post, err := store.SetPostStatus(id, status, publishAt, userID)
+		if err != nil {
+			postError(w, err)
+			return
+		}
+
+		// Respond with the updated post
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(post)
+	}
+}
+
+// findVisiblePost loads a post and checks that the current user may see it.
+// It writes an error response and returns nil if the post is missing or hidden.
+// Editors and admins see unpublished posts too, as they may edit them.
+func findVisiblePost(w http.ResponseWriter, r *http.Request, store models.PostStore, id int) *models.Post {
+	userID, ok := r.Context().Value(auth.UserIDKey).(int)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil
+	}
+
+	post, err := store.GetPostByID(id)
+	if err != nil || !canSee(r, post, userID) {
+		http.Error(w, "Post not found", http.StatusNotFound)
+		return nil
+	}
+	return post
+}
+
+// canSee reports whether the current user may see the post, published or not
+func canSee(r *http.Request, post *models.Post, userID int) bool {
+	return post.VisibleTo(viewer(r, userID))
+}
+
+// viewer returns the current user as a viewer of posts
+func viewer(r *http.Request, userID int) models.Viewer {
+	role, _ := r.Context().Value(auth.RoleKey).(models.Role)
+	return models.Viewer{ID: userID, Role: role}
+}
+
+// postError writes the response for an error returned by a post store write
+func postError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrPostNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrNotAuthor):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, models.ErrInvalidStatus),
+		errors.Is(err, models.ErrInvalidTransition),
+		errors.Is(err, models.ErrPublishAtRequired),
+		errors.Is(err, models.ErrInvalidSlug),
+		errors.Is(err, models.ErrInvalidTag),
+		errors.Is(err, models.ErrTooManyTags),
+		errors.Is(err, models.ErrCategoryNotFound),
+		errors.Is(err, models.ErrInvalidSEO),
+		errors.Is(err, models.ErrFeaturedImageMissing):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, models.ErrSlugTaken):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,230 @@
+// backend/internal/handlers/revision_handlers.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"blog-app/internal/auth"
+	"blog-app/internal/diff"
+	"blog-app/internal/models"
+)
+
+// RevisionDiffResponse represents the difference between two revisions of a post
+type RevisionDiffResponse struct {
+	PostID    int          `json:"post_id"`
+	From      int          `json:"from"` // 0 when diffing against an empty post
+	To        int          `json:"to"`
+	FromTitle string       `json:"from_title"`
+	ToTitle   string       `json:"to_title"`
+	Changes   []diff.Chunk `json:"changes"`
+	Unified   string       `json:"unified"`
+}
+
+// GetPostRevisionsHandler returns the revision history of a post
+func GetPostRevisionsHandler(store models.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the ID from the URL
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, "Invalid post ID", http.StatusBadRequest)
+			return
+		}
+
+		// The history is only visible to the author and editors
+		if findRevisedPost(w, r, store, id) == nil {
+			return
+		}
+
+		// Get the revisions
+		revisions, err := store.GetPostRevisions(id)
+		if err != nil {
+			if err == models.ErrPostNotFound {
+				http.Error(w, "Post not found", http.StatusNotFound)
+				return
+			}
+			http.Error(w, "Failed to get revisions", http.StatusInternalServerError)
+			return
+		}
+
+		// Respond with the revisions
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(revisions)
+	}
+}
+
+// GetPostRevisionHandler returns a single revision of a post
+func GetPostRevisionHandler(store models.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, rev, ok := revisionParams(w, r)
+		if !ok {
+			return
+		}
+
+		// The history is only visible to the author and editors
+		if findRevisedPost(w, r, store, id) == nil {
+			return
+		}
+
+		// Get the revision
+		revision, err := store.GetPostRevision(id, rev)
+		if err != nil {
+			http.Error(w, "Revision not found", http.StatusNotFound)
+			return
+		}
+
+		// Respond with the revision
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(revision)
+	}
+}
+
+// DiffPostRevisionHandler compares a revision against another one.
+// The "against" query parameter defaults to the previous revision.
+func DiffPostRevisionHandler(store models.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, rev, ok := revisionParams(w, r)
+		if !ok {
+			return
+		}
+
+		// The history is only visible to the author and editors
+		if findRevisedPost(w, r, store, id) == nil {
+			return
+		}
+
+		against := rev - 1
+		if v := r.URL.Query().Get("against"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				http.Error(w, "Invalid against revision", http.StatusBadRequest)
+				return
+			}
+			against = n
+		}
+
+		// Get the revision being inspected
+		to, err := store.GetPostRevision(id, rev)
+		if err != nil {
+			http.Error(w, "Revision not found", http.StatusNotFound)
+			return
+		}
+
+		// Get the base revision; revision 0 stands for an empty post
+		from := &models.PostRevision{PostID: id}
+		if against > 0 {
+			from, err = store.GetPostRevision(id, against)
+			if err != nil {
+				http.Error(w, "Revision to compare against not found", http.StatusNotFound)
+				return
+			}
+		}
+
+		changes, err := diff.Lines(from.Content, to.Content)
+		if err != nil {
+			http.Error(w, "Revisions are too large to compare", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		// Respond with the diff
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(RevisionDiffResponse{
+			PostID:    id,
+			From:      from.Revision,
+			To:        to.Revision,
+			FromTitle: from.Title,
+			ToTitle:   to.Title,
+			Changes:   changes,
+			Unified:   diff.Unified(changes),
+		})
+	}
+}
+
+// RestorePostRevisionHandler rolls a post back to an earlier revision.
+// Revisions hold the title and content, so only those are restored; the
+// current slug, status, tags, category, featured image and SEO metadata are
+// kept. The restore is saved as a new revision, so it can itself be undone.
+func RestorePostRevisionHandler(store models.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		id, rev, ok := revisionParams(w, r)
+		if !ok {
+			return
+		}
+
+		// The history is only visible to the author and editors; UpdatePost
+		// then checks the user may edit the post, as for updates
+		if findRevisedPost(w, r, store, id) == nil {
+			return
+		}
+
+		// Get the revision to restore
+		revision, err := store.GetPostRevision(id, rev)
+		if err != nil {
+			http.Error(w, "Revision not found", http.StatusNotFound)
+			return
+		}
+
+		// Write it back as the current version
+		post, err := store.UpdatePost(id, models.PostInput{
+			Title:   revision.Title,
+			Content: revision.Content,
+		}, userID)
+		if err != nil {
+			postError(w, err)
+			return
+		}
+
+		// Respond with the restored post
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(post)
+	}
+}
+
+// findRevisedPost returns the post whose revisions the current user wants to
+// see, writing an error response and returning nil if the post is not visible
+// to them or they are neither its author nor allowed to edit every post.
+// Revisions hold earlier drafts that may never have been meant for readers.
+func findRevisedPost(w http.ResponseWriter, r *http.Request, store models.PostStore, id int) *models.Post {
+	post := findVisiblePost(w, r, store, id)
+	if post == nil {
+		return nil
+	}
+
+	userID, _ := r.Context().Value(auth.UserIDKey).(int)
+	role, _ := r.Context().Value(auth.RoleKey).(models.Role)
+	if post.AuthorID != userID && !role.Can(models.PermEditAnyPost) {
+		http.Error(w, "You can only see the revisions of your own posts", http.StatusForbidden)
+		return nil
+	}
+	return post
+}
+
+// revisionParams parses the post ID and revision number from the URL,
+// writing an error response and returning false if either is invalid
+func revisionParams(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		http.Error(w, "Invalid post ID", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	rev, err := strconv.Atoi(vars["rev"])
+	if err != nil || rev < 1 {
+		http.Error(w, "Invalid revision number", http.StatusBadRequest)
+		return 0, 0, false
+	}
+
+	return id, rev, true
+}
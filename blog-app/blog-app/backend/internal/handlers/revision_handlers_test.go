@@ -0,0 +1,55 @@
+// backend/internal/handlers/revision_handlers_test.go
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"blog-app/internal/models"
+)
+
+func TestRevisionsOnlyForAuthorAndEditors(t *testing.T) {
+	store := models.NewMemoryStore()
+	alice, err := store.CreateUser("alice", "alice@example.com", "password123")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	post, err := store.CreatePost(models.PostInput{Title: "Post", Content: "First draft", Status: models.StatusPublished}, alice.ID)
+	if err != nil {
+		t.Fatalf("failed to create post: %v", err)
+	}
+	if _, err := store.UpdatePost(post.ID, models.PostInput{Title: "Post", Content: "Second draft"}, alice.ID); err != nil {
+		t.Fatalf("failed to update post: %v", err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/posts/{id}/revisions", GetPostRevisionsHandler(store)).Methods("GET")
+	router.HandleFunc("/posts/{id}/revisions/{rev}", GetPostRevisionHandler(store)).Methods("GET")
+	router.HandleFunc("/posts/{id}/revisions/{rev}/diff", DiffPostRevisionHandler(store)).Methods("GET")
+
+	base := "/posts/" + strconv.Itoa(post.ID) + "/revisions"
+	users := []struct {
+		name   string
+		id     int
+		role   models.Role
+		status int
+	}{
+		{"author", alice.ID, models.RoleAuthor, http.StatusOK},
+		{"editor", alice.ID + 1, models.RoleEditor, http.StatusOK},
+		{"another author", alice.ID + 2, models.RoleAuthor, http.StatusForbidden},
+		{"reader", alice.ID + 3, models.RoleReader, http.StatusForbidden},
+	}
+	for _, path := range []string{base, base + "/1", base + "/2/diff"} {
+		for _, u := range users {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, withUser(httptest.NewRequest("GET", path, nil), u.id, u.role))
+			if w.Code != u.status {
+				t.Errorf("GET %s as %s: status = %d, want %d", path, u.name, w.Code, u.status)
+			}
+		}
+	}
+}
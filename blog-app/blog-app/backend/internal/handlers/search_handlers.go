@@ -0,0 +1,92 @@
+// backend/internal/handlers/search_handlers.go
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"blog-app/internal/auth"
+	"blog-app/internal/models"
+)
+
+// maxQueryLength is the longest search query accepted, in bytes
+const maxQueryLength = 200
+
+// SearchPage is a page of search results, best match first
+type SearchPage struct {
+	Query      string      `json:"query"`
+	Results    []SearchHit `json:"results"`
+	Page       int         `json:"page"`
+	PerPage    int         `json:"per_page"`
+	Total      int         `json:"total"`
+	TotalPages int         `json:"total_pages"`
+}
+
+// SearchHit is a post matching a search, summarised like in post listings
+type SearchHit struct {
+	Post    PostView `json:"post"`
+	Score   float64  `json:"score"`
+	Snippet string   `json:"snippet"` // HTML-escaped plain text with matching words wrapped in <mark>
+}
+
+// SearchHandler searches the title and content of the posts visible to the user.
+// The "q" query parameter holds the search terms; "page" and "per_page" select the
+// page and "fields" the post fields to return, as in GetPostsHandler.
+func SearchHandler(store models.SearchStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Validate the query
+		query := strings.TrimSpace(r.URL.Query().Get("q"))
+		if query == "" {
+			http.Error(w, "Search query is required", http.StatusBadRequest)
+			return
+		}
+		if len(query) > maxQueryLength {
+			http.Error(w, "Search query must be at most 200 characters", http.StatusBadRequest)
+			return
+		}
+
+		page, perPage, ok := pageParams(w, r)
+		if !ok {
+			return
+		}
+		fields, ok := postFields(w, r)
+		if !ok {
+			return
+		}
+
+		// Run the search
+		results, total, err := store.SearchPosts(query, viewer(r, userID), page, perPage)
+		if err != nil {
+			if errors.Is(err, models.ErrEmptyQuery) {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			http.Error(w, "Failed to search posts", http.StatusInternalServerError)
+			return
+		}
+
+		// Respond with the page
+		hits := make([]SearchHit, len(results))
+		for i, result := range results {
+			hits[i] = SearchHit{Post: viewPost(result.Post, fields), Score: result.Score, Snippet: result.Snippet}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(SearchPage{
+			Query:      query,
+			Results:    hits,
+			Page:       page,
+			PerPage:    perPage,
+			Total:      total,
+			TotalPages: totalPages(total, perPage),
+		})
+	}
+}
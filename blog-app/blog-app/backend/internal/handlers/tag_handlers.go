@@ -0,0 +1,33 @@
+// backend/internal/handlers/tag_handlers.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"blog-app/internal/auth"
+	"blog-app/internal/models"
+)
+
+// GetTagsHandler returns every tag in use, with the number of posts visible to the current user
+func GetTagsHandler(store models.TagStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Get the tags
+		tags, err := store.GetTags(viewer(r, userID))
+		if err != nil {
+			http.Error(w, "Failed to get tags", http.StatusInternalServerError)
+			return
+		}
+
+		// Respond with the tags
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tags)
+	}
+}
@@ -0,0 +1,130 @@
+// backend/internal/handlers/token_handlers.go
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"blog-app/internal/auth"
+	"blog-app/internal/models"
+)
+
+// defaultPersonalTokenLifetime is how long a personal access token lasts when
+// the request does not say
+const defaultPersonalTokenLifetime = 30 * 24 * time.Hour
+
+// PersonalTokenRequest represents the request body for creating a personal access token
+type PersonalTokenRequest struct {
+	Name      string         `json:"name"`
+	Scopes    []models.Scope `json:"scopes"`
+	ExpiresAt *time.Time     `json:"expires_at"` // Defaults to 30 days from now
+}
+
+// PersonalTokenResponse represents a newly created personal access token,
+// the only response that includes the token itself
+type PersonalTokenResponse struct {
+	*models.PersonalToken
+	Token string `json:"token"`
+}
+
+// GetPersonalTokensHandler lists the current user's personal access tokens
+func GetPersonalTokensHandler(store models.PersonalTokenStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		tokens, err := store.GetPersonalTokens(userID)
+		if err != nil {
+			http.Error(w, "Failed to get tokens", http.StatusInternalServerError)
+			return
+		}
+
+		// Respond with the tokens
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(tokens)
+	}
+}
+
+// CreatePersonalTokenHandler mints a personal access token for the current user
+func CreatePersonalTokenHandler(store models.PersonalTokenStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Parse the request body
+		var req PersonalTokenRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		expiresAt := time.Now().Add(defaultPersonalTokenLifetime)
+		if req.ExpiresAt != nil {
+			expiresAt = *req.ExpiresAt
+		}
+
+		// Create the token
+		token, prefix, hash, err := auth.NewPersonalToken()
+		if err != nil {
+			http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+			return
+		}
+		pat, err := store.CreatePersonalToken(userID, req.Name, req.Scopes, prefix, hash, expiresAt)
+		if err != nil {
+			tokenError(w, err)
+			return
+		}
+
+		// Respond with the token, which cannot be retrieved again
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(PersonalTokenResponse{PersonalToken: pat, Token: token})
+	}
+}
+
+// RevokePersonalTokenHandler revokes one of the current user's personal access tokens
+func RevokePersonalTokenHandler(store models.PersonalTokenStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		id, ok := urlID(w, r, "id", "Invalid token ID")
+		if !ok {
+			return
+		}
+
+		if err := store.RevokePersonalToken(id, userID); err != nil {
+			tokenError(w, err)
+			return
+		}
+
+		// Respond with success
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// tokenError writes the response for an error returned by a personal access token store write
+func tokenError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrPersonalTokenNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrInvalidScope),
+		errors.Is(err, models.ErrInvalidTokenName),
+		errors.Is(err, models.ErrInvalidTokenExpiry):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,83 @@
+// backend/internal/handlers/token_handlers_test.go
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+
+	"blog-app/internal/auth"
+	"blog-app/internal/models"
+)
+
+func TestPersonalTokenHandlers(t *testing.T) {
+	store := models.NewMemoryStore()
+	alice, err := store.CreateUser("alice", "alice@example.com", "password123")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+	bob, err := store.CreateUser("bob", "bob@example.com", "password123")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	router := mux.NewRouter()
+	router.HandleFunc("/tokens", GetPersonalTokensHandler(store)).Methods("GET")
+	router.HandleFunc("/tokens", CreatePersonalTokenHandler(store)).Methods("POST")
+	router.HandleFunc("/tokens/{id}", RevokePersonalTokenHandler(store)).Methods("DELETE")
+	serve := func(method, path, body string, user *models.User) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, withUser(httptest.NewRequest(method, path, strings.NewReader(body)), user.ID, user.Role))
+		return w
+	}
+
+	// Invalid settings are refused
+	for _, body := range []string{
+		`{"name": "", "scopes": ["posts:read"]}`,
+		`{"name": "ci", "scopes": []}`,
+		`{"name": "ci", "scopes": ["posts:admin"]}`,
+		`{"name": "ci", "scopes": ["posts:read"], "expires_at": "2000-01-01T00:00:00Z"}`,
+	} {
+		if w := serve("POST", "/tokens", body, alice); w.Code != http.StatusBadRequest {
+			t.Errorf("POST /tokens %s: status = %d, want %d", body, w.Code, http.StatusBadRequest)
+		}
+	}
+
+	// The token is returned once, when it is created
+	w := serve("POST", "/tokens", `{"name": "ci", "scopes": ["posts:read"]}`, alice)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST /tokens: status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+	}
+	var created PersonalTokenResponse
+	if err := json.NewDecoder(w.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode token: %v", err)
+	}
+	if !auth.IsPersonalToken(created.Token) || !strings.HasPrefix(created.Token, created.Prefix) {
+		t.Errorf("token = %q with prefix %q, want a personal access token", created.Token, created.Prefix)
+	}
+
+	w = serve("GET", "/tokens", "", alice)
+	if strings.Contains(w.Body.String(), created.Token) || !strings.Contains(w.Body.String(), created.Prefix) {
+		t.Errorf("GET /tokens = %s, want the token's prefix but not the token", w.Body)
+	}
+	if w := serve("GET", "/tokens", "", bob); strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("GET /tokens as bob = %s, want no tokens", w.Body)
+	}
+
+	// Only the token's user may revoke it
+	path := "/tokens/" + strconv.Itoa(created.ID)
+	if w := serve("DELETE", path, "", bob); w.Code != http.StatusNotFound {
+		t.Errorf("DELETE %s as bob: status = %d, want %d", path, w.Code, http.StatusNotFound)
+	}
+	if w := serve("DELETE", path, "", alice); w.Code != http.StatusNoContent {
+		t.Errorf("DELETE %s: status = %d, want %d", path, w.Code, http.StatusNoContent)
+	}
+	if _, err := store.GetPersonalTokenByHash(auth.HashToken(created.Token)); err != models.ErrPersonalTokenInvalid {
+		t.Errorf("revoked token lookup error = %v, want %v", err, models.ErrPersonalTokenInvalid)
+	}
+}
@@ -2,33 +2,51 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gorilla/mux"
 
 	"blog-app/internal/auth"
+	"blog-app/internal/blob"
 	"blog-app/internal/models"
 )
 
-// GetUsersHandler returns a list of all users
-func GetUsersHandler(db *sql.DB) http.HandlerFunc {
+// RoleRequest represents the request body for changing a user's role
+type RoleRequest struct {
+	Role models.Role `json:"role"` // admin, editor, author or reader
+}
+
+// GetUsersHandler returns a list of all users. Only admins see their email addresses.
+func GetUsersHandler(store models.UserStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get the user ID from the context
-		_, ok := r.Context().Value(auth.UserIDKey).(int)
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
 		if !ok {
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
 		}
 
+		// Use the current role rather than the one the token was issued with
+		user, err := store.GetUserByID(userID)
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
 		// Get all users
-		users, err := models.GetUsers(db)
+		users, err := store.GetUsers()
 		if err != nil {
 			http.Error(w, "Failed to get users", http.StatusInternalServerError)
 			return
 		}
+		if !user.Role.Can(models.PermManageUsers) {
+			for _, u := range users {
+				u.Email = ""
+			}
+		}
 
 		// Respond with the users
 		w.Header().Set("Content-Type", "application/json")
@@ -36,11 +54,13 @@ func GetUsersHandler(db *sql.DB) http.HandlerFunc {
 	}
 }
 
-// DeleteUserHandler deletes a user
-func DeleteUserHandler(db *sql.DB) http.HandlerFunc {
+// DeleteUserHandler deletes a user along with the files of their media.
+// Users may delete themselves; deleting anyone else needs the users:manage
+// permission, which the store checks against the current role.
+func DeleteUserHandler(store models.Store, files blob.Storage) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get the user ID from the context
-		_, ok := r.Context().Value(auth.UserIDKey).(int)
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
 		if !ok {
 			http.Error(w, "Unauthorized", http.StatusUnauthorized)
 			return
@@ -54,14 +74,72 @@ func DeleteUserHandler(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		// Delete the user
-		err = models.DeleteUser(db, id)
+		// Look up their media before the records are gone
+		items, err := store.GetMediaByOwner(id)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
+		// Delete the user, then their files
+		err = store.DeleteUser(id, userID)
+		if err != nil {
+			userError(w, err)
+			return
+		}
+		for _, item := range items {
+			deleteFiles(files, item.Variants)
+		}
+
 		// Respond with success
 		w.WriteHeader(http.StatusNoContent)
 	}
-}
\ No newline at end of file
+}
+
+// SetUserRoleHandler changes the role of a user. It is served on the admin
+// routes, which only users whose current role is admin can reach.
+func SetUserRoleHandler(store models.UserStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the ID from the URL
+		vars := mux.Vars(r)
+		id, err := strconv.Atoi(vars["id"])
+		if err != nil {
+			http.Error(w, "Invalid user ID", http.StatusBadRequest)
+			return
+		}
+
+		// Parse the request body
+		var req RoleRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		// Change the role
+		user, err := store.SetUserRole(id, req.Role)
+		if err != nil {
+			userError(w, err)
+			return
+		}
+
+		// Respond with the updated user
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(user.ToResponse())
+	}
+}
+
+// userError writes the response for an error returned by a user store write
+func userError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, models.ErrNotAuthor):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, models.ErrInvalidUserRole):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, models.ErrLastAdmin), errors.Is(err, models.ErrSoleOwner):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,577 @@
+// backend/internal/handlers/website_handlers.go
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+
+	"blog-app/internal/access"
+	"blog-app/internal/auth"
+	"blog-app/internal/models"
+)
+
+// maxTextLength is the longest website name, page title or item title and subtitle, in characters
+const maxTextLength = 255
+
+// WebsiteRequest represents the request body for creating or updating a website
+type WebsiteRequest struct {
+	Name   string `json:"name"`
+	Domain string `json:"domain"` // Host name such as "example.com"
+}
+
+// PageRequest represents the request body for creating or updating a page
+type PageRequest struct {
+	Title string `json:"title"`
+	Slug  string `json:"slug,omitempty"` // Optional, generated from the title on create when empty
+}
+
+// ItemRequest represents the request body for creating or updating an item
+type ItemRequest struct {
+	Title    string `json:"title"`
+	Subtitle string `json:"subtitle,omitempty"`
+	ImageID  *int   `json:"image_id,omitempty"` // Optional media ID, omit or send 0 for no image
+}
+
+// MoveItemRequest represents the request body for moving an item on its page
+type MoveItemRequest struct {
+	Position *int `json:"position,omitempty"` // 0-based position on the page, defaults to last
+}
+
+// GetWebsitesHandler returns the websites the current user is a member of, with their role on each
+func GetWebsitesHandler(store models.WebsiteMemberStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		websites, err := store.GetWebsitesByMember(userID)
+		if err != nil {
+			http.Error(w, "Failed to get websites", http.StatusInternalServerError)
+			return
+		}
+
+		// Respond with the websites
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(websites)
+	}
+}
+
+// GetWebsiteHandler returns a single website
+func GetWebsiteHandler(store models.WebsiteStore, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		website := findWebsite(w, r, store, sites, access.View)
+		if website == nil {
+			return
+		}
+
+		// Respond with the website
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(website)
+	}
+}
+
+// CreateWebsiteHandler creates a website with the current user as its owner
+func CreateWebsiteHandler(store models.WebsiteStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Get the user ID from the context
+		userID, ok := r.Context().Value(auth.UserIDKey).(int)
+		if !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		// Parse and validate the request body
+		var req WebsiteRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		req.Name = strings.TrimSpace(req.Name)
+		if !validText(req.Name, true) {
+			http.Error(w, "Name is required and must be at most 255 characters", http.StatusBadRequest)
+			return
+		}
+
+		// Create the website
+		website, err := store.CreateWebsite(models.WebsiteInput{Name: req.Name, Domain: req.Domain}, userID)
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with the website
+		website.Role = models.WebsiteOwner
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(website)
+	}
+}
+
+// UpdateWebsiteHandler renames a website or changes its domain
+func UpdateWebsiteHandler(store models.WebsiteStore, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		website := findWebsite(w, r, store, sites, access.Manage)
+		if website == nil {
+			return
+		}
+
+		// Parse and validate the request body
+		var req WebsiteRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		req.Name = strings.TrimSpace(req.Name)
+		if !validText(req.Name, true) {
+			http.Error(w, "Name is required and must be at most 255 characters", http.StatusBadRequest)
+			return
+		}
+
+		// Update the website
+		role := website.Role
+		website, err := store.UpdateWebsite(website.ID, models.WebsiteInput{Name: req.Name, Domain: req.Domain})
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+		website.Role = role
+
+		// Respond with the updated website
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(website)
+	}
+}
+
+// DeleteWebsiteHandler deletes a website with all of its pages and items
+func DeleteWebsiteHandler(store models.WebsiteStore, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		website := findWebsite(w, r, store, sites, access.Manage)
+		if website == nil {
+			return
+		}
+
+		if err := store.DeleteWebsite(website.ID); err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with success
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetPagesHandler returns the pages of a website
+func GetPagesHandler(store models.WebsiteStore, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		website := findWebsite(w, r, store, sites, access.View)
+		if website == nil {
+			return
+		}
+
+		pages, err := store.GetPages(website.ID)
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with the pages
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(pages)
+	}
+}
+
+// GetPageHandler returns a single page of a website
+func GetPageHandler(store models.WebsiteStore, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page := findPage(w, r, store, sites, access.View)
+		if page == nil {
+			return
+		}
+
+		// Respond with the page
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	}
+}
+
+// CreatePageHandler creates a page on a website
+func CreatePageHandler(store models.WebsiteStore, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		website := findWebsite(w, r, store, sites, access.Edit)
+		if website == nil {
+			return
+		}
+
+		// Parse and validate the request body
+		req, ok := pageRequest(w, r)
+		if !ok {
+			return
+		}
+
+		// Create the page
+		page, err := store.CreatePage(website.ID, models.PageInput{Title: req.Title, Slug: req.Slug})
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with the page
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(page)
+	}
+}
+
+// UpdatePageHandler updates the title and optionally the slug of a page
+func UpdatePageHandler(store models.WebsiteStore, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page := findPage(w, r, store, sites, access.Edit)
+		if page == nil {
+			return
+		}
+
+		// Parse and validate the request body
+		req, ok := pageRequest(w, r)
+		if !ok {
+			return
+		}
+
+		// Update the page
+		page, err := store.UpdatePage(page.WebsiteID, page.ID, models.PageInput{Title: req.Title, Slug: req.Slug})
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with the updated page
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	}
+}
+
+// DeletePageHandler deletes a page with all of its items
+func DeletePageHandler(store models.WebsiteStore, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page := findPage(w, r, store, sites, access.Delete)
+		if page == nil {
+			return
+		}
+
+		if err := store.DeletePage(page.WebsiteID, page.ID); err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with success
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// GetItemsHandler returns the items of a page in position order
+func GetItemsHandler(store models.WebsiteStore, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page := findPage(w, r, store, sites, access.View)
+		if page == nil {
+			return
+		}
+
+		items, err := store.GetItems(page.ID)
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with the items
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(items)
+	}
+}
+
+// GetItemHandler returns a single item of a page
+func GetItemHandler(store models.WebsiteStore, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page := findPage(w, r, store, sites, access.View)
+		if page == nil {
+			return
+		}
+		id, ok := urlID(w, r, "item", "Invalid item ID")
+		if !ok {
+			return
+		}
+
+		item, err := store.GetItem(page.ID, id)
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with the item
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(item)
+	}
+}
+
+// CreateItemHandler adds an item to the end of a page
+func CreateItemHandler(store models.WebsiteStore, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page := findPage(w, r, store, sites, access.Edit)
+		if page == nil {
+			return
+		}
+
+		// Parse and validate the request body
+		req, ok := itemRequest(w, r)
+		if !ok {
+			return
+		}
+
+		// Create the item
+		item, err := store.CreateItem(page.ID, models.ItemInput{Title: req.Title, Subtitle: req.Subtitle, ImageID: req.ImageID})
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with the item
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(item)
+	}
+}
+
+// UpdateItemHandler updates the title, subtitle and image of an item
+func UpdateItemHandler(store models.WebsiteStore, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page := findPage(w, r, store, sites, access.Edit)
+		if page == nil {
+			return
+		}
+		id, ok := urlID(w, r, "item", "Invalid item ID")
+		if !ok {
+			return
+		}
+
+		// Parse and validate the request body
+		req, ok := itemRequest(w, r)
+		if !ok {
+			return
+		}
+
+		// Update the item
+		item, err := store.UpdateItem(page.ID, id, models.ItemInput{Title: req.Title, Subtitle: req.Subtitle, ImageID: req.ImageID})
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with the updated item
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(item)
+	}
+}
+
+// MoveItemHandler moves an item to a new position on its page
+func MoveItemHandler(store models.WebsiteStore, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page := findPage(w, r, store, sites, access.Edit)
+		if page == nil {
+			return
+		}
+		id, ok := urlID(w, r, "item", "Invalid item ID")
+		if !ok {
+			return
+		}
+
+		// Parse the request body
+		var req MoveItemRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		position := -1
+		if req.Position != nil {
+			if *req.Position < 0 {
+				http.Error(w, "Position must not be negative", http.StatusBadRequest)
+				return
+			}
+			position = *req.Position
+		}
+
+		// Move the item
+		item, err := store.MoveItem(page.ID, id, position)
+		if err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with the moved item
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(item)
+	}
+}
+
+// DeleteItemHandler deletes an item from a page
+func DeleteItemHandler(store models.WebsiteStore, sites *access.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page := findPage(w, r, store, sites, access.Delete)
+		if page == nil {
+			return
+		}
+		id, ok := urlID(w, r, "item", "Invalid item ID")
+		if !ok {
+			return
+		}
+
+		if err := store.DeleteItem(page.ID, id); err != nil {
+			websiteError(w, err)
+			return
+		}
+
+		// Respond with success
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// findWebsite loads the website named in the URL, with the current user's role
+// on it. It writes an error response and returns nil if the website cannot be
+// found, the user is not a member or their role does not grant perm.
+func findWebsite(w http.ResponseWriter, r *http.Request, store models.WebsiteStore, sites *access.Policy, perm access.Permission) *models.Website {
+	userID, ok := r.Context().Value(auth.UserIDKey).(int)
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil
+	}
+	id, ok := urlID(w, r, "site", "Invalid website ID")
+	if !ok {
+		return nil
+	}
+
+	member, err := sites.Authorize(userID, id, perm)
+	if err != nil {
+		websiteError(w, err)
+		return nil
+	}
+	website, err := store.GetWebsite(id)
+	if err != nil {
+		websiteError(w, err)
+		return nil
+	}
+	website.Role = member.Role
+	return website
+}
+
+// findPage loads the page named in the URL, checking the website the same way as findWebsite
+func findPage(w http.ResponseWriter, r *http.Request, store models.WebsiteStore, sites *access.Policy, perm access.Permission) *models.Page {
+	website := findWebsite(w, r, store, sites, perm)
+	if website == nil {
+		return nil
+	}
+	id, ok := urlID(w, r, "page", "Invalid page ID")
+	if !ok {
+		return nil
+	}
+
+	page, err := store.GetPage(website.ID, id)
+	if err != nil {
+		websiteError(w, err)
+		return nil
+	}
+	return page
+}
+
+// urlID parses a numeric ID from the URL variable with the given name,
+// writing message as a bad request and returning false if it is invalid
+func urlID(w http.ResponseWriter, r *http.Request, name, message string) (int, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)[name])
+	if err != nil {
+		http.Error(w, message, http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// pageRequest parses and validates the body of a page request,
+// writing an error response and returning false if it is invalid
+func pageRequest(w http.ResponseWriter, r *http.Request) (PageRequest, bool) {
+	var req PageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	if !validText(req.Title, true) {
+		http.Error(w, "Title is required and must be at most 255 characters", http.StatusBadRequest)
+		return req, false
+	}
+	if req.Slug != "" && !models.ValidSlug(req.Slug) {
+		http.Error(w, models.ErrInvalidSlug.Error(), http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+// itemRequest parses and validates the body of an item request,
+// writing an error response and returning false if it is invalid
+func itemRequest(w http.ResponseWriter, r *http.Request) (ItemRequest, bool) {
+	var req ItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return req, false
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	req.Subtitle = strings.TrimSpace(req.Subtitle)
+	if !validText(req.Title, true) {
+		http.Error(w, "Title is required and must be at most 255 characters", http.StatusBadRequest)
+		return req, false
+	}
+	if !validText(req.Subtitle, false) {
+		http.Error(w, "Subtitle must be at most 255 characters", http.StatusBadRequest)
+		return req, false
+	}
+	if req.ImageID != nil && *req.ImageID < 0 {
+		http.Error(w, "Invalid image ID", http.StatusBadRequest)
+		return req, false
+	}
+	return req, true
+}
+
+// validText reports whether s fits in maxTextLength characters and, if required, is not empty
+func validText(s string, required bool) bool {
+	return (s != "" || !required) && utf8.RuneCountInString(s) <= maxTextLength
+}
+
+// websiteError writes the response for an error returned by a website store
+func websiteError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrWebsiteNotFound),
+		errors.Is(err, models.ErrPageNotFound),
+		errors.Is(err, models.ErrItemNotFound),
+		errors.Is(err, models.ErrMemberNotFound),
+		errors.Is(err, models.ErrInviteNotFound),
+		errors.Is(err, models.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, access.ErrForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, models.ErrInvalidDomain),
+		errors.Is(err, models.ErrInvalidSlug),
+		errors.Is(err, models.ErrItemImageMissing),
+		errors.Is(err, models.ErrInvalidRole):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, models.ErrDomainTaken),
+		errors.Is(err, models.ErrSlugTaken),
+		errors.Is(err, models.ErrMemberExists),
+		errors.Is(err, models.ErrLastOwner):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,48 @@
+// backend/internal/media/config.go
+package media
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Config holds the media upload settings
+type Config struct {
+	MaxBytes int64  // Largest upload accepted
+	BaseURL  string // Prefix for media URLs, empty for URLs relative to the API host
+}
+
+// DefaultConfig returns the settings used when nothing is configured
+func DefaultConfig() Config {
+	return Config{
+		MaxBytes: 10 << 20,
+	}
+}
+
+// ConfigFromEnv reads the media settings through getenv, falling back to DefaultConfig:
+//   - MEDIA_MAX_BYTES: largest upload accepted, in bytes
+//   - MEDIA_BASE_URL: prefix for media URLs, such as "https://blog.example.com"
+func ConfigFromEnv(getenv func(string) string) (Config, error) {
+	cfg := DefaultConfig()
+
+	if v := getenv("MEDIA_MAX_BYTES"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("invalid MEDIA_MAX_BYTES %q", v)
+		}
+		cfg.MaxBytes = n
+	}
+	cfg.BaseURL = strings.TrimRight(getenv("MEDIA_BASE_URL"), "/")
+
+	return cfg, nil
+}
+
+// URL returns the address a rendition of a media item is served at. Posts
+// reference media through these addresses, which only depend on the media ID.
+func (c Config) URL(mediaID int, rendition string) string {
+	if rendition == Original {
+		return fmt.Sprintf("%s/media/%d", c.BaseURL, mediaID)
+	}
+	return fmt.Sprintf("%s/media/%d/%s", c.BaseURL, mediaID, rendition)
+}
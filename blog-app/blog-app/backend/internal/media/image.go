@@ -0,0 +1,360 @@
+// backend/internal/media/image.go
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+// Errors returned by Process
+var (
+	ErrUnsupportedType = errors.New("only JPEG, PNG and GIF images are supported")
+	ErrInvalidImage    = errors.New("the file is not a valid image")
+	ErrImageTooLarge   = errors.New("the image has too many pixels")
+)
+
+// MaxPixels is the largest image accepted, to keep decoding within memory
+const MaxPixels = 40_000_000
+
+// jpegQuality is used for every JPEG written
+const jpegQuality = 85
+
+// Original is the name of the rendition holding the uploaded image itself
+const Original = "original"
+
+// Variant is a resized rendition generated for every upload
+type Variant struct {
+	Name          string
+	Width, Height int  // Bounding box
+	Crop          bool // Fill the box exactly, cropping the edges, instead of fitting inside it
+}
+
+// Variants lists the renditions generated next to the original. Fitted variants
+// are skipped for images that already fit inside their box.
+var Variants = []Variant{
+	{Name: "thumb", Width: 200, Height: 200, Crop: true},
+	{Name: "medium", Width: 800, Height: 800},
+	{Name: "large", Width: 1600, Height: 1600},
+}
+
+// Rendition is an encoded image ready to be stored
+type Rendition struct {
+	Name          string
+	ContentType   string
+	Width, Height int
+	Data          []byte
+}
+
+// Extension returns the file extension for the rendition's content type
+func (r Rendition) Extension() string {
+	switch r.ContentType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	default:
+		return ".gif"
+	}
+}
+
+// Process validates an uploaded image by its content and returns the original,
+// with any metadata removed, followed by its variants.
+//
+// Originals are re-encoded, which drops EXIF and other metadata; JPEGs are
+// first turned upright according to their EXIF orientation. GIFs are
+// re-encoded frame by frame, keeping their animation but dropping comments
+// and application data such as XMP. Variants of PNGs and GIFs are PNGs, to
+// keep transparency, and variants of animated GIFs show the first frame.
+func Process(data []byte) ([]Rendition, error) {
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return nil, ErrUnsupportedType
+	}
+
+	// Check the size before decoding the pixels
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	var img *image.NRGBA
+	var original Rendition
+	if contentType == "image/gif" {
+		img, original, err = processGIF(data)
+	} else {
+		decoded, _, decodeErr := image.Decode(bytes.NewReader(data))
+		if decodeErr != nil {
+			return nil, ErrInvalidImage
+		}
+		img = toNRGBA(decoded)
+		if contentType == "image/jpeg" {
+			img = orient(img, jpegOrientation(data))
+		}
+		original, err = encode(Original, contentType, img)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	variantType := "image/png"
+	if contentType == "image/jpeg" {
+		variantType = "image/jpeg"
+	}
+
+	renditions := []Rendition{original}
+	for _, v := range Variants {
+		var resized *image.NRGBA
+		if v.Crop {
+			resized = cover(img, v.Width, v.Height)
+		} else {
+			w, h := img.Bounds().Dx(), img.Bounds().Dy()
+			if w <= v.Width && h <= v.Height {
+				continue
+			}
+			resized = fit(img, v.Width, v.Height)
+		}
+
+		r, err := encode(v.Name, variantType, resized)
+		if err != nil {
+			return nil, err
+		}
+		renditions = append(renditions, r)
+	}
+
+	return renditions, nil
+}
+
+// processGIF decodes every frame of a GIF and encodes them again, returning
+// the first frame for the variants and the re-encoded original. The frames
+// of an animation together may not have more than MaxPixels pixels.
+func processGIF(data []byte) (*image.NRGBA, Rendition, error) {
+	g, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil || len(g.Image) == 0 {
+		return nil, Rendition{}, ErrInvalidImage
+	}
+	pixels := 0
+	for _, frame := range g.Image {
+		pixels += frame.Bounds().Dx() * frame.Bounds().Dy()
+	}
+	if pixels > MaxPixels {
+		return nil, Rendition{}, ErrImageTooLarge
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		return nil, Rendition{}, err
+	}
+	original := Rendition{Name: Original, ContentType: "image/gif", Width: g.Config.Width, Height: g.Config.Height, Data: buf.Bytes()}
+	return toNRGBA(g.Image[0]), original, nil
+}
+
+// encode writes an image as a JPEG or PNG rendition
+func encode(name, contentType string, img *image.NRGBA) (Rendition, error) {
+	var buf bytes.Buffer
+	var err error
+	if contentType == "image/jpeg" {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	} else {
+		err = png.Encode(&buf, img)
+	}
+	if err != nil {
+		return Rendition{}, err
+	}
+
+	b := img.Bounds()
+	return Rendition{Name: name, ContentType: contentType, Width: b.Dx(), Height: b.Dy(), Data: buf.Bytes()}, nil
+}
+
+// toNRGBA copies an image into an NRGBA image with its origin at (0, 0)
+func toNRGBA(src image.Image) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+	return dst
+}
+
+// fit scales an image down to fit inside a box, keeping its aspect ratio
+func fit(img *image.NRGBA, maxW, maxH int) *image.NRGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w*maxH > h*maxW {
+		return resize(img, maxW, max(1, h*maxW/w))
+	}
+	return resize(img, max(1, w*maxH/h), maxH)
+}
+
+// cover scales an image to fill a box exactly, cropping the centre to the box's aspect ratio
+func cover(img *image.NRGBA, boxW, boxH int) *image.NRGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	cropW, cropH := w, h
+	if w*boxH > h*boxW {
+		cropW = h * boxW / boxH
+	} else {
+		cropH = w * boxH / boxW
+	}
+	x0, y0 := (w-cropW)/2, (h-cropH)/2
+	cropped := img.SubImage(image.Rect(x0, y0, x0+cropW, y0+cropH)).(*image.NRGBA)
+
+	// Small images are cropped but not enlarged
+	if cropW < boxW {
+		boxW, boxH = cropW, cropH
+	}
+	return resize(cropped, boxW, boxH)
+}
+
+// resize scales an image to w x h by averaging the source pixels that fall
+// into each destination pixel, weighting colours by their opacity
+func resize(src *image.NRGBA, w, h int) *image.NRGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+
+	for y := 0; y < h; y++ {
+		y0, y1 := b.Min.Y+y*sh/h, b.Min.Y+max((y+1)*sh/h, y*sh/h+1)
+		for x := 0; x < w; x++ {
+			x0, x1 := b.Min.X+x*sw/w, b.Min.X+max((x+1)*sw/w, x*sw/w+1)
+
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[src.PixOffset(x0, sy):]
+				for i := 0; i < (x1-x0)*4; i += 4 {
+					pa := uint64(row[i+3])
+					r += uint64(row[i]) * pa
+					g += uint64(row[i+1]) * pa
+					bl += uint64(row[i+2]) * pa
+					a += pa
+					n++
+				}
+			}
+
+			o := dst.PixOffset(x, y)
+			if a > 0 {
+				dst.Pix[o] = uint8(r / a)
+				dst.Pix[o+1] = uint8(g / a)
+				dst.Pix[o+2] = uint8(bl / a)
+			}
+			dst.Pix[o+3] = uint8(a / n)
+		}
+	}
+	return dst
+}
+
+// orient turns an image upright according to its EXIF orientation (1 to 8)
+func orient(img *image.NRGBA, orientation int) *image.NRGBA {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // Flip horizontally
+				dx, dy = w-1-x, y
+			case 3: // Rotate 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // Flip vertically
+				dx, dy = x, h-1-y
+			case 5: // Transpose
+				dx, dy = y, x
+			case 6: // Rotate 90° clockwise
+				dx, dy = h-1-y, x
+			case 7: // Transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // Rotate 90° counter-clockwise
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], img.Pix[img.PixOffset(x, y):img.PixOffset(x, y)+4])
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation of a JPEG, or 1 if it has none
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	// Walk the segments before the image data looking for APP1 with EXIF
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		switch {
+		case marker == 0xFF: // Fill byte
+			i++
+			continue
+		case marker == 0xDA || marker == 0xD9: // Start of scan, end of image
+			return 1
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7): // No length
+			i += 2
+			continue
+		}
+
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+size]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+// tiffOrientation reads the orientation tag from the first IFD of TIFF-encoded EXIF data
+func tiffOrientation(t []byte) int {
+	if len(t) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(t[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(t[4:]))
+	if ifd < 8 || ifd+2 > len(t) {
+		return 1
+	}
+	entries := int(order.Uint16(t[ifd:]))
+	for k := 0; k < entries; k++ {
+		e := ifd + 2 + 12*k
+		if e+12 > len(t) {
+			return 1
+		}
+		if order.Uint16(t[e:]) == 0x0112 {
+			if o := int(order.Uint16(t[e+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 1
+		}
+	}
+	return 1
+}
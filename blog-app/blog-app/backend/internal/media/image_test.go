@@ -0,0 +1,186 @@
+// backend/internal/media/image_test.go
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// testImage returns a w x h image with a different colour in each corner
+func testImage(w, h int) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(255 * x / w), G: uint8(255 * y / h), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// exifJPEG returns a JPEG of a w x h image carrying an EXIF segment with the
+// given orientation and a camera serial number
+func exifJPEG(t *testing.T, w, h, orientation int) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(w, h), nil); err != nil {
+		t.Fatalf("failed to encode JPEG: %v", err)
+	}
+
+	// Big-endian TIFF with a single IFD holding the orientation, followed by the serial number
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08\x00\x01")
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = append(tiff, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01)
+	tiff = binary.BigEndian.AppendUint16(tiff, uint16(orientation))
+	tiff = append(tiff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)
+	tiff = append(tiff, "SERIAL-12345"...)
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+// animatedGIF returns a GIF of two w x h frames with a comment extension
+// holding text before its trailer
+func animatedGIF(t *testing.T, w, h int, comment string) []byte {
+	t.Helper()
+
+	g := &gif.GIF{LoopCount: 0}
+	for i, c := range []color.Color{color.White, color.Black} {
+		frame := image.NewPaletted(image.Rect(0, 0, w, h), palette.Plan9)
+		for p := range frame.Pix {
+			frame.Pix[p] = uint8(frame.Palette.Index(c))
+		}
+		g.Image = append(g.Image, frame)
+		g.Delay = append(g.Delay, 10*(i+1))
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, g); err != nil {
+		t.Fatalf("failed to encode GIF: %v", err)
+	}
+
+	data := buf.Bytes()
+	ext := append([]byte{0x21, 0xFE, byte(len(comment))}, comment...)
+	ext = append(ext, 0x00)
+	return append(append(append([]byte{}, data[:len(data)-1]...), ext...), 0x3B)
+}
+
+func TestProcessStripsJPEGMetadata(t *testing.T) {
+	data := exifJPEG(t, 40, 20, 6)
+	if !bytes.Contains(data, []byte("SERIAL-12345")) {
+		t.Fatal("test JPEG does not carry its EXIF data")
+	}
+
+	renditions, err := Process(data)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	original := renditions[0]
+	if original.Name != Original || original.ContentType != "image/jpeg" {
+		t.Fatalf("first rendition is %s of type %s, want the JPEG original", original.Name, original.ContentType)
+	}
+	if bytes.Contains(original.Data, []byte("Exif")) || bytes.Contains(original.Data, []byte("SERIAL-12345")) {
+		t.Error("the original still carries EXIF data")
+	}
+
+	// Orientation 6 is turned upright, swapping the width and height
+	if original.Width != 20 || original.Height != 40 {
+		t.Errorf("original is %dx%d, want 20x40", original.Width, original.Height)
+	}
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(original.Data))
+	if err != nil || cfg.Width != 20 || cfg.Height != 40 {
+		t.Errorf("stored original decodes as %dx%d, %v; want 20x40", cfg.Width, cfg.Height, err)
+	}
+}
+
+func TestProcessReencodesGIF(t *testing.T) {
+	data := animatedGIF(t, 30, 30, "secret comment")
+
+	renditions, err := Process(data)
+	if err != nil {
+		t.Fatalf("Process() error = %v", err)
+	}
+	original := renditions[0]
+	if original.ContentType != "image/gif" || bytes.Contains(original.Data, []byte("secret comment")) {
+		t.Errorf("original of type %s still carries the comment", original.ContentType)
+	}
+
+	// The animation survives
+	g, err := gif.DecodeAll(bytes.NewReader(original.Data))
+	if err != nil {
+		t.Fatalf("failed to decode the original: %v", err)
+	}
+	if len(g.Image) != 2 || g.Delay[0] != 10 || g.Delay[1] != 20 {
+		t.Errorf("original has %d frames with delays %v, want 2 with delays [10 20]", len(g.Image), g.Delay)
+	}
+
+	// Variants are PNGs of the first frame
+	thumb := renditions[1]
+	if thumb.Name != "thumb" || thumb.ContentType != "image/png" {
+		t.Fatalf("second rendition is %s of type %s, want a PNG thumb", thumb.Name, thumb.ContentType)
+	}
+	img, err := png.Decode(bytes.NewReader(thumb.Data))
+	if err != nil {
+		t.Fatalf("failed to decode the thumb: %v", err)
+	}
+	if r, g, b, _ := img.At(0, 0).RGBA(); r != 0xFFFF || g != 0xFFFF || b != 0xFFFF {
+		t.Errorf("thumb pixel = %x %x %x, want the white first frame", r, g, b)
+	}
+}
+
+func TestProcessRejectsOversizedImages(t *testing.T) {
+	// A small GIF whose header claims a 10000x10000 screen is refused
+	// before any pixels are decoded
+	data := animatedGIF(t, 2, 2, "")
+	binary.LittleEndian.PutUint16(data[6:], 10000)
+	binary.LittleEndian.PutUint16(data[8:], 10000)
+	if _, err := Process(data); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Process() of a 10000x10000 GIF error = %v, want %v", err, ErrImageTooLarge)
+	}
+
+	// As is a PNG whose header claims 8000x8000 pixels, with the header's
+	// checksum fixed up
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(2, 2)); err != nil {
+		t.Fatalf("failed to encode PNG: %v", err)
+	}
+	data = buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:], 8000)
+	binary.BigEndian.PutUint32(data[20:], 8000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	if _, err := Process(data); !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("Process() of an 8000x8000 PNG error = %v, want %v", err, ErrImageTooLarge)
+	}
+}
+
+func TestProcessRejectsOtherFiles(t *testing.T) {
+	bmp := append([]byte("BM"), make([]byte, 60)...)
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"BMP image", bmp, ErrUnsupportedType},
+		{"text", []byte("just some text"), ErrUnsupportedType},
+		{"truncated GIF", []byte("GIF89a\x01\x00"), ErrInvalidImage},
+		{"corrupt PNG", append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 20)...), ErrInvalidImage},
+	}
+	for _, tt := range tests {
+		if _, err := Process(tt.data); !errors.Is(err, tt.want) {
+			t.Errorf("%s: Process() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -3,15 +3,20 @@ package middleware
 
 import (
 	"context"
-	"database/sql"
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"blog-app/internal/auth"
+	"blog-app/internal/models"
 )
 
-// AuthMiddleware is a middleware that checks for a valid JWT token
-func AuthMiddleware(db *sql.DB) func(http.Handler) http.Handler {
+// AuthMiddleware is a middleware that checks for a JWT token signed with a key
+// on the ring that has not been revoked, or for a personal access token whose
+// scopes include the one the route requires. Either way the request gets the
+// user's current role from the store.
+func AuthMiddleware(store models.Store, keys *auth.KeyRing, scopes *TokenScopes) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Get the Authorization header
@@ -30,18 +35,95 @@ func AuthMiddleware(db *sql.DB) func(http.Handler) http.Handler {
 			// Extract the token
 			token := strings.TrimPrefix(authHeader, "Bearer ")
 
+			// Personal access tokens are looked up instead of validated
+			if auth.IsPersonalToken(token) {
+				servePersonalToken(w, r, next, store, scopes, token)
+				return
+			}
+
 			// Validate the token
-			claims, err := auth.ValidateToken(token)
+			claims, err := keys.ValidateToken(token)
 			if err != nil {
 				http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
 				return
 			}
 
-			// Add user ID to request context
-			ctx := context.WithValue(r.Context(), auth.UserIDKey, claims.UserID)
+			// Reject tokens revoked by logging out
+			revoked, err := store.IsTokenRevoked(claims.ID)
+			if err != nil {
+				http.Error(w, "Failed to check token", http.StatusInternalServerError)
+				return
+			}
+			if revoked {
+				http.Error(w, "Token has been revoked", http.StatusUnauthorized)
+				return
+			}
+
+			// Look up the user's current role; the role in the token is the one
+			// they had when it was issued
+			user, err := store.GetUserByID(claims.UserID)
+			if err != nil {
+				if errors.Is(err, models.ErrUserNotFound) {
+					http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+					return
+				}
+				http.Error(w, "Failed to check token", http.StatusInternalServerError)
+				return
+			}
+
+			// Add user ID, role and claims to request context
+			ctx := context.WithValue(r.Context(), auth.UserIDKey, user.ID)
+			ctx = context.WithValue(ctx, auth.RoleKey, user.Role)
+			ctx = context.WithValue(ctx, auth.ClaimsKey, claims)
 			
 			// Call the next handler with the updated context
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
-}
\ No newline at end of file
+}
+
+// servePersonalToken authenticates a request carrying a personal access token
+// as the token's user, with the user's current role. The token's use is only
+// recorded once the route and the token's scopes allow the request.
+func servePersonalToken(w http.ResponseWriter, r *http.Request, next http.Handler, store models.Store, scopes *TokenScopes, token string) {
+	// Check the token
+	pat, err := store.GetPersonalTokenByHash(auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, models.ErrPersonalTokenInvalid) {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, "Failed to check token", http.StatusInternalServerError)
+		return
+	}
+
+	// Check the route is open to the token
+	scope, ok := scopes.scopeOf(r)
+	if !ok {
+		http.Error(w, "Personal access tokens cannot be used here", http.StatusForbidden)
+		return
+	}
+	if !pat.HasScope(scope) {
+		http.Error(w, fmt.Sprintf("Token lacks the %s scope", scope), http.StatusForbidden)
+		return
+	}
+
+	// Act as the token's user
+	user, err := store.GetUserByID(pat.UserID)
+	if err != nil {
+		http.Error(w, models.ErrPersonalTokenInvalid.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	// Record the use of the token
+	if err := store.RecordPersonalTokenUse(pat.ID); err != nil {
+		http.Error(w, "Failed to check token", http.StatusInternalServerError)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), auth.UserIDKey, user.ID)
+	ctx = context.WithValue(ctx, auth.RoleKey, user.Role)
+	ctx = context.WithValue(ctx, auth.PersonalTokenKey, pat)
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
@@ -0,0 +1,127 @@
+// backend/internal/middleware/auth_test.go
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"blog-app/internal/auth"
+	"blog-app/internal/models"
+)
+
+// newTokenRouter returns a router with a route for each kind of scope, the
+// store behind it and a user to mint tokens for
+func newTokenRouter(t *testing.T) (*mux.Router, models.Store, *models.User) {
+	t.Helper()
+
+	store := models.NewMemoryStore()
+	user, err := store.CreateUser("alice", "alice@example.com", "password123")
+	if err != nil {
+		t.Fatalf("failed to create user: %v", err)
+	}
+
+	// Routes answer with the user the request was made as
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Context().Value(auth.UserIDKey) != user.ID {
+			t.Errorf("%s %s: user ID = %v, want %d", r.Method, r.URL.Path, r.Context().Value(auth.UserIDKey), user.ID)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	// Personal access tokens never reach the key ring, so the tests go without one
+	scopes := NewTokenScopes()
+	router := mux.NewRouter()
+	router.Use(AuthMiddleware(store, nil, scopes))
+	scopes.Require(models.ScopePostsRead, router.Handle("/posts", ok).Methods("GET"))
+	scopes.Require(models.ScopePostsWrite, router.Handle("/posts", ok).Methods("POST"))
+	router.Handle("/tokens", ok).Methods("GET")
+	return router, store, user
+}
+
+// mustCreateToken mints a personal access token for the user with the scopes
+func mustCreateToken(t *testing.T, store models.Store, userID int, scopes ...models.Scope) (string, *models.PersonalToken) {
+	t.Helper()
+
+	token, prefix, hash, err := auth.NewPersonalToken()
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	pat, err := store.CreatePersonalToken(userID, "ci", scopes, prefix, hash, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to create token: %v", err)
+	}
+	return token, pat
+}
+
+// lastUsed returns when the store last recorded a use of the token
+func lastUsed(t *testing.T, store models.Store, pat *models.PersonalToken) *time.Time {
+	t.Helper()
+
+	tokens, err := store.GetPersonalTokens(pat.UserID)
+	if err != nil {
+		t.Fatalf("failed to get tokens: %v", err)
+	}
+	for _, token := range tokens {
+		if token.ID == pat.ID {
+			return token.LastUsedAt
+		}
+	}
+	t.Fatalf("token %d not found", pat.ID)
+	return nil
+}
+
+func TestPersonalTokenAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		revoke bool
+		want   int
+	}{
+		{"scope granted", "GET", "/posts", false, http.StatusNoContent},
+		{"scope missing", "POST", "/posts", false, http.StatusForbidden},
+		{"route not open to tokens", "GET", "/tokens", false, http.StatusForbidden},
+		{"revoked token", "GET", "/posts", true, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, store, user := newTokenRouter(t)
+			token, pat := mustCreateToken(t, store, user.ID, models.ScopePostsRead)
+			if tt.revoke {
+				if err := store.RevokePersonalToken(pat.ID, user.ID); err != nil {
+					t.Fatalf("failed to revoke token: %v", err)
+				}
+			}
+
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			r.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+
+			// Only requests the token is allowed to make count as a use
+			used := lastUsed(t, store, pat)
+			if allowed := tt.want < 300; (used != nil) != allowed {
+				t.Errorf("last_used_at = %v, want it set only if the request is allowed", used)
+			}
+		})
+	}
+}
+
+func TestPersonalTokenAuthUnknownToken(t *testing.T) {
+	router, _, _ := newTokenRouter(t)
+
+	r := httptest.NewRequest("GET", "/posts", nil)
+	r.Header.Set("Authorization", "Bearer "+auth.PersonalTokenPrefix+"unknown")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
@@ -0,0 +1,50 @@
+// backend/internal/middleware/role.go
+package middleware
+
+import (
+	"net/http"
+
+	"blog-app/internal/auth"
+	"blog-app/internal/models"
+)
+
+// RequireRole is a middleware that only lets through users with one of the
+// given roles. It must run after AuthMiddleware, which puts the user's current
+// role in the request context.
+func RequireRole(roles ...models.Role) func(http.Handler) http.Handler {
+	return requireRole(func(role models.Role) bool {
+		for _, allowed := range roles {
+			if role == allowed {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// RequirePermission is a middleware that only lets through users whose role
+// allows the permission. It must run after AuthMiddleware.
+func RequirePermission(perm models.Permission) func(http.Handler) http.Handler {
+	return requireRole(func(role models.Role) bool {
+		return role.Can(perm)
+	})
+}
+
+// requireRole builds a middleware rejecting users whose role is not allowed
+func requireRole(allowed func(models.Role) bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := r.Context().Value(auth.RoleKey).(models.Role)
+			if !ok {
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+			if !allowed(role) {
+				http.Error(w, "Your role does not allow this", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
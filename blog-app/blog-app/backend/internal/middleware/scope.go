@@ -0,0 +1,40 @@
+// backend/internal/middleware/scope.go
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"blog-app/internal/models"
+)
+
+// TokenScopes records the scope a personal access token needs for each route.
+// Routes without a scope cannot be used with personal access tokens at all,
+// so a token never reaches more than it was granted.
+type TokenScopes struct {
+	routes map[*mux.Route]models.Scope
+}
+
+// NewTokenScopes creates an empty set of route scopes
+func NewTokenScopes() *TokenScopes {
+	return &TokenScopes{routes: make(map[*mux.Route]models.Scope)}
+}
+
+// Require opens the route to personal access tokens that have the scope. It
+// returns the route, so that it can wrap a route's registration. Routes must
+// be registered before the server starts.
+func (s *TokenScopes) Require(scope models.Scope, route *mux.Route) *mux.Route {
+	s.routes[route] = scope
+	return route
+}
+
+// scopeOf returns the scope needed for the route the request matched
+func (s *TokenScopes) scopeOf(r *http.Request) (models.Scope, bool) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "", false
+	}
+	scope, ok := s.routes[route]
+	return scope, ok
+}
@@ -0,0 +1,345 @@
+// backend/internal/models/category.go
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+	"time"
+)
+
+// Errors returned by category operations
+var (
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrCategoryCycle       = errors.New("a category cannot be moved under itself or one of its descendants")
+	ErrCategoryHasChildren = errors.New("category has subcategories; move or delete them first")
+)
+
+// Category is a node in the category tree. Siblings are ordered by Position.
+type Category struct {
+	ID        int         `json:"id"`
+	Name      string      `json:"name"`
+	ParentID  *int        `json:"parent_id"` // nil for top-level categories
+	Position  int         `json:"position"`  // 0-based position among siblings
+	Children  []*Category `json:"children,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// BuildCategoryTree nests a flat list of categories under their parents,
+// returning the top-level categories with siblings in position order
+func BuildCategoryTree(categories []*Category) []*Category {
+	byID := make(map[int]*Category, len(categories))
+	for _, c := range categories {
+		copied := *c
+		copied.Children = nil
+		byID[c.ID] = &copied
+	}
+
+	roots := []*Category{}
+	for _, c := range categories {
+		node := byID[c.ID]
+		if c.ParentID == nil {
+			roots = append(roots, node)
+			continue
+		}
+		if parent, ok := byID[*c.ParentID]; ok {
+			parent.Children = append(parent.Children, node)
+		}
+	}
+
+	var order func(nodes []*Category)
+	order = func(nodes []*Category) {
+		sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].Position < nodes[j].Position })
+		for _, node := range nodes {
+			order(node.Children)
+		}
+	}
+	order(roots)
+
+	return roots
+}
+
+// categoryDescendants returns id and the IDs of every category below it
+func categoryDescendants(categories []*Category, id int) []int {
+	children := make(map[int][]int)
+	for _, c := range categories {
+		if c.ParentID != nil {
+			children[*c.ParentID] = append(children[*c.ParentID], c.ID)
+		}
+	}
+
+	ids := []int{id}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids
+}
+
+// siblingsOf returns the IDs of the categories under parentID in position order,
+// leaving out the category with the given ID
+func siblingsOf(categories []*Category, parentID *int, except int) []int {
+	var siblings []*Category
+	for _, c := range categories {
+		if c.ID != except && sameParent(c.ParentID, parentID) {
+			siblings = append(siblings, c)
+		}
+	}
+	sort.SliceStable(siblings, func(i, j int) bool { return siblings[i].Position < siblings[j].Position })
+
+	ids := make([]int, len(siblings))
+	for i, c := range siblings {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+// sameParent reports whether two optional parent IDs refer to the same parent
+func sameParent(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// insertAt returns ids with id inserted at position, clamped to the list.
+// A negative position appends.
+func insertAt(ids []int, id, position int) []int {
+	if position < 0 || position > len(ids) {
+		position = len(ids)
+	}
+	ids = append(ids, 0)
+	copy(ids[position+1:], ids[position:])
+	ids[position] = id
+	return ids
+}
+
+// checkMove validates moving a category under a new parent.
+// Pass an id of 0 to only check that the parent exists.
+func checkMove(categories []*Category, id int, parentID *int) error {
+	if parentID == nil {
+		return nil
+	}
+	for _, descendant := range categoryDescendants(categories, id) {
+		if descendant == *parentID {
+			return ErrCategoryCycle
+		}
+	}
+	for _, c := range categories {
+		if c.ID == *parentID {
+			return nil
+		}
+	}
+	return ErrCategoryNotFound
+}
+
+// scanCategory reads a row selected with categorySelect
+func scanCategory(row rowScanner) (*Category, error) {
+	var c Category
+	var parentID sql.NullInt64
+	if err := row.Scan(&c.ID, &c.Name, &parentID, &c.Position, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		c.ParentID = &id
+	}
+	return &c, nil
+}
+
+// categorySelect is the column list shared by every category query, read with scanCategory
+const categorySelect = "SELECT id, name, parent_id, position, created_at, updated_at FROM categories"
+
+// GetCategories retrieves every category as a flat list, ordered by parent and position
+func (s *SQLStore) GetCategories() ([]*Category, error) {
+	rows, err := s.db.Query(categorySelect + " ORDER BY parent_id, position, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []*Category
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return categories, nil
+}
+
+// GetCategory retrieves a category by ID
+func (s *SQLStore) GetCategory(id int) (*Category, error) {
+	c, err := scanCategory(s.db.QueryRow(categorySelect+" WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// CreateCategory creates a category as the last child of parentID, or at the top level if nil
+func (s *SQLStore) CreateCategory(name string, parentID *int) (*Category, error) {
+	categories, err := s.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate that the parent exists
+	if err := checkMove(categories, 0, parentID); err != nil {
+		return nil, err
+	}
+
+	// New categories go after their siblings
+	position := len(siblingsOf(categories, parentID, 0))
+
+	now := s.now()
+	id, err := s.db.Insert(
+		"INSERT INTO categories (name, parent_id, position, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		name, parentID, position, now, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCategory(int(id))
+}
+
+// UpdateCategory renames a category
+func (s *SQLStore) UpdateCategory(id int, name string) (*Category, error) {
+	if _, err := s.GetCategory(id); err != nil {
+		return nil, err
+	}
+
+	_, err := s.db.Exec("UPDATE categories SET name = ?, updated_at = ? WHERE id = ?", name, s.now(), id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetCategory(id)
+}
+
+// MoveCategory moves a category under a new parent (nil for the top level) at the
+// given position among its new siblings. A negative position moves it to the end.
+func (s *SQLStore) MoveCategory(id int, parentID *int, position int) (*Category, error) {
+	categories, err := s.GetCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	var current *Category
+	for _, c := range categories {
+		if c.ID == id {
+			current = c
+		}
+	}
+	if current == nil {
+		return nil, ErrCategoryNotFound
+	}
+	if err := checkMove(categories, id, parentID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Close the gap left behind, then make room among the new siblings
+	now := s.now()
+	if !sameParent(current.ParentID, parentID) {
+		for i, siblingID := range siblingsOf(categories, current.ParentID, id) {
+			if _, err := tx.Exec("UPDATE categories SET position = ? WHERE id = ?", i, siblingID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	for i, siblingID := range insertAt(siblingsOf(categories, parentID, id), id, position) {
+		if _, err := tx.Exec("UPDATE categories SET position = ? WHERE id = ?", i, siblingID); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec("UPDATE categories SET parent_id = ?, updated_at = ? WHERE id = ?", parentID, now, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.GetCategory(id)
+}
+
+// DeleteCategory deletes a category without subcategories.
+// Its posts become uncategorized.
+func (s *SQLStore) DeleteCategory(id int) error {
+	categories, err := s.GetCategories()
+	if err != nil {
+		return err
+	}
+
+	var current *Category
+	for _, c := range categories {
+		if c.ID == id {
+			current = c
+		}
+		if c.ParentID != nil && *c.ParentID == id {
+			return ErrCategoryHasChildren
+		}
+	}
+	if current == nil {
+		return ErrCategoryNotFound
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("UPDATE posts SET category_id = NULL WHERE category_id = ?", id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec("DELETE FROM categories WHERE id = ?", id); err != nil {
+		return err
+	}
+
+	// Close the gap among the remaining siblings
+	for i, siblingID := range siblingsOf(categories, current.ParentID, id) {
+		if _, err := tx.Exec("UPDATE categories SET position = ? WHERE id = ?", i, siblingID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// checkCategory returns ErrCategoryNotFound unless the post category exists.
+// nil and 0 both mean "no category".
+func (s *SQLStore) checkCategory(id *int) error {
+	if id == nil || *id == 0 {
+		return nil
+	}
+	_, err := s.GetCategory(*id)
+	return err
+}
+
+// optionalIDArg converts an optional reference, such as a post category, into a
+// query argument, storing 0 as NULL
+func optionalIDArg(id *int) interface{} {
+	if id == nil || *id == 0 {
+		return nil
+	}
+	return *id
+}
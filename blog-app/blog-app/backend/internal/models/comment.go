@@ -0,0 +1,365 @@
+// backend/internal/models/comment.go
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Errors returned by comment operations
+var (
+	ErrCommentNotFound   = errors.New("comment not found")
+	ErrInvalidParent     = errors.New("parent comment does not belong to this post")
+	ErrCommentDeleted    = errors.New("comment has been deleted")
+	ErrCommentTooLong    = fmt.Errorf("comments must be at most %d characters", MaxCommentLength)
+	ErrCommentEmpty      = errors.New("comment content is required")
+	ErrInvalidModeration = errors.New("invalid moderation status")
+)
+
+// CommentStatus is the moderation state of a comment
+type CommentStatus string
+
+// Comment moderation states. Only approved comments are shown to readers.
+const (
+	CommentPending  CommentStatus = "pending"
+	CommentApproved CommentStatus = "approved"
+	CommentRejected CommentStatus = "rejected"
+	CommentSpam     CommentStatus = "spam"
+)
+
+// Valid reports whether s is a known comment status
+func (s CommentStatus) Valid() bool {
+	switch s {
+	case CommentPending, CommentApproved, CommentRejected, CommentSpam:
+		return true
+	}
+	return false
+}
+
+// MaxCommentLength is the longest comment accepted, in bytes
+const MaxCommentLength = 10000
+
+// Comment is a reader's comment on a post. Replies are threaded through ParentID.
+// Deleted comments that still have replies are kept, without content, so the
+// thread stays intact.
+type Comment struct {
+	ID          int           `json:"id"`
+	PostID      int           `json:"post_id"`
+	ParentID    *int          `json:"parent_id"` // nil for top-level comments
+	AuthorID    int           `json:"author_id"` // 0 if the author has since been deleted
+	Author      string        `json:"author"`    // Username of the author
+	Content     string        `json:"content"`
+	Deleted     bool          `json:"deleted"`
+	Status      CommentStatus `json:"status"`
+	SpamScore   float64       `json:"spam_score"`
+	ModeratedBy int           `json:"moderated_by,omitempty"` // 0 until a moderator acts on the comment
+	ModeratedAt *time.Time    `json:"moderated_at,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	Replies     []*Comment    `json:"replies"`
+}
+
+// CommentInput holds the writable fields of a comment. Status and SpamScore
+// come from the spam scorer; ParentID is only used on create.
+type CommentInput struct {
+	ParentID  *int
+	Content   string
+	Status    CommentStatus
+	SpamScore float64
+}
+
+// visibleTo reports whether a reader who is not a moderator may see the comment
+func (c *Comment) visibleTo(userID int) bool {
+	return c.Status == CommentApproved || (c.AuthorID == userID && userID != 0)
+}
+
+// commentSelect is the column list shared by every comment query, read with scanComment
+const commentSelect = `
+		SELECT c.id, c.post_id, c.parent_id, COALESCE(c.author_id, 0), COALESCE(u.username, ''), c.content, c.deleted_at,
+			c.status, c.spam_score, COALESCE(c.moderated_by, 0), c.moderated_at, c.created_at, c.updated_at
+		FROM comments c
+		LEFT JOIN users u ON c.author_id = u.id`
+
+// scanComment reads a row selected with commentSelect
+func scanComment(row rowScanner) (*Comment, error) {
+	var c Comment
+	var parentID sql.NullInt64
+	var deletedAt, moderatedAt sql.NullTime
+	if err := row.Scan(
+		&c.ID, &c.PostID, &parentID, &c.AuthorID, &c.Author, &c.Content, &deletedAt,
+		&c.Status, &c.SpamScore, &c.ModeratedBy, &moderatedAt, &c.CreatedAt, &c.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		c.ParentID = &id
+	}
+	c.Deleted = deletedAt.Valid
+	if moderatedAt.Valid {
+		c.ModeratedAt = &moderatedAt.Time
+	}
+	c.Replies = []*Comment{}
+	return &c, nil
+}
+
+// buildCommentThreads nests comments under their parents and returns the given
+// page of top-level comments, oldest first, along with the number of top-level
+// comments. Replies are ordered oldest first at every level.
+func buildCommentThreads(comments []*Comment, page, perPage int) ([]*Comment, int) {
+	sort.SliceStable(comments, func(i, j int) bool {
+		if comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].ID < comments[j].ID
+		}
+		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+	})
+
+	byID := make(map[int]*Comment, len(comments))
+	for _, c := range comments {
+		byID[c.ID] = c
+	}
+
+	roots := []*Comment{}
+	for _, c := range comments {
+		if c.ParentID == nil {
+			roots = append(roots, c)
+		} else if parent, ok := byID[*c.ParentID]; ok {
+			parent.Replies = append(parent.Replies, c)
+		}
+	}
+
+	total := len(roots)
+	start := (page - 1) * perPage
+	if start >= total {
+		return []*Comment{}, total
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+	return roots[start:end], total
+}
+
+// GetComments retrieves a page of top-level comments on a post, oldest first,
+// each with its full tree of replies, and the total number of top-level comments.
+// Moderators see every comment; other viewers see approved comments and their own.
+func (s *SQLStore) GetComments(postID, viewerID int, moderator bool, page, perPage int) ([]*Comment, int, error) {
+	query, args := commentSelect+`
+		WHERE c.post_id = ?`, []interface{}{postID}
+	if !moderator {
+		query += " AND (c.status = ? OR c.author_id = ?)"
+		args = append(args, CommentApproved, viewerID)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var comments []*Comment
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		comments = append(comments, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	threads, total := buildCommentThreads(comments, page, perPage)
+	return threads, total, nil
+}
+
+// GetComment retrieves a single comment, without its replies
+func (s *SQLStore) GetComment(id int) (*Comment, error) {
+	c, err := scanComment(s.db.QueryRow(commentSelect+`
+		WHERE c.id = ?`,
+		id,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// CreateComment adds a comment to a post, as a reply to input.ParentID if it is not nil
+func (s *SQLStore) CreateComment(postID int, input CommentInput, authorID int) (*Comment, error) {
+	if err := validateComment(input); err != nil {
+		return nil, err
+	}
+
+	// Replies must stay within the same post, under a comment the author can see
+	if input.ParentID != nil {
+		parent, err := s.GetComment(*input.ParentID)
+		if err != nil {
+			if err == ErrCommentNotFound {
+				return nil, ErrInvalidParent
+			}
+			return nil, err
+		}
+		if parent.PostID != postID || !parent.visibleTo(authorID) {
+			return nil, ErrInvalidParent
+		}
+	}
+
+	now := s.now()
+	id, err := s.db.Insert(
+		"INSERT INTO comments (post_id, parent_id, author_id, content, status, spam_score, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		postID, input.ParentID, authorID, input.Content, input.Status, input.SpamScore, now, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetComment(int(id))
+}
+
+// UpdateComment edits the content of a comment. The edited comment goes back
+// through moderation with the status and spam score in the input.
+func (s *SQLStore) UpdateComment(id int, input CommentInput, userID int) (*Comment, error) {
+	if err := validateComment(input); err != nil {
+		return nil, err
+	}
+
+	c, err := s.GetComment(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the author can edit their own comment
+	if c.AuthorID != userID {
+		return nil, fmt.Errorf("%w: you can only edit your own comments", ErrNotAuthor)
+	}
+	if c.Deleted {
+		return nil, ErrCommentDeleted
+	}
+
+	_, err = s.db.Exec(
+		"UPDATE comments SET content = ?, status = ?, spam_score = ?, updated_at = ? WHERE id = ?",
+		input.Content, input.Status, input.SpamScore, s.now(), id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetComment(id)
+}
+
+// DeleteComment deletes a comment. The comment's author and the author of the
+// post may delete it. A comment with replies is blanked out instead of removed.
+func (s *SQLStore) DeleteComment(id, userID int) error {
+	var authorID, postAuthorID, replies int
+	err := s.db.QueryRow(`
+		SELECT COALESCE(c.author_id, 0), p.author_id, (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id)
+		FROM comments c
+		JOIN posts p ON c.post_id = p.id
+		WHERE c.id = ?`,
+		id,
+	).Scan(&authorID, &postAuthorID, &replies)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrCommentNotFound
+		}
+		return err
+	}
+
+	if authorID != userID && postAuthorID != userID {
+		return fmt.Errorf("%w: you can only delete your own comments or comments on your posts", ErrNotAuthor)
+	}
+
+	if replies > 0 {
+		now := s.now()
+		_, err = s.db.Exec("UPDATE comments SET content = '', deleted_at = ?, updated_at = ? WHERE id = ?", now, now, id)
+		return err
+	}
+
+	_, err = s.db.Exec("DELETE FROM comments WHERE id = ?", id)
+	return err
+}
+
+// GetModerationQueue retrieves a page of comments with the given status, oldest
+// first, and the total number of such comments. A postAuthorID other than 0 limits
+// the queue to comments on that user's posts.
+func (s *SQLStore) GetModerationQueue(status CommentStatus, postAuthorID, page, perPage int) ([]*Comment, int, error) {
+	where, args := " WHERE c.status = ? AND c.deleted_at IS NULL", []interface{}{status}
+	if postAuthorID != 0 {
+		where += " AND c.post_id IN (SELECT id FROM posts WHERE author_id = ?)"
+		args = append(args, postAuthorID)
+	}
+
+	var total int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM comments c"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(
+		commentSelect+where+" ORDER BY c.created_at, c.id LIMIT ? OFFSET ?",
+		append(args, perPage, (page-1)*perPage)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	comments := []*Comment{}
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		comments = append(comments, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return comments, total, nil
+}
+
+// ModerateComment records a moderator's decision on a comment.
+// Permission checks are up to the caller.
+func (s *SQLStore) ModerateComment(id int, status CommentStatus, moderatorID int) (*Comment, error) {
+	if !status.Valid() || status == CommentPending {
+		return nil, ErrInvalidModeration
+	}
+	if _, err := s.GetComment(id); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	_, err := s.db.Exec(
+		"UPDATE comments SET status = ?, moderated_by = ?, moderated_at = ? WHERE id = ?",
+		status, moderatorID, now, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetComment(id)
+}
+
+// validateComment checks a new or edited comment
+func validateComment(input CommentInput) error {
+	if strings.TrimSpace(input.Content) == "" {
+		return ErrCommentEmpty
+	}
+	if len(input.Content) > MaxCommentLength {
+		return ErrCommentTooLong
+	}
+	if !input.Status.Valid() {
+		return ErrInvalidModeration
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+// backend/internal/models/listing.go
+package models
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned for a cursor that was not issued for the requested sort order
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// DefaultPostLimit is the page size used when a PostFilter has no Limit
+const DefaultPostLimit = 20
+
+// PostSort is the order of a post listing. Ties are broken by post ID.
+type PostSort string
+
+// Post listing orders
+const (
+	SortCreated PostSort = "created" // Newest first
+	SortUpdated PostSort = "updated" // Most recently updated first
+	SortTitle   PostSort = "title"   // Alphabetical
+)
+
+// Valid reports whether s is a known sort order
+func (s PostSort) Valid() bool {
+	switch s {
+	case SortCreated, SortUpdated, SortTitle:
+		return true
+	}
+	return false
+}
+
+// PostFilter selects a page of posts. Zero values leave a filter out.
+type PostFilter struct {
+	AuthorID   int
+	Tag        string     // In the form returned by NormalizeTags
+	CategoryID int        // Includes posts in every subcategory
+	Since      *time.Time // Created at or after
+	Until      *time.Time // Created before
+	Sort       PostSort   // Defaults to SortCreated
+	Limit      int        // Defaults to DefaultPostLimit
+	Cursor     string     // NextCursor of the previous page, empty for the first page
+	Content    bool       // Also load the HTML content of each post
+}
+
+// PostPage is a page of a post listing
+type PostPage struct {
+	Posts      []*PostSummary `json:"posts"`
+	NextCursor string         `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// postCursor is the position of the last post on a page, encoded into an
+// opaque string for clients. Only the field matching Sort is set.
+type postCursor struct {
+	Sort  PostSort   `json:"s"`
+	Time  *time.Time `json:"t,omitempty"`
+	Title string     `json:"v,omitempty"`
+	ID    int        `json:"id"`
+}
+
+// prepare fills in the defaults of a filter and decodes its cursor, which is nil on the first page
+func (f PostFilter) prepare() (PostFilter, *postCursor, error) {
+	if f.Sort == "" {
+		f.Sort = SortCreated
+	}
+	if f.Limit <= 0 {
+		f.Limit = DefaultPostLimit
+	}
+	if f.Cursor == "" {
+		return f, nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(f.Cursor)
+	if err != nil {
+		return f, nil, ErrInvalidCursor
+	}
+	var c postCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != f.Sort || (c.Sort != SortTitle && c.Time == nil) {
+		return f, nil, ErrInvalidCursor
+	}
+	return f, &c, nil
+}
+
+// cursorAfter returns the cursor pointing just after post
+func cursorAfter(post *PostSummary, sort PostSort) string {
+	c := postCursor{Sort: sort, ID: post.ID}
+	switch sort {
+	case SortTitle:
+		c.Title = post.Title
+	default:
+		t := sortTime(post, sort)
+		c.Time = &t
+	}
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// sortTime returns the timestamp a post is ordered by
+func sortTime(post *PostSummary, sort PostSort) time.Time {
+	if sort == SortUpdated {
+		return post.UpdatedAt
+	}
+	return post.CreatedAt
+}
+
+// comesBefore reports whether post a is listed before post b
+func comesBefore(a, b *PostSummary, sort PostSort) bool {
+	if sort == SortTitle {
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+		return a.ID < b.ID
+	}
+	ta, tb := sortTime(a, sort), sortTime(b, sort)
+	if !ta.Equal(tb) {
+		return ta.After(tb)
+	}
+	return a.ID > b.ID
+}
+
+// follows reports whether post is listed after the cursor
+func (c *postCursor) follows(post *PostSummary) bool {
+	last := &PostSummary{ID: c.ID, Title: c.Title}
+	if c.Time != nil {
+		last.CreatedAt, last.UpdatedAt = *c.Time, *c.Time
+	}
+	return comesBefore(last, post, c.Sort)
+}
+
+// newPostPage turns up to Limit+1 posts into a page, with a cursor if there are more
+func newPostPage(posts []*PostSummary, f PostFilter) *PostPage {
+	page := &PostPage{Posts: posts}
+	if page.Posts == nil {
+		page.Posts = []*PostSummary{}
+	}
+	if len(posts) > f.Limit {
+		page.Posts = posts[:f.Limit]
+		page.NextCursor = cursorAfter(page.Posts[f.Limit-1], f.Sort)
+	}
+	return page
+}
+
+// ListPosts retrieves a page of the posts visible to the viewer that match the filter
+func (s *SQLStore) ListPosts(filter PostFilter, viewer Viewer) (*PostPage, error) {
+	filter, cursor, err := filter.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	visible, args := visibleWhere(viewer)
+	where := []string{visible}
+
+	if filter.AuthorID != 0 {
+		where = append(where, "p.author_id = ?")
+		args = append(args, filter.AuthorID)
+	}
+	if filter.Tag != "" {
+		where = append(where, "p.id IN (SELECT pt.post_id FROM post_tags pt JOIN tags t ON t.id = pt.tag_id WHERE t.name = ?)")
+		args = append(args, filter.Tag)
+	}
+	if filter.CategoryID != 0 {
+		categories, err := s.GetCategories()
+		if err != nil {
+			return nil, err
+		}
+		if err := checkMove(categories, 0, &filter.CategoryID); err != nil {
+			return nil, err
+		}
+		ids := categoryDescendants(categories, filter.CategoryID)
+		where = append(where, "p.category_id IN (?"+strings.Repeat(", ?", len(ids)-1)+")")
+		for _, id := range ids {
+			args = append(args, id)
+		}
+	}
+	if filter.Since != nil {
+		where = append(where, "p.created_at >= ?")
+		args = append(args, s.timeArg(filter.Since))
+	}
+	if filter.Until != nil {
+		where = append(where, "p.created_at < ?")
+		args = append(args, s.timeArg(filter.Until))
+	}
+
+	// Pick up after the last post of the previous page
+	order := "p.created_at DESC, p.id DESC"
+	switch filter.Sort {
+	case SortTitle:
+		order = "p.title, p.id"
+		if cursor != nil {
+			where = append(where, "(p.title > ? OR (p.title = ? AND p.id > ?))")
+			args = append(args, cursor.Title, cursor.Title, cursor.ID)
+		}
+	case SortUpdated:
+		order = "p.updated_at DESC, p.id DESC"
+		if cursor != nil {
+			where = append(where, "(p.updated_at < ? OR (p.updated_at = ? AND p.id < ?))")
+			args = append(args, s.timeArg(cursor.Time), s.timeArg(cursor.Time), cursor.ID)
+		}
+	default:
+		if cursor != nil {
+			where = append(where, "(p.created_at < ? OR (p.created_at = ? AND p.id < ?))")
+			args = append(args, s.timeArg(cursor.Time), s.timeArg(cursor.Time), cursor.ID)
+		}
+	}
+
+	// Fetch one extra post to know whether there is another page
+	posts, err := s.querySummaries(
+		summarySelect(filter.Content)+" WHERE "+strings.Join(where, " AND ")+" GROUP BY p.id, u.username ORDER BY "+order+" LIMIT ?",
+		append(args, filter.Limit+1)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPostPage(posts, filter), nil
+}
+
+// summarySelect returns the column list of post listings, read with
+// scanSummary. It reads the start of the plain text for the excerpt and the
+// stored word count instead of the content, which is only selected when
+// asked for, and counts the approved comments in a join.
+func summarySelect(content bool) string {
+	contentColumn := "''"
+	if content {
+		contentColumn = "p.content"
+	}
+	return `
+		SELECT p.id, p.title, p.slug, SUBSTR(COALESCE(p.search_text, ''), 1, ` + strconv.Itoa(ExcerptLength+1) + `), COALESCE(p.word_count, 0),
+			p.category_id, p.author_id, u.username, p.featured_image_id, COUNT(c.id), p.status, p.published_at, p.created_at, p.updated_at, ` + contentColumn + `
+		FROM posts p
+		JOIN users u ON p.author_id = u.id
+		LEFT JOIN comments c ON c.post_id = p.id AND c.status = 'approved' AND c.deleted_at IS NULL`
+}
+
+// scanSummary reads a row selected with summarySelect
+func scanSummary(row rowScanner) (*PostSummary, error) {
+	var post PostSummary
+	var categoryID, featuredImageID sql.NullInt64
+	var publishedAt sql.NullTime
+	if err := row.Scan(
+		&post.ID, &post.Title, &post.Slug, &post.Excerpt, &post.WordCount,
+		&categoryID, &post.AuthorID, &post.Author, &featuredImageID, &post.CommentCount, &post.Status, &publishedAt, &post.CreatedAt, &post.UpdatedAt, &post.Content,
+	); err != nil {
+		return nil, err
+	}
+
+	// The start of the text is one character longer than an excerpt, which
+	// tells whether it was cut
+	post.Excerpt = excerpt(post.Excerpt, ExcerptLength)
+	post.ReadingTime = readingTime(post.WordCount)
+	if categoryID.Valid {
+		id := int(categoryID.Int64)
+		post.CategoryID = &id
+	}
+	if featuredImageID.Valid {
+		id := int(featuredImageID.Int64)
+		post.FeaturedImageID = &id
+	}
+	if publishedAt.Valid {
+		post.PublishedAt = &publishedAt.Time
+	}
+	return &post, nil
+}
+
+// querySummaries runs a query built on summarySelect and reads every row, with tags
+func (s *SQLStore) querySummaries(query string, args ...interface{}) ([]*PostSummary, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []*PostSummary
+	for rows.Next() {
+		post, err := scanSummary(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	// Close the rows before loading tags; SQLite has a single connection
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachSummaryTags(posts); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
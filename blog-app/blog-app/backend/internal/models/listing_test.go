@@ -0,0 +1,127 @@
+// backend/internal/models/listing_test.go
+package models
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestListPostsCursor(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		author := mustCreateUser(t, store, "alice")
+		for _, title := range []string{"Banana", "Apple", "Cherry", "Apple", "Date", "Banana", "Elderberry"} {
+			mustCreatePost(t, store, author, PostInput{Title: title, Content: "Fruit", Status: StatusPublished})
+		}
+		viewer := Viewer{ID: author.ID, Role: author.Role}
+
+		for _, sort := range []PostSort{SortCreated, SortUpdated, SortTitle} {
+			t.Run(string(sort), func(t *testing.T) {
+				all, err := store.ListPosts(PostFilter{Sort: sort, Limit: 100}, viewer)
+				if err != nil {
+					t.Fatalf("ListPosts() error = %v", err)
+				}
+				if len(all.Posts) != 7 || all.NextCursor != "" {
+					t.Fatalf("ListPosts() returned %d posts and cursor %q, want 7 and none", len(all.Posts), all.NextCursor)
+				}
+				for i := 1; i < len(all.Posts); i++ {
+					if !comesBefore(all.Posts[i-1], all.Posts[i], sort) {
+						t.Errorf("post %d is listed before post %d", all.Posts[i-1].ID, all.Posts[i].ID)
+					}
+				}
+
+				// Walking the pages must list every post once, in the same order
+				var walked []*PostSummary
+				filter := PostFilter{Sort: sort, Limit: 3}
+				for pages := 0; ; pages++ {
+					if pages == 3 {
+						t.Fatalf("more than 3 pages of 3 for 7 posts")
+					}
+					page, err := store.ListPosts(filter, viewer)
+					if err != nil {
+						t.Fatalf("ListPosts() error = %v", err)
+					}
+					walked = append(walked, page.Posts...)
+					if page.NextCursor == "" {
+						break
+					}
+					filter.Cursor = page.NextCursor
+				}
+				if len(walked) != len(all.Posts) {
+					t.Fatalf("pages listed %d posts, want %d", len(walked), len(all.Posts))
+				}
+				for i := range walked {
+					if walked[i].ID != all.Posts[i].ID {
+						t.Errorf("post %d on the pages is %d, want %d", i, walked[i].ID, all.Posts[i].ID)
+					}
+				}
+			})
+		}
+
+		t.Run("invalid cursor", func(t *testing.T) {
+			first, err := store.ListPosts(PostFilter{Sort: SortCreated, Limit: 1}, viewer)
+			if err != nil {
+				t.Fatalf("ListPosts() error = %v", err)
+			}
+
+			tests := []struct {
+				name   string
+				filter PostFilter
+			}{
+				{"not base64", PostFilter{Cursor: "!!!"}},
+				{"not JSON", PostFilter{Cursor: "bm90IGpzb24"}},
+				{"other sort order", PostFilter{Sort: SortTitle, Cursor: first.NextCursor}},
+			}
+			for _, tt := range tests {
+				if _, err := store.ListPosts(tt.filter, viewer); !errors.Is(err, ErrInvalidCursor) {
+					t.Errorf("%s: ListPosts() error = %v, want %v", tt.name, err, ErrInvalidCursor)
+				}
+			}
+		})
+	})
+}
+
+func TestListPostsSummaries(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		author := mustCreateUser(t, store, "alice")
+		content := "<p>" + strings.Repeat("Many <b>fruity</b> words, ", 40) + "</p>"
+		post := mustCreatePost(t, store, author, PostInput{Title: "Fruit", Content: content, Tags: []string{"food", "apple"}, Status: StatusPublished})
+		for _, status := range []CommentStatus{CommentApproved, CommentApproved, CommentPending} {
+			if _, err := store.CreateComment(post.ID, CommentInput{Content: "Nice", Status: status}, author.ID); err != nil {
+				t.Fatalf("failed to create comment: %v", err)
+			}
+		}
+		viewer := Viewer{ID: author.ID, Role: author.Role}
+
+		// Listings match the summary of the full post, without the content
+		page, err := store.ListPosts(PostFilter{}, viewer)
+		if err != nil {
+			t.Fatalf("ListPosts() error = %v", err)
+		}
+		if len(page.Posts) != 1 {
+			t.Fatalf("ListPosts() returned %d posts, want 1", len(page.Posts))
+		}
+		full, err := store.GetPostByID(post.ID)
+		if err != nil {
+			t.Fatalf("GetPostByID() error = %v", err)
+		}
+		want := Summarize(full)
+		want.Content = ""
+		if got := page.Posts[0]; !reflect.DeepEqual(got, want) {
+			t.Errorf("ListPosts() summary =\n%+v\nwant\n%+v", got, want)
+		}
+		if want.CommentCount != 2 || !strings.HasSuffix(want.Excerpt, "…") {
+			t.Errorf("summary has %d comments and excerpt %q, want 2 and a cut excerpt", want.CommentCount, want.Excerpt)
+		}
+
+		// The content is loaded when asked for
+		page, err = store.ListPosts(PostFilter{Content: true}, viewer)
+		if err != nil {
+			t.Fatalf("ListPosts() error = %v", err)
+		}
+		if page.Posts[0].Content != content {
+			t.Errorf("ListPosts() content = %q, want the post's content", page.Posts[0].Content)
+		}
+	})
+}
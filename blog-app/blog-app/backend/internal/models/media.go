@@ -0,0 +1,191 @@
+// backend/internal/models/media.go
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrMediaNotFound is returned when a media item does not exist
+var ErrMediaNotFound = errors.New("media not found")
+
+// Media is an uploaded image and the renditions generated from it
+type Media struct {
+	ID        int             `json:"id"`
+	OwnerID   int             `json:"owner_id"`
+	Filename  string          `json:"filename"` // Name of the uploaded file
+	URL       string          `json:"url"`      // Address of the original, filled in by handlers
+	Variants  []*MediaVariant `json:"variants"` // Smallest first, including the original
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// MediaVariant is one stored rendition of a media item, such as "original" or "thumb"
+type MediaVariant struct {
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Size        int64  `json:"size"`
+	StorageKey  string `json:"-"`
+	URL         string `json:"url"` // Filled in by handlers
+}
+
+// Variant returns the rendition with the given name, or nil if it was not generated
+func (m *Media) Variant(name string) *MediaVariant {
+	for _, v := range m.Variants {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// sortVariants orders renditions from smallest to largest
+func sortVariants(variants []*MediaVariant) {
+	sort.SliceStable(variants, func(i, j int) bool {
+		if variants[i].Width != variants[j].Width {
+			return variants[i].Width < variants[j].Width
+		}
+		return variants[i].Name < variants[j].Name
+	})
+}
+
+// mediaSelect is the column list shared by every media query, with one row per
+// rendition, read with queryMedia
+const mediaSelect = `
+		SELECT m.id, m.owner_id, m.filename, m.created_at,
+			v.name, v.content_type, v.width, v.height, v.size, v.storage_key
+		FROM media m
+		JOIN media_variants v ON v.media_id = m.id`
+
+// queryMedia runs a query selecting mediaSelect and groups the renditions by
+// media item, keeping the order of the items
+func (s *SQLStore) queryMedia(query string, args ...interface{}) ([]*Media, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*Media{}
+	byID := make(map[int]*Media)
+	for rows.Next() {
+		var m Media
+		var v MediaVariant
+		if err := rows.Scan(
+			&m.ID, &m.OwnerID, &m.Filename, &m.CreatedAt,
+			&v.Name, &v.ContentType, &v.Width, &v.Height, &v.Size, &v.StorageKey,
+		); err != nil {
+			return nil, err
+		}
+
+		item, ok := byID[m.ID]
+		if !ok {
+			item = &m
+			byID[m.ID] = item
+			items = append(items, item)
+		}
+		item.Variants = append(item.Variants, &v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, item := range items {
+		sortVariants(item.Variants)
+	}
+	return items, nil
+}
+
+// CreateMedia records an uploaded image whose renditions have been stored
+func (s *SQLStore) CreateMedia(ownerID int, filename string, variants []*MediaVariant) (*Media, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	id, err := tx.Insert(
+		"INSERT INTO media (owner_id, filename, created_at) VALUES (?, ?, ?)",
+		ownerID, filename, s.now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range variants {
+		_, err := tx.Exec(
+			"INSERT INTO media_variants (media_id, name, content_type, width, height, size, storage_key) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			id, v.Name, v.ContentType, v.Width, v.Height, v.Size, v.StorageKey,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.GetMedia(int(id))
+}
+
+// GetMedia retrieves a media item with its renditions
+func (s *SQLStore) GetMedia(id int) (*Media, error) {
+	items, err := s.queryMedia(mediaSelect+`
+		WHERE m.id = ?`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, ErrMediaNotFound
+	}
+	return items[0], nil
+}
+
+// mediaExists reports whether a media item exists
+func (s *SQLStore) mediaExists(id int) (bool, error) {
+	var exists bool
+	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM media WHERE id = ?)", id).Scan(&exists)
+	return exists, err
+}
+
+// GetMediaByOwner retrieves the media uploaded by a user, newest first
+func (s *SQLStore) GetMediaByOwner(ownerID int) ([]*Media, error) {
+	return s.queryMedia(mediaSelect+`
+		WHERE m.owner_id = ?
+		ORDER BY m.created_at DESC, m.id DESC`,
+		ownerID,
+	)
+}
+
+// DeleteMedia deletes a media item and its renditions. Only the owner can
+// delete it; the stored files are left to the caller.
+func (s *SQLStore) DeleteMedia(id, userID int) error {
+	var ownerID int
+	err := s.db.QueryRow("SELECT owner_id FROM media WHERE id = ?", id).Scan(&ownerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrMediaNotFound
+		}
+		return err
+	}
+
+	if ownerID != userID {
+		return fmt.Errorf("%w: you can only delete your own media", ErrNotAuthor)
+	}
+
+	// Take it off the posts featuring it; SQLite has no foreign key to do it
+	if _, err := s.db.Exec("UPDATE posts SET featured_image_id = NULL WHERE featured_image_id = ?", id); err != nil {
+		return err
+	}
+
+	// The renditions are deleted along with the media
+	_, err = s.db.Exec("DELETE FROM media WHERE id = ?", id)
+	return err
+}
@@ -0,0 +1,2281 @@
+// backend/internal/models/memory.go
+package models
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"blog-app/internal/search"
+)
+
+// MemoryStore implements Store entirely in memory.
+// It is intended for local development and tests where no database is available.
+type MemoryStore struct {
+	mu         sync.RWMutex
+	users      map[int]*User
+	posts      map[int]*Post
+	revisions  map[int][]*PostRevision // keyed by post ID, oldest first
+	oldSlugs   map[int][]string        // keyed by post ID, oldest first
+	tagIDs     map[string]int          // keyed by tag name
+	categories map[int]*Category
+	comments   map[int]*Comment
+	media      map[int]*Media
+	websites   map[int]*Website
+	pages      map[int]*Page
+	items      map[int]*Item
+	members    map[memberKey]*WebsiteMember
+	invites    map[int]*WebsiteInvite
+	refresh    map[string]*RefreshToken // keyed by token hash
+	revoked    map[string]time.Time     // expiry of revoked access tokens, keyed by token ID
+	personal   map[int]*PersonalToken
+	index      *search.Index
+	nextUserID int
+	nextPostID int
+	nextRevID  int
+	nextTagID  int
+	nextCatID  int
+	nextCmtID  int
+	nextMedID  int
+	nextWebID  int
+	nextPageID int
+	nextItemID int
+	nextPatID  int
+	nextInvID  int
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		users:      make(map[int]*User),
+		posts:      make(map[int]*Post),
+		revisions:  make(map[int][]*PostRevision),
+		oldSlugs:   make(map[int][]string),
+		tagIDs:     make(map[string]int),
+		categories: make(map[int]*Category),
+		comments:   make(map[int]*Comment),
+		media:      make(map[int]*Media),
+		websites:   make(map[int]*Website),
+		pages:      make(map[int]*Page),
+		items:      make(map[int]*Item),
+		members:    make(map[memberKey]*WebsiteMember),
+		invites:    make(map[int]*WebsiteInvite),
+		refresh:    make(map[string]*RefreshToken),
+		revoked:    make(map[string]time.Time),
+		personal:   make(map[int]*PersonalToken),
+		index:      search.NewIndex(),
+		nextUserID: 1,
+		nextPostID: 1,
+		nextRevID:  1,
+		nextTagID:  1,
+		nextCatID:  1,
+		nextCmtID:  1,
+		nextMedID:  1,
+		nextWebID:  1,
+		nextPageID: 1,
+		nextItemID: 1,
+		nextPatID:  1,
+		nextInvID:  1,
+	}
+}
+
+// CreateUser creates a new user in memory
+func (m *MemoryStore) CreateUser(username, email, password string) (*User, error) {
+	// Hash the password
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Check if username or email already exists
+	for _, u := range m.users {
+		if u.Username == username || u.Email == email {
+			return nil, ErrUserExists
+		}
+	}
+
+	// The first user becomes an admin, so that someone can manage the others
+	role := DefaultRole
+	if len(m.users) == 0 {
+		role = RoleAdmin
+	}
+
+	now := time.Now()
+	user := &User{
+		ID:        m.nextUserID,
+		Username:  username,
+		Email:     email,
+		Password:  string(hashedPassword),
+		Role:      role,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.users[user.ID] = user
+	m.nextUserID++
+
+	copied := *user
+	return &copied, nil
+}
+
+// GetUserByID retrieves a user by ID
+func (m *MemoryStore) GetUserByID(id int) (*User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+// GetUserByEmail retrieves a user by email
+func (m *MemoryStore) GetUserByEmail(email string) (*User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// GetUsers retrieves all users ordered by ID
+func (m *MemoryStore) GetUsers() ([]*UserResponse, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var users []*UserResponse
+	for _, user := range m.users {
+		users = append(users, user.ToResponse())
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
+	return users, nil
+}
+
+// SetUserRole changes a user's role and logs out their sessions. The last
+// admin cannot be demoted.
+func (m *MemoryStore) SetUserRole(id int, role Role) (*User, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidUserRole
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return nil, ErrUserNotFound
+	}
+	if user.Role == role {
+		copied := *user
+		return &copied, nil
+	}
+	if user.Role == RoleAdmin && !m.hasOtherAdmin(id) {
+		return nil, ErrLastAdmin
+	}
+	now := time.Now()
+	user.Role = role
+	user.UpdatedAt = now
+
+	// Log out the user's sessions
+	for _, t := range m.refresh {
+		if t.UserID == id && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+
+	copied := *user
+	return &copied, nil
+}
+
+// hasOtherAdmin reports whether a user other than id is an admin.
+// The caller must hold the lock.
+func (m *MemoryStore) hasOtherAdmin(id int) bool {
+	for _, u := range m.users {
+		if u.Role == RoleAdmin && u.ID != id {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPostWriter returns an ErrNotAuthor error unless the user wrote the post
+// or their role allows perm on the posts of others. The caller must hold the lock.
+func (m *MemoryStore) checkPostWriter(authorID, userID int, perm Permission, action string) error {
+	if authorID == userID {
+		return nil
+	}
+	if user, ok := m.users[userID]; ok && user.Role.Can(perm) {
+		return nil
+	}
+	return fmt.Errorf("%w: you can only %s your own posts", ErrNotAuthor, action)
+}
+
+// DeleteUser deletes a user and all of their posts on behalf of userID. Users
+// may delete themselves; deleting anyone else needs the users:manage
+// permission. The last admin cannot be deleted.
+func (m *MemoryStore) DeleteUser(id, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if id != userID {
+		if asking, ok := m.users[userID]; !ok || !asking.Role.Can(PermManageUsers) {
+			return fmt.Errorf("%w: you can only delete your own account", ErrNotAuthor)
+		}
+	}
+	user, ok := m.users[id]
+	if !ok {
+		return ErrUserNotFound
+	}
+	if user.Role == RoleAdmin && !m.hasOtherAdmin(id) {
+		return ErrLastAdmin
+	}
+
+	// Websites outlive their creator, but not their last owner
+	for key, member := range m.members {
+		if key.userID == id && member.Role == WebsiteOwner && !m.hasOtherOwner(key.websiteID, id) {
+			return ErrSoleOwner
+		}
+	}
+
+	for postID, post := range m.posts {
+		if post.AuthorID == id {
+			delete(m.posts, postID)
+			delete(m.revisions, postID)
+			delete(m.oldSlugs, postID)
+			m.deletePostComments(postID)
+			m.index.Remove(postID)
+		}
+	}
+	delete(m.users, id)
+
+	// Their media goes with them, and out of the posts featuring it
+	for mediaID, item := range m.media {
+		if item.OwnerID == id {
+			delete(m.media, mediaID)
+			m.forgetMedia(mediaID)
+		}
+	}
+
+	// They leave their websites, which forget who created them
+	for _, w := range m.websites {
+		if w.OwnerID == id {
+			w.OwnerID = 0
+		}
+	}
+	for key := range m.members {
+		if key.userID == id {
+			delete(m.members, key)
+		}
+	}
+	for _, invite := range m.invites {
+		if invite.InvitedBy == id {
+			invite.InvitedBy = 0
+		}
+	}
+
+	// Their sessions and personal access tokens end
+	for hash, t := range m.refresh {
+		if t.UserID == id {
+			delete(m.refresh, hash)
+		}
+	}
+	for tokenID, t := range m.personal {
+		if t.UserID == id {
+			delete(m.personal, tokenID)
+		}
+	}
+
+	// Comments they made or moderated on other posts lose their author or moderator
+	for _, c := range m.comments {
+		if c.AuthorID == id {
+			c.AuthorID = 0
+		}
+		if c.ModeratedBy == id {
+			c.ModeratedBy = 0
+		}
+	}
+
+	// Revisions they made on other posts lose their editor
+	for _, revisions := range m.revisions {
+		for _, rev := range revisions {
+			if rev.EditorID == id {
+				rev.EditorID = 0
+			}
+		}
+	}
+
+	return nil
+}
+
+// CreatePost creates a new post in memory
+func (m *MemoryStore) CreatePost(input PostInput, authorID int) (*Post, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Validate that the author exists
+	if _, ok := m.users[authorID]; !ok {
+		return nil, ErrNoAuthor
+	}
+
+	// New posts start as drafts unless published straight away
+	now := time.Now()
+	status := StatusDraft
+	var publishedAt *time.Time
+	if target := input.targetStatus(); target != "" {
+		var err error
+		status = target
+		publishedAt, err = transition(StatusDraft, nil, status, input.PublishAt, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Clean up the tags and metadata, and check the category and featured image
+	tags, err := NormalizeTags(input.Tags)
+	if err != nil {
+		return nil, err
+	}
+	seo, err := input.seo(PostSEO{})
+	if err != nil {
+		return nil, err
+	}
+	if err := m.checkCategory(input.CategoryID); err != nil {
+		return nil, err
+	}
+	if err := m.checkFeaturedImage(input.FeaturedImageID); err != nil {
+		return nil, err
+	}
+
+	// Pick a unique slug
+	slug, err := m.resolveSlug(input.Slug, input.Title, 0)
+	if err != nil {
+		return nil, err
+	}
+	m.useTags(tags)
+
+	post := &Post{
+		ID:              m.nextPostID,
+		Title:           input.Title,
+		Slug:            slug,
+		Content:         input.Content,
+		CategoryID:      optionalID(input.CategoryID),
+		Tags:            tags,
+		AuthorID:        authorID,
+		Status:          status,
+		PublishedAt:     publishedAt,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+		SEO:             seo,
+		FeaturedImageID: optionalID(input.FeaturedImageID),
+	}
+	m.posts[post.ID] = post
+	m.nextPostID++
+	m.addRevision(post, authorID)
+	m.index.Add(post.ID, post.Title, search.StripHTML(post.Content))
+
+	return m.postView(post), nil
+}
+
+// GetPostByID retrieves a post by ID, whatever its status
+func (m *MemoryStore) GetPostByID(id int) (*Post, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	post, ok := m.posts[id]
+	if !ok {
+		return nil, ErrPostNotFound
+	}
+	return m.postView(post), nil
+}
+
+// GetPosts retrieves all posts visible to the viewer, newest first
+func (m *MemoryStore) GetPosts(viewer Viewer) ([]*Post, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.listPosts(func(p *Post) bool { return p.VisibleTo(viewer) }), nil
+}
+
+// ListPosts retrieves a page of the posts visible to the viewer that match the filter
+func (m *MemoryStore) ListPosts(filter PostFilter, viewer Viewer) (*PostPage, error) {
+	filter, cursor, err := filter.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var inCategory map[int]bool
+	if filter.CategoryID != 0 {
+		if _, ok := m.categories[filter.CategoryID]; !ok {
+			return nil, ErrCategoryNotFound
+		}
+		inCategory = make(map[int]bool)
+		for _, id := range categoryDescendants(m.categoryList(), filter.CategoryID) {
+			inCategory[id] = true
+		}
+	}
+
+	var posts []*PostSummary
+	for _, p := range m.listPosts(func(p *Post) bool {
+		switch {
+		case !p.VisibleTo(viewer),
+			filter.AuthorID != 0 && p.AuthorID != filter.AuthorID,
+			filter.Tag != "" && !hasTag(p, filter.Tag),
+			inCategory != nil && (p.CategoryID == nil || !inCategory[*p.CategoryID]),
+			filter.Since != nil && p.CreatedAt.Before(*filter.Since),
+			filter.Until != nil && !p.CreatedAt.Before(*filter.Until):
+			return false
+		}
+		return true
+	}) {
+		summary := Summarize(p)
+		if !filter.Content {
+			summary.Content = ""
+		}
+		if cursor == nil || cursor.follows(summary) {
+			posts = append(posts, summary)
+		}
+	}
+	sort.SliceStable(posts, func(i, j int) bool { return comesBefore(posts[i], posts[j], filter.Sort) })
+
+	if len(posts) > filter.Limit+1 {
+		posts = posts[:filter.Limit+1]
+	}
+	return newPostPage(posts, filter), nil
+}
+
+// GetPostsByAuthor retrieves all posts by a specific author that are visible to the viewer, newest first
+func (m *MemoryStore) GetPostsByAuthor(authorID int, viewer Viewer) ([]*Post, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.listPosts(func(p *Post) bool { return p.AuthorID == authorID && p.VisibleTo(viewer) }), nil
+}
+
+// UpdatePost updates an existing post
+func (m *MemoryStore) UpdatePost(id int, input PostInput, userID int) (*Post, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	post, ok := m.posts[id]
+	if !ok {
+		return nil, ErrPostNotFound
+	}
+
+	// Only the author or an editor can update the post
+	if err := m.checkPostWriter(post.AuthorID, userID, PermEditAnyPost, "update"); err != nil {
+		return nil, err
+	}
+
+	// Validate everything before changing the post
+	var err error
+	tags := post.Tags
+	if input.Tags != nil {
+		tags, err = NormalizeTags(input.Tags)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	categoryID := post.CategoryID
+	if input.CategoryID != nil {
+		if err := m.checkCategory(input.CategoryID); err != nil {
+			return nil, err
+		}
+		categoryID = optionalID(input.CategoryID)
+	}
+
+	featuredImageID := post.FeaturedImageID
+	if input.FeaturedImageID != nil {
+		if err := m.checkFeaturedImage(input.FeaturedImageID); err != nil {
+			return nil, err
+		}
+		featuredImageID = optionalID(input.FeaturedImageID)
+	}
+	seo, err := input.seo(post.SEO)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply a status change if one was requested
+	now := time.Now()
+	status, publishedAt := post.Status, post.PublishedAt
+	if target := input.targetStatus(); target != "" && (target != post.Status || input.PublishAt != nil) {
+		publishedAt, err = transition(post.Status, post.PublishedAt, target, input.PublishAt, now)
+		if err != nil {
+			return nil, err
+		}
+		status = target
+	}
+
+	// Move to the new slug, keeping the old one for redirects
+	if input.Slug != "" && input.Slug != post.Slug {
+		slug, err := m.resolveSlug(input.Slug, input.Title, id)
+		if err != nil {
+			return nil, err
+		}
+		m.changeSlug(post, slug)
+	}
+
+	m.useTags(tags)
+	post.Status = status
+	post.PublishedAt = publishedAt
+	post.Tags = tags
+	post.CategoryID = categoryID
+	post.FeaturedImageID = featuredImageID
+	post.SEO = seo
+	post.Title = input.Title
+	post.Content = input.Content
+	post.UpdatedAt = now
+	m.addRevision(post, userID)
+	m.index.Add(post.ID, post.Title, search.StripHTML(post.Content))
+
+	return m.postView(post), nil
+}
+
+// GetPostBySlug retrieves a post by its current slug or by one it used to have
+func (m *MemoryStore) GetPostBySlug(slug string) (*Post, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, post := range m.posts {
+		if post.Slug == slug {
+			return m.postView(post), nil
+		}
+	}
+
+	// Fall back to the slug history
+	for postID, slugs := range m.oldSlugs {
+		for _, old := range slugs {
+			if old == slug {
+				return m.postView(m.posts[postID]), nil
+			}
+		}
+	}
+	return nil, ErrPostNotFound
+}
+
+// GetPostSlugHistory retrieves the previous slugs of a post, newest first
+func (m *MemoryStore) GetPostSlugHistory(postID int) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored := m.oldSlugs[postID]
+	slugs := make([]string, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		slugs = append(slugs, stored[i])
+	}
+	return slugs, nil
+}
+
+// GetTags retrieves every tag used by a post visible to the viewer, with post counts
+func (m *MemoryStore) GetTags(viewer Viewer) ([]*Tag, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, post := range m.posts {
+		if !post.VisibleTo(viewer) {
+			continue
+		}
+		for _, tag := range post.Tags {
+			counts[tag]++
+		}
+	}
+
+	var tags []*Tag
+	for name, count := range counts {
+		tags = append(tags, &Tag{ID: m.tagIDs[name], Name: name, PostCount: count})
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	return tags, nil
+}
+
+// SetPostStatus moves a post to a new lifecycle state
+func (m *MemoryStore) SetPostStatus(id int, status PostStatus, publishAt *time.Time, userID int) (*Post, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	post, ok := m.posts[id]
+	if !ok {
+		return nil, ErrPostNotFound
+	}
+
+	// Only the author or an editor can change the status of the post
+	if err := m.checkPostWriter(post.AuthorID, userID, PermEditAnyPost, "change the status of"); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	publishedAt, err := transition(post.Status, post.PublishedAt, status, publishAt, now)
+	if err != nil {
+		return nil, err
+	}
+	post.Status = status
+	post.PublishedAt = publishedAt
+	post.UpdatedAt = now
+
+	return m.postView(post), nil
+}
+
+// PublishDuePosts publishes every scheduled post whose publication time has passed
+func (m *MemoryStore) PublishDuePosts(now time.Time) ([]*Post, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var published []*Post
+	for _, post := range m.posts {
+		if post.Status != StatusScheduled || post.PublishedAt == nil || post.PublishedAt.After(now) {
+			continue
+		}
+		post.Status = StatusPublished
+		post.UpdatedAt = now
+		published = append(published, m.postView(post))
+	}
+
+	return published, nil
+}
+
+// DeletePost deletes a post
+func (m *MemoryStore) DeletePost(id, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	post, ok := m.posts[id]
+	if !ok {
+		return ErrPostNotFound
+	}
+
+	// Only the author or an admin can delete the post
+	if err := m.checkPostWriter(post.AuthorID, userID, PermDeleteAnyPost, "delete"); err != nil {
+		return err
+	}
+
+	delete(m.posts, id)
+	delete(m.revisions, id)
+	delete(m.oldSlugs, id)
+	m.deletePostComments(id)
+	m.index.Remove(id)
+	return nil
+}
+
+// GetPostRevisions retrieves every revision of a post, newest first
+func (m *MemoryStore) GetPostRevisions(postID int) ([]*PostRevision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.posts[postID]; !ok {
+		return nil, ErrPostNotFound
+	}
+
+	stored := m.revisions[postID]
+	revisions := make([]*PostRevision, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		revisions = append(revisions, m.revisionView(stored[i]))
+	}
+	return revisions, nil
+}
+
+// GetPostRevision retrieves a single revision of a post
+func (m *MemoryStore) GetPostRevision(postID, revision int) (*PostRevision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, rev := range m.revisions[postID] {
+		if rev.Revision == revision {
+			return m.revisionView(rev), nil
+		}
+	}
+	return nil, ErrRevisionNotFound
+}
+
+// slugTaken reports whether a slug is used, now or in the past, by a post other than postID.
+// The caller must hold the lock.
+func (m *MemoryStore) slugTaken(slug string, postID int) bool {
+	for _, post := range m.posts {
+		if post.Slug == slug && post.ID != postID {
+			return true
+		}
+	}
+	for id, slugs := range m.oldSlugs {
+		for _, old := range slugs {
+			if old == slug && id != postID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// resolveSlug works out the slug to store for a post, as SQLStore does.
+// The caller must hold the lock.
+func (m *MemoryStore) resolveSlug(requested, title string, postID int) (string, error) {
+	taken := func(slug string) (bool, error) { return m.slugTaken(slug, postID), nil }
+
+	if requested == "" {
+		return uniqueSlug(Slugify(title), taken)
+	}
+	if !ValidSlug(requested) {
+		return "", ErrInvalidSlug
+	}
+	if m.slugTaken(requested, postID) {
+		return "", ErrSlugTaken
+	}
+	return requested, nil
+}
+
+// changeSlug moves a post to a new slug, keeping the old one in its history.
+// The caller must hold the write lock.
+func (m *MemoryStore) changeSlug(post *Post, slug string) {
+	var history []string
+	for _, old := range m.oldSlugs[post.ID] {
+		if old != slug {
+			history = append(history, old)
+		}
+	}
+	m.oldSlugs[post.ID] = append(history, post.Slug)
+	post.Slug = slug
+}
+
+// useTags gives IDs to tags seen for the first time.
+// The caller must hold the write lock.
+func (m *MemoryStore) useTags(tags []string) {
+	for _, tag := range tags {
+		if _, ok := m.tagIDs[tag]; !ok {
+			m.tagIDs[tag] = m.nextTagID
+			m.nextTagID++
+		}
+	}
+}
+
+// GetCategories retrieves every category as a flat list
+func (m *MemoryStore) GetCategories() ([]*Category, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var categories []*Category
+	for _, c := range m.categoryList() {
+		categories = append(categories, categoryView(c))
+	}
+	return categories, nil
+}
+
+// GetCategory retrieves a category by ID
+func (m *MemoryStore) GetCategory(id int) (*Category, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, ok := m.categories[id]
+	if !ok {
+		return nil, ErrCategoryNotFound
+	}
+	return categoryView(c), nil
+}
+
+// CreateCategory creates a category as the last child of parentID, or at the top level if nil
+func (m *MemoryStore) CreateCategory(name string, parentID *int) (*Category, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	categories := m.categoryList()
+	if err := checkMove(categories, 0, parentID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	c := &Category{
+		ID:        m.nextCatID,
+		Name:      name,
+		ParentID:  optionalID(parentID),
+		Position:  len(siblingsOf(categories, parentID, 0)),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.categories[c.ID] = c
+	m.nextCatID++
+
+	return categoryView(c), nil
+}
+
+// UpdateCategory renames a category
+func (m *MemoryStore) UpdateCategory(id int, name string) (*Category, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.categories[id]
+	if !ok {
+		return nil, ErrCategoryNotFound
+	}
+	c.Name = name
+	c.UpdatedAt = time.Now()
+
+	return categoryView(c), nil
+}
+
+// MoveCategory moves a category under a new parent at the given position among its new siblings
+func (m *MemoryStore) MoveCategory(id int, parentID *int, position int) (*Category, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.categories[id]
+	if !ok {
+		return nil, ErrCategoryNotFound
+	}
+	categories := m.categoryList()
+	if err := checkMove(categories, id, parentID); err != nil {
+		return nil, err
+	}
+
+	// Close the gap left behind, then make room among the new siblings
+	if !sameParent(c.ParentID, parentID) {
+		for i, siblingID := range siblingsOf(categories, c.ParentID, id) {
+			m.categories[siblingID].Position = i
+		}
+	}
+	for i, siblingID := range insertAt(siblingsOf(categories, parentID, id), id, position) {
+		m.categories[siblingID].Position = i
+	}
+	c.ParentID = optionalID(parentID)
+	c.UpdatedAt = time.Now()
+
+	return categoryView(c), nil
+}
+
+// DeleteCategory deletes a category without subcategories; its posts become uncategorized
+func (m *MemoryStore) DeleteCategory(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.categories[id]
+	if !ok {
+		return ErrCategoryNotFound
+	}
+	for _, other := range m.categories {
+		if other.ParentID != nil && *other.ParentID == id {
+			return ErrCategoryHasChildren
+		}
+	}
+
+	for _, post := range m.posts {
+		if post.CategoryID != nil && *post.CategoryID == id {
+			post.CategoryID = nil
+		}
+	}
+	siblings := siblingsOf(m.categoryList(), c.ParentID, id)
+	delete(m.categories, id)
+
+	// Close the gap among the remaining siblings
+	for i, siblingID := range siblings {
+		m.categories[siblingID].Position = i
+	}
+
+	return nil
+}
+
+// GetComments retrieves a page of top-level comments on a post, oldest first,
+// each with its full tree of replies, and the total number of top-level comments
+func (m *MemoryStore) GetComments(postID, viewerID int, moderator bool, page, perPage int) ([]*Comment, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var comments []*Comment
+	for _, c := range m.comments {
+		if c.PostID == postID && (moderator || c.visibleTo(viewerID)) {
+			comments = append(comments, m.commentView(c))
+		}
+	}
+
+	threads, total := buildCommentThreads(comments, page, perPage)
+	return threads, total, nil
+}
+
+// GetComment retrieves a single comment, without its replies
+func (m *MemoryStore) GetComment(id int) (*Comment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	c, ok := m.comments[id]
+	if !ok {
+		return nil, ErrCommentNotFound
+	}
+	return m.commentView(c), nil
+}
+
+// CreateComment adds a comment to a post, as a reply to input.ParentID if it is not nil
+func (m *MemoryStore) CreateComment(postID int, input CommentInput, authorID int) (*Comment, error) {
+	if err := validateComment(input); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Replies must stay within the same post, under a comment the author can see
+	if input.ParentID != nil {
+		parent, ok := m.comments[*input.ParentID]
+		if !ok || parent.PostID != postID || !parent.visibleTo(authorID) {
+			return nil, ErrInvalidParent
+		}
+	}
+
+	now := time.Now()
+	c := &Comment{
+		ID:        m.nextCmtID,
+		PostID:    postID,
+		ParentID:  optionalID(input.ParentID),
+		AuthorID:  authorID,
+		Content:   input.Content,
+		Status:    input.Status,
+		SpamScore: input.SpamScore,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.comments[c.ID] = c
+	m.nextCmtID++
+
+	return m.commentView(c), nil
+}
+
+// UpdateComment edits the content of a comment, sending it back through moderation
+func (m *MemoryStore) UpdateComment(id int, input CommentInput, userID int) (*Comment, error) {
+	if err := validateComment(input); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.comments[id]
+	if !ok {
+		return nil, ErrCommentNotFound
+	}
+
+	// Only the author can edit their own comment
+	if c.AuthorID != userID {
+		return nil, fmt.Errorf("%w: you can only edit your own comments", ErrNotAuthor)
+	}
+	if c.Deleted {
+		return nil, ErrCommentDeleted
+	}
+
+	c.Content = input.Content
+	c.Status = input.Status
+	c.SpamScore = input.SpamScore
+	c.UpdatedAt = time.Now()
+
+	return m.commentView(c), nil
+}
+
+// DeleteComment deletes a comment, blanking it out instead if it has replies
+func (m *MemoryStore) DeleteComment(id, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.comments[id]
+	if !ok {
+		return ErrCommentNotFound
+	}
+
+	if c.AuthorID != userID && m.posts[c.PostID].AuthorID != userID {
+		return fmt.Errorf("%w: you can only delete your own comments or comments on your posts", ErrNotAuthor)
+	}
+
+	for _, other := range m.comments {
+		if other.ParentID != nil && *other.ParentID == id {
+			c.Content = ""
+			c.Deleted = true
+			c.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+
+	delete(m.comments, id)
+	return nil
+}
+
+// GetModerationQueue retrieves a page of comments with the given status, oldest first
+func (m *MemoryStore) GetModerationQueue(status CommentStatus, postAuthorID, page, perPage int) ([]*Comment, int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var comments []*Comment
+	for _, c := range m.comments {
+		if c.Status != status || c.Deleted {
+			continue
+		}
+		if postAuthorID != 0 && m.posts[c.PostID].AuthorID != postAuthorID {
+			continue
+		}
+		comments = append(comments, m.commentView(c))
+	}
+	sort.Slice(comments, func(i, j int) bool {
+		if comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].ID < comments[j].ID
+		}
+		return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+	})
+
+	total := len(comments)
+	start := (page - 1) * perPage
+	if start >= total {
+		return []*Comment{}, total, nil
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+	return comments[start:end], total, nil
+}
+
+// ModerateComment records a moderator's decision on a comment
+func (m *MemoryStore) ModerateComment(id int, status CommentStatus, moderatorID int) (*Comment, error) {
+	if !status.Valid() || status == CommentPending {
+		return nil, ErrInvalidModeration
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.comments[id]
+	if !ok {
+		return nil, ErrCommentNotFound
+	}
+
+	now := time.Now()
+	c.Status = status
+	c.ModeratedBy = moderatorID
+	c.ModeratedAt = &now
+
+	return m.commentView(c), nil
+}
+
+// SearchPosts retrieves a page of posts visible to the viewer that match the
+// query, best match first, and the total number of matches
+func (m *MemoryStore) SearchPosts(query string, viewer Viewer, page, perPage int) ([]*SearchResult, int, error) {
+	terms := search.Tokenize(query)
+	if len(terms) == 0 {
+		return nil, 0, ErrEmptyQuery
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var hits []search.Hit
+	for _, hit := range m.index.Search(query) {
+		if post, ok := m.posts[hit.ID]; ok && post.VisibleTo(viewer) {
+			hits = append(hits, hit)
+		}
+	}
+
+	hits, total := pageOfHits(hits, page, perPage)
+	results := []*SearchResult{}
+	for _, hit := range hits {
+		results = append(results, newSearchResult(m.postView(m.posts[hit.ID]), hit.Score, terms))
+	}
+
+	return results, total, nil
+}
+
+// CreateMedia records an uploaded image whose renditions have been stored
+func (m *MemoryStore) CreateMedia(ownerID int, filename string, variants []*MediaVariant) (*Media, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[ownerID]; !ok {
+		return nil, ErrUserNotFound
+	}
+
+	item := &Media{
+		ID:        m.nextMedID,
+		OwnerID:   ownerID,
+		Filename:  filename,
+		CreatedAt: time.Now(),
+	}
+	for _, v := range variants {
+		copied := *v
+		item.Variants = append(item.Variants, &copied)
+	}
+	sortVariants(item.Variants)
+	m.media[item.ID] = item
+	m.nextMedID++
+
+	return mediaView(item), nil
+}
+
+// GetMedia retrieves a media item with its renditions
+func (m *MemoryStore) GetMedia(id int) (*Media, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	item, ok := m.media[id]
+	if !ok {
+		return nil, ErrMediaNotFound
+	}
+	return mediaView(item), nil
+}
+
+// GetMediaByOwner retrieves the media uploaded by a user, newest first
+func (m *MemoryStore) GetMediaByOwner(ownerID int) ([]*Media, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	items := []*Media{}
+	for _, item := range m.media {
+		if item.OwnerID == ownerID {
+			items = append(items, mediaView(item))
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].CreatedAt.Equal(items[j].CreatedAt) {
+			return items[i].ID > items[j].ID
+		}
+		return items[i].CreatedAt.After(items[j].CreatedAt)
+	})
+
+	return items, nil
+}
+
+// DeleteMedia deletes a media item. Only the owner can delete it.
+func (m *MemoryStore) DeleteMedia(id, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.media[id]
+	if !ok {
+		return ErrMediaNotFound
+	}
+	if item.OwnerID != userID {
+		return fmt.Errorf("%w: you can only delete your own media", ErrNotAuthor)
+	}
+
+	delete(m.media, id)
+	m.forgetMedia(id)
+	return nil
+}
+
+// forgetMedia removes a deleted media item from the posts featuring it
+// and the items showing it. The caller must hold the write lock.
+func (m *MemoryStore) forgetMedia(mediaID int) {
+	for _, post := range m.posts {
+		if post.FeaturedImageID != nil && *post.FeaturedImageID == mediaID {
+			post.FeaturedImageID = nil
+		}
+	}
+	for _, item := range m.items {
+		if item.ImageID != nil && *item.ImageID == mediaID {
+			item.ImageID = nil
+		}
+	}
+}
+
+// mediaView returns a copy of a media item and its renditions
+func mediaView(item *Media) *Media {
+	copied := *item
+	copied.Variants = make([]*MediaVariant, len(item.Variants))
+	for i, v := range item.Variants {
+		variant := *v
+		copied.Variants[i] = &variant
+	}
+	return &copied
+}
+
+// GetWebsites retrieves every website ordered by name
+func (m *MemoryStore) GetWebsites() ([]*Website, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	websites := []*Website{}
+	for _, w := range m.websites {
+		copied := *w
+		websites = append(websites, &copied)
+	}
+	sort.Slice(websites, func(i, j int) bool {
+		if websites[i].Name == websites[j].Name {
+			return websites[i].ID < websites[j].ID
+		}
+		return websites[i].Name < websites[j].Name
+	})
+
+	return websites, nil
+}
+
+// GetWebsite retrieves a website by ID
+func (m *MemoryStore) GetWebsite(id int) (*Website, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	w, ok := m.websites[id]
+	if !ok {
+		return nil, ErrWebsiteNotFound
+	}
+	copied := *w
+	return &copied, nil
+}
+
+// CreateWebsite creates a website with the given user as its first owner
+func (m *MemoryStore) CreateWebsite(input WebsiteInput, ownerID int) (*Website, error) {
+	domain, err := NormalizeDomain(input.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.users[ownerID]; !ok {
+		return nil, ErrUserNotFound
+	}
+	if err := m.checkDomain(domain, 0); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	w := &Website{
+		ID:        m.nextWebID,
+		Name:      input.Name,
+		Domain:    domain,
+		OwnerID:   ownerID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.websites[w.ID] = w
+	m.nextWebID++
+	m.members[memberKey{w.ID, ownerID}] = &WebsiteMember{
+		WebsiteID: w.ID,
+		UserID:    ownerID,
+		Role:      WebsiteOwner,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	copied := *w
+	return &copied, nil
+}
+
+// UpdateWebsite renames a website or moves it to another domain
+func (m *MemoryStore) UpdateWebsite(id int, input WebsiteInput) (*Website, error) {
+	domain, err := NormalizeDomain(input.Domain)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w, ok := m.websites[id]
+	if !ok {
+		return nil, ErrWebsiteNotFound
+	}
+	if err := m.checkDomain(domain, id); err != nil {
+		return nil, err
+	}
+	w.Name = input.Name
+	w.Domain = domain
+	w.UpdatedAt = time.Now()
+
+	copied := *w
+	return &copied, nil
+}
+
+// DeleteWebsite deletes a website along with its pages and their items
+func (m *MemoryStore) DeleteWebsite(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.websites[id]; !ok {
+		return ErrWebsiteNotFound
+	}
+	m.deleteWebsite(id)
+	return nil
+}
+
+// memberKey identifies a website membership in the memory store
+type memberKey struct {
+	websiteID int
+	userID    int
+}
+
+// GetWebsitesByMember retrieves the websites a user is a member of, ordered by
+// name, each with the user's role
+func (m *MemoryStore) GetWebsitesByMember(userID int) ([]*Website, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	websites := []*Website{}
+	for key, member := range m.members {
+		if key.userID != userID {
+			continue
+		}
+		copied := *m.websites[key.websiteID]
+		copied.Role = member.Role
+		websites = append(websites, &copied)
+	}
+	sort.Slice(websites, func(i, j int) bool {
+		if websites[i].Name == websites[j].Name {
+			return websites[i].ID < websites[j].ID
+		}
+		return websites[i].Name < websites[j].Name
+	})
+
+	return websites, nil
+}
+
+// GetWebsiteMembers retrieves the members of a website ordered by username
+func (m *MemoryStore) GetWebsiteMembers(websiteID int) ([]*WebsiteMember, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	members := []*WebsiteMember{}
+	for key := range m.members {
+		if key.websiteID == websiteID {
+			members = append(members, m.memberView(key))
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].Username < members[j].Username
+	})
+
+	return members, nil
+}
+
+// GetWebsiteMember retrieves a user's membership of a website
+func (m *MemoryStore) GetWebsiteMember(websiteID, userID int) (*WebsiteMember, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	key := memberKey{websiteID, userID}
+	if _, ok := m.members[key]; !ok {
+		return nil, ErrMemberNotFound
+	}
+	return m.memberView(key), nil
+}
+
+// AddWebsiteMember gives a user a role on a website
+func (m *MemoryStore) AddWebsiteMember(websiteID, userID int, role WebsiteRole) (*WebsiteMember, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.websites[websiteID]; !ok {
+		return nil, ErrWebsiteNotFound
+	}
+	if _, ok := m.users[userID]; !ok {
+		return nil, ErrUserNotFound
+	}
+	key := memberKey{websiteID, userID}
+	if _, ok := m.members[key]; ok {
+		return nil, ErrMemberExists
+	}
+
+	now := time.Now()
+	m.members[key] = &WebsiteMember{
+		WebsiteID: websiteID,
+		UserID:    userID,
+		Role:      role,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	return m.memberView(key), nil
+}
+
+// UpdateWebsiteMember changes a member's role. The last owner cannot be demoted.
+func (m *MemoryStore) UpdateWebsiteMember(websiteID, userID int, role WebsiteRole) (*WebsiteMember, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := memberKey{websiteID, userID}
+	member, ok := m.members[key]
+	if !ok {
+		return nil, ErrMemberNotFound
+	}
+	if member.Role == WebsiteOwner && role != WebsiteOwner && !m.hasOtherOwner(websiteID, userID) {
+		return nil, ErrLastOwner
+	}
+	member.Role = role
+	member.UpdatedAt = time.Now()
+
+	return m.memberView(key), nil
+}
+
+// RemoveWebsiteMember takes a user off a website. The last owner cannot be removed.
+func (m *MemoryStore) RemoveWebsiteMember(websiteID, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := memberKey{websiteID, userID}
+	member, ok := m.members[key]
+	if !ok {
+		return ErrMemberNotFound
+	}
+	if member.Role == WebsiteOwner && !m.hasOtherOwner(websiteID, userID) {
+		return ErrLastOwner
+	}
+	delete(m.members, key)
+
+	return nil
+}
+
+// memberView returns a copy of a membership with the member's username filled in.
+// The caller must hold the lock.
+func (m *MemoryStore) memberView(key memberKey) *WebsiteMember {
+	copied := *m.members[key]
+	if user, ok := m.users[key.userID]; ok {
+		copied.Username = user.Username
+	}
+	return &copied
+}
+
+// hasOtherOwner reports whether the website has an owner besides userID.
+// The caller must hold the lock.
+func (m *MemoryStore) hasOtherOwner(websiteID, userID int) bool {
+	for key, member := range m.members {
+		if key.websiteID == websiteID && key.userID != userID && member.Role == WebsiteOwner {
+			return true
+		}
+	}
+	return false
+}
+
+// InviteWebsiteMember invites the user with the given email address to a
+// website, replacing any pending invite of the address to the same website
+func (m *MemoryStore) InviteWebsiteMember(websiteID int, email string, role WebsiteRole, invitedBy int) (*WebsiteInvite, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.websites[websiteID]; !ok {
+		return nil, ErrWebsiteNotFound
+	}
+
+	invite := m.findInvite(websiteID, email)
+	if invite == nil {
+		invite = &WebsiteInvite{ID: m.nextInvID, WebsiteID: websiteID, Email: email}
+		m.invites[invite.ID] = invite
+		m.nextInvID++
+	}
+	invite.Role = role
+	invite.InvitedBy = invitedBy
+	invite.CreatedAt = time.Now()
+
+	return m.inviteView(invite), nil
+}
+
+// GetWebsiteInvites retrieves the pending invites of a website ordered by email address
+func (m *MemoryStore) GetWebsiteInvites(websiteID int) ([]*WebsiteInvite, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	invites := []*WebsiteInvite{}
+	for _, invite := range m.invites {
+		if invite.WebsiteID == websiteID {
+			invites = append(invites, m.inviteView(invite))
+		}
+	}
+	sort.Slice(invites, func(i, j int) bool {
+		return invites[i].Email < invites[j].Email
+	})
+
+	return invites, nil
+}
+
+// GetUserInvites retrieves the pending invites of a user's email address,
+// newest first
+func (m *MemoryStore) GetUserInvites(userID int) ([]*WebsiteInvite, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	invites := []*WebsiteInvite{}
+	user, ok := m.users[userID]
+	if !ok {
+		return invites, nil
+	}
+	for _, invite := range m.invites {
+		if invite.Email == user.Email {
+			invites = append(invites, m.inviteView(invite))
+		}
+	}
+	sort.Slice(invites, func(i, j int) bool {
+		if invites[i].CreatedAt.Equal(invites[j].CreatedAt) {
+			return invites[i].ID > invites[j].ID
+		}
+		return invites[i].CreatedAt.After(invites[j].CreatedAt)
+	})
+
+	return invites, nil
+}
+
+// AcceptWebsiteInvite makes the user a member of the website they were
+// invited to, with the role of the invite, and deletes the invite
+func (m *MemoryStore) AcceptWebsiteInvite(id, userID int) (*WebsiteMember, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	invite := m.userInvite(id, userID)
+	if invite == nil {
+		return nil, ErrInviteNotFound
+	}
+	key := memberKey{invite.WebsiteID, userID}
+	if _, ok := m.members[key]; ok {
+		return nil, ErrMemberExists
+	}
+
+	now := time.Now()
+	m.members[key] = &WebsiteMember{
+		WebsiteID: invite.WebsiteID,
+		UserID:    userID,
+		Role:      invite.Role,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	delete(m.invites, id)
+
+	return m.memberView(key), nil
+}
+
+// DeclineWebsiteInvite deletes an invite of the user's email address
+func (m *MemoryStore) DeclineWebsiteInvite(id, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.userInvite(id, userID) == nil {
+		return ErrInviteNotFound
+	}
+	delete(m.invites, id)
+	return nil
+}
+
+// DeleteWebsiteInvite withdraws a pending invite to a website
+func (m *MemoryStore) DeleteWebsiteInvite(websiteID, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	invite, ok := m.invites[id]
+	if !ok || invite.WebsiteID != websiteID {
+		return ErrInviteNotFound
+	}
+	delete(m.invites, id)
+	return nil
+}
+
+// findInvite returns the invite of an email address to a website, or nil.
+// The caller must hold the lock.
+func (m *MemoryStore) findInvite(websiteID int, email string) *WebsiteInvite {
+	for _, invite := range m.invites {
+		if invite.WebsiteID == websiteID && invite.Email == email {
+			return invite
+		}
+	}
+	return nil
+}
+
+// userInvite returns an invite if it is for the user's email address, or nil.
+// The caller must hold the lock.
+func (m *MemoryStore) userInvite(id, userID int) *WebsiteInvite {
+	invite, ok := m.invites[id]
+	user, found := m.users[userID]
+	if !ok || !found || invite.Email != user.Email {
+		return nil
+	}
+	return invite
+}
+
+// inviteView returns a copy of an invite with the website's name filled in.
+// The caller must hold the lock.
+func (m *MemoryStore) inviteView(invite *WebsiteInvite) *WebsiteInvite {
+	copied := *invite
+	if w, ok := m.websites[invite.WebsiteID]; ok {
+		copied.WebsiteName = w.Name
+	}
+	return &copied
+}
+
+// GetPages retrieves the pages of a website ordered by slug
+func (m *MemoryStore) GetPages(websiteID int) ([]*Page, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.websites[websiteID]; !ok {
+		return nil, ErrWebsiteNotFound
+	}
+
+	pages := []*Page{}
+	for _, p := range m.pages {
+		if p.WebsiteID == websiteID {
+			copied := *p
+			pages = append(pages, &copied)
+		}
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].Slug < pages[j].Slug })
+
+	return pages, nil
+}
+
+// GetPage retrieves a page of a website by ID
+func (m *MemoryStore) GetPage(websiteID, id int) (*Page, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	p, ok := m.pages[id]
+	if !ok || p.WebsiteID != websiteID {
+		return nil, ErrPageNotFound
+	}
+	copied := *p
+	return &copied, nil
+}
+
+// CreatePage creates a page on a website
+func (m *MemoryStore) CreatePage(websiteID int, input PageInput) (*Page, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.websites[websiteID]; !ok {
+		return nil, ErrWebsiteNotFound
+	}
+	slug, err := m.resolvePageSlug(websiteID, 0, input)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	p := &Page{
+		ID:        m.nextPageID,
+		WebsiteID: websiteID,
+		Title:     input.Title,
+		Slug:      slug,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.pages[p.ID] = p
+	m.nextPageID++
+
+	copied := *p
+	return &copied, nil
+}
+
+// UpdatePage updates the title of a page and, if one is given, its slug
+func (m *MemoryStore) UpdatePage(websiteID, id int, input PageInput) (*Page, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.pages[id]
+	if !ok || p.WebsiteID != websiteID {
+		return nil, ErrPageNotFound
+	}
+	if input.Slug != "" {
+		slug, err := m.resolvePageSlug(websiteID, id, input)
+		if err != nil {
+			return nil, err
+		}
+		p.Slug = slug
+	}
+	p.Title = input.Title
+	p.UpdatedAt = time.Now()
+
+	copied := *p
+	return &copied, nil
+}
+
+// DeletePage deletes a page of a website along with its items
+func (m *MemoryStore) DeletePage(websiteID, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	p, ok := m.pages[id]
+	if !ok || p.WebsiteID != websiteID {
+		return ErrPageNotFound
+	}
+	m.deletePage(id)
+	return nil
+}
+
+// GetItems retrieves the items of a page in position order
+func (m *MemoryStore) GetItems(pageID int) ([]*Item, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	items := []*Item{}
+	for _, item := range m.itemList(pageID) {
+		items = append(items, itemView(item))
+	}
+	return items, nil
+}
+
+// GetItem retrieves an item of a page by ID
+func (m *MemoryStore) GetItem(pageID, id int) (*Item, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	item, ok := m.items[id]
+	if !ok || item.PageID != pageID {
+		return nil, ErrItemNotFound
+	}
+	return itemView(item), nil
+}
+
+// CreateItem adds an item to the end of a page
+func (m *MemoryStore) CreateItem(pageID int, input ItemInput) (*Item, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkItemImage(input.ImageID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	item := &Item{
+		ID:        m.nextItemID,
+		PageID:    pageID,
+		Title:     input.Title,
+		Subtitle:  input.Subtitle,
+		ImageID:   optionalID(input.ImageID),
+		Position:  len(m.itemList(pageID)),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.items[item.ID] = item
+	m.nextItemID++
+
+	return itemView(item), nil
+}
+
+// UpdateItem updates the fields of an item, keeping its position
+func (m *MemoryStore) UpdateItem(pageID, id int, input ItemInput) (*Item, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[id]
+	if !ok || item.PageID != pageID {
+		return nil, ErrItemNotFound
+	}
+	if err := m.checkItemImage(input.ImageID); err != nil {
+		return nil, err
+	}
+	item.Title = input.Title
+	item.Subtitle = input.Subtitle
+	item.ImageID = optionalID(input.ImageID)
+	item.UpdatedAt = time.Now()
+
+	return itemView(item), nil
+}
+
+// MoveItem moves an item to the given position on its page
+func (m *MemoryStore) MoveItem(pageID, id, position int) (*Item, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[id]
+	if !ok || item.PageID != pageID {
+		return nil, ErrItemNotFound
+	}
+
+	others, _ := otherItems(m.itemList(pageID), id)
+	for i, itemID := range insertAt(others, id, position) {
+		m.items[itemID].Position = i
+	}
+	item.UpdatedAt = time.Now()
+
+	return itemView(item), nil
+}
+
+// DeleteItem deletes an item and closes the gap it leaves on the page
+func (m *MemoryStore) DeleteItem(pageID, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[id]
+	if !ok || item.PageID != pageID {
+		return ErrItemNotFound
+	}
+
+	others, _ := otherItems(m.itemList(pageID), id)
+	delete(m.items, id)
+	for i, itemID := range others {
+		m.items[itemID].Position = i
+	}
+
+	return nil
+}
+
+// checkDomain returns ErrDomainTaken if a website other than websiteID uses the domain.
+// The caller must hold the lock.
+func (m *MemoryStore) checkDomain(domain string, websiteID int) error {
+	for _, w := range m.websites {
+		if w.Domain == domain && w.ID != websiteID {
+			return ErrDomainTaken
+		}
+	}
+	return nil
+}
+
+// resolvePageSlug works out the slug to store for a page of a website.
+// The caller must hold the lock.
+func (m *MemoryStore) resolvePageSlug(websiteID, pageID int, input PageInput) (string, error) {
+	base, err := pageSlug(input)
+	if err != nil {
+		return "", err
+	}
+
+	taken := func(slug string) (bool, error) {
+		for _, p := range m.pages {
+			if p.WebsiteID == websiteID && p.Slug == slug && p.ID != pageID {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if input.Slug == "" {
+		return uniqueSlug(base, taken)
+	}
+	if used, _ := taken(base); used {
+		return "", ErrSlugTaken
+	}
+	return base, nil
+}
+
+// checkItemImage returns ErrItemImageMissing unless the item image exists.
+// The caller must hold the lock.
+func (m *MemoryStore) checkItemImage(id *int) error {
+	if id == nil || *id == 0 {
+		return nil
+	}
+	if _, ok := m.media[*id]; !ok {
+		return ErrItemImageMissing
+	}
+	return nil
+}
+
+// deleteWebsite removes a website with its pages and their items.
+// The caller must hold the write lock.
+func (m *MemoryStore) deleteWebsite(id int) {
+	for pageID, p := range m.pages {
+		if p.WebsiteID == id {
+			m.deletePage(pageID)
+		}
+	}
+	for key := range m.members {
+		if key.websiteID == id {
+			delete(m.members, key)
+		}
+	}
+	for inviteID, invite := range m.invites {
+		if invite.WebsiteID == id {
+			delete(m.invites, inviteID)
+		}
+	}
+	delete(m.websites, id)
+}
+
+// deletePage removes a page with its items.
+// The caller must hold the write lock.
+func (m *MemoryStore) deletePage(id int) {
+	for itemID, item := range m.items {
+		if item.PageID == id {
+			delete(m.items, itemID)
+		}
+	}
+	delete(m.pages, id)
+}
+
+// itemList returns the stored items of a page in position order.
+// The caller must hold the lock.
+func (m *MemoryStore) itemList(pageID int) []*Item {
+	var items []*Item
+	for _, item := range m.items {
+		if item.PageID == pageID {
+			items = append(items, item)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Position == items[j].Position {
+			return items[i].ID < items[j].ID
+		}
+		return items[i].Position < items[j].Position
+	})
+	return items
+}
+
+// itemView returns a copy of a stored item
+func itemView(item *Item) *Item {
+	copied := *item
+	copied.ImageID = optionalID(item.ImageID)
+	return &copied
+}
+
+// deletePostComments removes every comment on a post.
+// The caller must hold the write lock.
+func (m *MemoryStore) deletePostComments(postID int) {
+	for id, c := range m.comments {
+		if c.PostID == postID {
+			delete(m.comments, id)
+		}
+	}
+}
+
+// commentView returns a copy of the comment with the author's username filled in.
+// The caller must hold the lock.
+func (m *MemoryStore) commentView(c *Comment) *Comment {
+	copied := *c
+	copied.ParentID = optionalID(c.ParentID)
+	copied.Replies = []*Comment{}
+	if c.ModeratedAt != nil {
+		moderatedAt := *c.ModeratedAt
+		copied.ModeratedAt = &moderatedAt
+	}
+	if author, ok := m.users[c.AuthorID]; ok {
+		copied.Author = author.Username
+	}
+	return &copied
+}
+
+// checkCategory returns ErrCategoryNotFound unless the post category exists.
+// The caller must hold the lock.
+func (m *MemoryStore) checkCategory(id *int) error {
+	if id == nil || *id == 0 {
+		return nil
+	}
+	if _, ok := m.categories[*id]; !ok {
+		return ErrCategoryNotFound
+	}
+	return nil
+}
+
+// checkFeaturedImage returns ErrFeaturedImageMissing unless the featured image exists.
+// The caller must hold the lock.
+func (m *MemoryStore) checkFeaturedImage(id *int) error {
+	if id == nil || *id == 0 {
+		return nil
+	}
+	if _, ok := m.media[*id]; !ok {
+		return ErrFeaturedImageMissing
+	}
+	return nil
+}
+
+// categoryList returns the stored categories ordered by ID.
+// The caller must hold the lock.
+func (m *MemoryStore) categoryList() []*Category {
+	categories := make([]*Category, 0, len(m.categories))
+	for _, c := range m.categories {
+		categories = append(categories, c)
+	}
+	sort.Slice(categories, func(i, j int) bool { return categories[i].ID < categories[j].ID })
+	return categories
+}
+
+// categoryView returns a copy of a stored category
+func categoryView(c *Category) *Category {
+	copied := *c
+	copied.ParentID = optionalID(c.ParentID)
+	return &copied
+}
+
+// optionalID copies an optional ID, treating 0 as none
+func optionalID(id *int) *int {
+	if id == nil || *id == 0 {
+		return nil
+	}
+	copied := *id
+	return &copied
+}
+
+// addRevision appends a snapshot of the post to its history.
+// The caller must hold the write lock.
+func (m *MemoryStore) addRevision(post *Post, editorID int) {
+	history := m.revisions[post.ID]
+	m.revisions[post.ID] = append(history, &PostRevision{
+		ID:        m.nextRevID,
+		PostID:    post.ID,
+		Revision:  len(history) + 1,
+		Title:     post.Title,
+		Content:   post.Content,
+		EditorID:  editorID,
+		CreatedAt: post.UpdatedAt,
+	})
+	m.nextRevID++
+}
+
+// revisionView returns a copy of the revision with the editor's username filled in.
+// The caller must hold the lock.
+func (m *MemoryStore) revisionView(rev *PostRevision) *PostRevision {
+	copied := *rev
+	if editor, ok := m.users[rev.EditorID]; ok {
+		copied.Editor = editor.Username
+	}
+	return &copied
+}
+
+// hasTag reports whether the post has the tag
+func hasTag(post *Post, tag string) bool {
+	for _, t := range post.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// listPosts returns copies of the posts matching keep, newest first.
+// The caller must hold the lock.
+func (m *MemoryStore) listPosts(keep func(*Post) bool) []*Post {
+	var posts []*Post
+	for _, post := range m.posts {
+		if keep(post) {
+			posts = append(posts, m.postView(post))
+		}
+	}
+	sort.Slice(posts, func(i, j int) bool {
+		if posts[i].CreatedAt.Equal(posts[j].CreatedAt) {
+			return posts[i].ID > posts[j].ID
+		}
+		return posts[i].CreatedAt.After(posts[j].CreatedAt)
+	})
+	return posts
+}
+
+// postView returns a copy of the post with the author's username filled in.
+// The caller must hold the lock.
+func (m *MemoryStore) postView(post *Post) *Post {
+	copied := *post
+	copied.Tags = append([]string{}, post.Tags...)
+	if post.CategoryID != nil {
+		categoryID := *post.CategoryID
+		copied.CategoryID = &categoryID
+	}
+	if post.PublishedAt != nil {
+		publishedAt := *post.PublishedAt
+		copied.PublishedAt = &publishedAt
+	}
+	copied.FeaturedImageID = optionalID(post.FeaturedImageID)
+	if author, ok := m.users[post.AuthorID]; ok {
+		copied.Author = author.Username
+	}
+	copied.CommentCount = 0
+	for _, c := range m.comments {
+		if c.PostID == post.ID && c.Status == CommentApproved && !c.Deleted {
+			copied.CommentCount++
+		}
+	}
+	return &copied
+}
+
+// CreateRefreshToken stores a refresh token of the given family, clearing out
+// the user's expired tokens while at it
+func (m *MemoryStore) CreateRefreshToken(userID int, familyID, tokenHash string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for hash, t := range m.refresh {
+		if t.UserID == userID && t.ExpiresAt.Before(now) {
+			delete(m.refresh, hash)
+		}
+	}
+
+	m.refresh[tokenHash] = &RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: tokenHash,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}
+	return nil
+}
+
+// UseRefreshToken exchanges a refresh token, marking it used so that it works
+// only once. Replaying a used token revokes its whole family.
+func (m *MemoryStore) UseRefreshToken(tokenHash string) (*RefreshToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.refresh[tokenHash]
+	if !ok {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	now := time.Now()
+	if t.UsedAt != nil {
+		m.revokeFamily(t.FamilyID, now)
+		return nil, ErrRefreshTokenReused
+	}
+	if t.RevokedAt != nil || !now.Before(t.ExpiresAt) {
+		return nil, ErrRefreshTokenInvalid
+	}
+	t.UsedAt = &now
+
+	copied := *t
+	return &copied, nil
+}
+
+// RevokeRefreshToken logs out the session of one of the user's refresh tokens
+// by revoking its whole family
+func (m *MemoryStore) RevokeRefreshToken(tokenHash string, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.refresh[tokenHash]
+	if !ok || t.UserID != userID {
+		return ErrRefreshTokenInvalid
+	}
+	m.revokeFamily(t.FamilyID, time.Now())
+	return nil
+}
+
+// RevokeToken records that the access token with the given ID may no longer be used
+func (m *MemoryStore) RevokeToken(tokenID string, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for id, expiry := range m.revoked {
+		if expiry.Before(now) {
+			delete(m.revoked, id)
+		}
+	}
+	m.revoked[tokenID] = expiresAt
+	return nil
+}
+
+// IsTokenRevoked reports whether the access token with the given ID has been revoked
+func (m *MemoryStore) IsTokenRevoked(tokenID string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	_, revoked := m.revoked[tokenID]
+	return revoked, nil
+}
+
+// revokeFamily revokes every refresh token of a family.
+// The caller must hold the write lock.
+func (m *MemoryStore) revokeFamily(familyID string, now time.Time) {
+	for _, t := range m.refresh {
+		if t.FamilyID == familyID && t.RevokedAt == nil {
+			t.RevokedAt = &now
+		}
+	}
+}
+
+// CreatePersonalToken stores a new personal access token for the user under
+// the hash of the token
+func (m *MemoryStore) CreatePersonalToken(userID int, name string, scopes []Scope, prefix, tokenHash string, expiresAt time.Time) (*PersonalToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if err := checkPersonalToken(name, scopes, expiresAt, now); err != nil {
+		return nil, err
+	}
+
+	t := &PersonalToken{
+		ID:        m.nextPatID,
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		TokenHash: tokenHash,
+		Scopes:    append([]Scope{}, scopes...),
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}
+	m.personal[t.ID] = t
+	m.nextPatID++
+
+	return copyPersonalToken(t), nil
+}
+
+// GetPersonalTokens retrieves the user's personal access tokens, newest first,
+// including expired and revoked ones
+func (m *MemoryStore) GetPersonalTokens(userID int) ([]*PersonalToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	tokens := []*PersonalToken{}
+	for _, t := range m.personal {
+		if t.UserID == userID {
+			tokens = append(tokens, copyPersonalToken(t))
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID > tokens[j].ID })
+	return tokens, nil
+}
+
+// RevokePersonalToken revokes one of the user's personal access tokens.
+// Revoking a revoked token is not an error.
+func (m *MemoryStore) RevokePersonalToken(id, userID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.personal[id]
+	if !ok || t.UserID != userID {
+		return ErrPersonalTokenNotFound
+	}
+	if t.RevokedAt == nil {
+		now := time.Now()
+		t.RevokedAt = &now
+	}
+	return nil
+}
+
+// GetPersonalTokenByHash looks up the personal access token with the given
+// hash
+func (m *MemoryStore) GetPersonalTokenByHash(tokenHash string) (*PersonalToken, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, t := range m.personal {
+		if t.TokenHash != tokenHash {
+			continue
+		}
+		if t.RevokedAt != nil || !time.Now().Before(t.ExpiresAt) {
+			return nil, ErrPersonalTokenInvalid
+		}
+		return copyPersonalToken(t), nil
+	}
+	return nil, ErrPersonalTokenInvalid
+}
+
+// RecordPersonalTokenUse records that a personal access token was used
+func (m *MemoryStore) RecordPersonalTokenUse(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if t, ok := m.personal[id]; ok {
+		now := time.Now()
+		t.LastUsedAt = &now
+	}
+	return nil
+}
+
+// copyPersonalToken returns a copy of a token that callers cannot use to
+// change the stored one
+func copyPersonalToken(t *PersonalToken) *PersonalToken {
+	copied := *t
+	copied.Scopes = append([]Scope{}, t.Scopes...)
+	return &copied
+}
@@ -0,0 +1,225 @@
+// backend/internal/models/personal_token.go
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// MaxPersonalTokenLifetime is how far in the future a personal access token may expire
+const MaxPersonalTokenLifetime = 365 * 24 * time.Hour
+
+// Errors returned by personal access token operations
+var (
+	ErrPersonalTokenNotFound = errors.New("personal access token not found")
+	ErrPersonalTokenInvalid  = errors.New("invalid, expired or revoked personal access token")
+	ErrInvalidScope          = errors.New("scopes must be one or more of posts:read, posts:write and users:admin")
+	ErrInvalidTokenName      = errors.New("token name is required and must be at most 100 characters")
+	ErrInvalidTokenExpiry    = errors.New("token expiry must be in the future and at most 365 days away")
+)
+
+// Scope limits what a personal access token may be used for. Within its
+// scopes, a token can do what its user's role allows.
+type Scope string
+
+// Personal access token scopes
+const (
+	ScopePostsRead  Scope = "posts:read"  // Read posts, their revisions, comments, tags and categories
+	ScopePostsWrite Scope = "posts:write" // Create, update, delete and publish posts
+	ScopeUsersAdmin Scope = "users:admin" // List and delete users and change their roles
+)
+
+// Valid reports whether s is a known scope
+func (s Scope) Valid() bool {
+	return s == ScopePostsRead || s == ScopePostsWrite || s == ScopeUsersAdmin
+}
+
+// PersonalToken is a long-lived token a user mints for scripts and CI jobs.
+// Only a hash of the token is stored; the token itself is shown once, when
+// it is created.
+type PersonalToken struct {
+	ID         int        `json:"id"`
+	UserID     int        `json:"user_id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // Start of the token, to tell tokens apart
+	TokenHash  string     `json:"-"`
+	Scopes     []Scope    `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HasScope reports whether the token was granted the scope
+func (t *PersonalToken) HasScope(scope Scope) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// checkPersonalToken validates the settings of a new token
+func checkPersonalToken(name string, scopes []Scope, expiresAt, now time.Time) error {
+	if strings.TrimSpace(name) == "" || len(name) > 100 {
+		return ErrInvalidTokenName
+	}
+	if len(scopes) == 0 {
+		return ErrInvalidScope
+	}
+	for _, s := range scopes {
+		if !s.Valid() {
+			return ErrInvalidScope
+		}
+	}
+	if !expiresAt.After(now) || expiresAt.After(now.Add(MaxPersonalTokenLifetime)) {
+		return ErrInvalidTokenExpiry
+	}
+	return nil
+}
+
+// joinScopes and splitScopes convert scopes to and from their column, a
+// comma-separated list
+func joinScopes(scopes []Scope) string {
+	parts := make([]string, len(scopes))
+	for i, s := range scopes {
+		parts[i] = string(s)
+	}
+	return strings.Join(parts, ",")
+}
+
+func splitScopes(column string) []Scope {
+	scopes := []Scope{}
+	for _, part := range strings.Split(column, ",") {
+		if part != "" {
+			scopes = append(scopes, Scope(part))
+		}
+	}
+	return scopes
+}
+
+// personalTokenSelect is the column list shared by every token query, read with scanPersonalToken
+const personalTokenSelect = `
+		SELECT id, user_id, name, prefix, token_hash, scopes, expires_at, last_used_at, revoked_at, created_at
+		FROM personal_tokens`
+
+// scanPersonalToken reads a row selected with personalTokenSelect
+func scanPersonalToken(row rowScanner) (*PersonalToken, error) {
+	var t PersonalToken
+	var scopes string
+	var lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.Prefix, &t.TokenHash, &scopes, &t.ExpiresAt, &lastUsedAt, &revokedAt, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	t.Scopes = splitScopes(scopes)
+	if lastUsedAt.Valid {
+		t.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+	return &t, nil
+}
+
+// CreatePersonalToken stores a new personal access token for the user under
+// the hash of the token
+func (s *SQLStore) CreatePersonalToken(userID int, name string, scopes []Scope, prefix, tokenHash string, expiresAt time.Time) (*PersonalToken, error) {
+	now := s.now()
+	if err := checkPersonalToken(name, scopes, expiresAt, now); err != nil {
+		return nil, err
+	}
+
+	id, err := s.db.Insert(
+		"INSERT INTO personal_tokens (user_id, name, prefix, token_hash, scopes, expires_at, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		userID, name, prefix, tokenHash, joinScopes(scopes), s.db.Dialect.Time(expiresAt), now,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PersonalToken{
+		ID:        int(id),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		TokenHash: tokenHash,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt,
+		CreatedAt: now,
+	}, nil
+}
+
+// GetPersonalTokens retrieves the user's personal access tokens, newest first,
+// including expired and revoked ones
+func (s *SQLStore) GetPersonalTokens(userID int) ([]*PersonalToken, error) {
+	rows, err := s.db.Query(personalTokenSelect+`
+		WHERE user_id = ?
+		ORDER BY created_at DESC, id DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []*PersonalToken{}
+	for rows.Next() {
+		t, err := scanPersonalToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens, rows.Err()
+}
+
+// RevokePersonalToken revokes one of the user's personal access tokens.
+// Revoking a revoked token is not an error.
+func (s *SQLStore) RevokePersonalToken(id, userID int) error {
+	var revokedAt sql.NullTime
+	err := s.db.QueryRow("SELECT revoked_at FROM personal_tokens WHERE id = ? AND user_id = ?", id, userID).Scan(&revokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrPersonalTokenNotFound
+		}
+		return err
+	}
+	if revokedAt.Valid {
+		return nil
+	}
+
+	_, err = s.db.Exec("UPDATE personal_tokens SET revoked_at = ? WHERE id = ?", s.now(), id)
+	return err
+}
+
+// GetPersonalTokenByHash looks up the personal access token with the given
+// hash. Unknown, expired and revoked tokens give ErrPersonalTokenInvalid.
+func (s *SQLStore) GetPersonalTokenByHash(tokenHash string) (*PersonalToken, error) {
+	t, err := scanPersonalToken(s.db.QueryRow(personalTokenSelect+`
+		WHERE token_hash = ?`,
+		tokenHash,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPersonalTokenInvalid
+		}
+		return nil, err
+	}
+
+	if t.RevokedAt != nil || !s.now().Before(t.ExpiresAt) {
+		return nil, ErrPersonalTokenInvalid
+	}
+	return t, nil
+}
+
+// RecordPersonalTokenUse records that a personal access token was used for a
+// request it was allowed to make
+func (s *SQLStore) RecordPersonalTokenUse(id int) error {
+	_, err := s.db.Exec("UPDATE personal_tokens SET last_used_at = ? WHERE id = ?", s.now(), id)
+	return err
+}
@@ -3,202 +3,477 @@ package models
 
 import (
 	"database/sql"
-	"errors"
 	"time"
+
+	"blog-app/internal/search"
 )
 
 // Post represents a blog post
 type Post struct {
-	ID        int       `json:"id"`
-	Title     string    `json:"title"`
-	Content   string    `json:"content"`
-	AuthorID  int       `json:"author_id"`
-	Author    string    `json:"author"` // Username of the author
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID              int        `json:"id"`
+	Title           string     `json:"title"`
+	Slug            string     `json:"slug"`
+	Content         string     `json:"content"`
+	AuthorID        int        `json:"author_id"`
+	Author          string     `json:"author"`      // Username of the author
+	CategoryID      *int       `json:"category_id"` // nil when the post is uncategorized
+	Tags            []string   `json:"tags"`
+	FeaturedImageID *int       `json:"featured_image_id"` // Media ID, nil when the post has no featured image
+	SEO             PostSEO    `json:"seo"`
+	CommentCount    int        `json:"comment_count"` // Approved comments only
+	Status          PostStatus `json:"status"`
+	PublishedAt     *time.Time `json:"published_at"` // Scheduled publication time while the post is scheduled
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// PostInput holds the writable fields of a post.
+// An empty Status means draft on create and "unchanged" on update.
+// Setting PublishAt schedules the post for that time. An empty Slug is
+// generated from the title on create and left unchanged on update, as are
+// nil Tags and a nil CategoryID. A CategoryID of 0 removes the category.
+// FeaturedImageID and SEO work the same way, with a nil SEO leaving the
+// metadata empty on create.
+type PostInput struct {
+	Title           string
+	Slug            string
+	Content         string
+	CategoryID      *int
+	Tags            []string
+	Status          PostStatus
+	PublishAt       *time.Time
+	FeaturedImageID *int
+	SEO             *PostSEO
+}
+
+// targetStatus returns the status the input asks for, or "" to leave it alone
+func (in PostInput) targetStatus() PostStatus {
+	if in.PublishAt != nil {
+		return StatusScheduled
+	}
+	return in.Status
+}
+
+// postSelect is the column list shared by every post query, read with scanPost
+const postSelect = `
+		SELECT p.id, p.title, p.slug, p.content, p.category_id, p.author_id, u.username, p.status, p.published_at, p.created_at, p.updated_at,
+			p.featured_image_id, p.meta_title, p.meta_description, p.canonical_url, p.og_title, p.og_description,
+			(SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id AND c.status = 'approved' AND c.deleted_at IS NULL)
+		FROM posts p
+		JOIN users u ON p.author_id = u.id`
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanPost reads a row selected with postSelect
+func scanPost(row rowScanner) (*Post, error) {
+	var post Post
+	var categoryID, featuredImageID sql.NullInt64
+	var publishedAt sql.NullTime
+	if err := row.Scan(
+		&post.ID, &post.Title, &post.Slug, &post.Content, &categoryID, &post.AuthorID, &post.Author, &post.Status, &publishedAt, &post.CreatedAt, &post.UpdatedAt,
+		&featuredImageID, &post.SEO.MetaTitle, &post.SEO.MetaDescription, &post.SEO.CanonicalURL, &post.SEO.OGTitle, &post.SEO.OGDescription,
+		&post.CommentCount,
+	); err != nil {
+		return nil, err
+	}
+	if categoryID.Valid {
+		id := int(categoryID.Int64)
+		post.CategoryID = &id
+	}
+	if featuredImageID.Valid {
+		id := int(featuredImageID.Int64)
+		post.FeaturedImageID = &id
+	}
+	if publishedAt.Valid {
+		post.PublishedAt = &publishedAt.Time
+	}
+	return &post, nil
+}
+
+// queryPosts runs a query built on postSelect and reads every row, with tags
+func (s *SQLStore) queryPosts(query string, args ...interface{}) ([]*Post, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var posts []*Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	// Close the rows before loading tags; SQLite has a single connection
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.attachTags(posts); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
 }
 
 // CreatePost creates a new post in the database
-func CreatePost(db *sql.DB, title, content string, authorID int) (*Post, error) {
+func (s *SQLStore) CreatePost(input PostInput, authorID int) (*Post, error) {
 	// Validate that the author exists
 	var exists bool
-	err := db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = ?)", authorID).Scan(&exists)
+	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE id = ?)", authorID).Scan(&exists)
 	if err != nil {
 		return nil, err
 	}
 	if !exists {
-		return nil, errors.New("author does not exist")
+		return nil, ErrNoAuthor
 	}
 
-	// Create the post
-	now := time.Now()
-	result, err := db.Exec(
-		"INSERT INTO posts (title, content, author_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
-		title, content, authorID, now, now,
-	)
+	// Clean up the tags and metadata, and check the category and featured image
+	tags, err := NormalizeTags(input.Tags)
+	if err != nil {
+		return nil, err
+	}
+	seo, err := input.seo(PostSEO{})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCategory(input.CategoryID); err != nil {
+		return nil, err
+	}
+	if err := s.checkFeaturedImage(input.FeaturedImageID); err != nil {
+		return nil, err
+	}
+
+	// New posts start as drafts unless published straight away
+	now := s.now()
+	status := StatusDraft
+	var publishedAt *time.Time
+	if target := input.targetStatus(); target != "" {
+		status = target
+		publishedAt, err = transition(StatusDraft, nil, status, input.PublishAt, now)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := s.db.Begin()
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
 
-	// Get the ID of the new post
-	id, err := result.LastInsertId()
+	// Pick a unique slug
+	slug, err := resolveSlug(tx, input.Slug, input.Title, 0)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get the author's username
-	var username string
-	err = db.QueryRow("SELECT username FROM users WHERE id = ?", authorID).Scan(&username)
+	// Create the post
+	searchText := search.StripHTML(input.Content)
+	id, err := tx.Insert(
+		`INSERT INTO posts (title, slug, content, search_text, word_count, category_id, author_id, status, published_at, created_at, updated_at,
+			featured_image_id, meta_title, meta_description, canonical_url, og_title, og_description)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		input.Title, slug, input.Content, searchText, wordCount(searchText), optionalIDArg(input.CategoryID), authorID, status, s.timeArg(publishedAt), now, now,
+		optionalIDArg(input.FeaturedImageID), seo.MetaTitle, seo.MetaDescription, seo.CanonicalURL, seo.OGTitle, seo.OGDescription,
+	)
 	if err != nil {
 		return nil, err
 	}
 
+	// Tag the post
+	if err := setPostTags(tx, int(id), tags); err != nil {
+		return nil, err
+	}
+
+	// Record the initial version as the first revision
+	if err := insertRevision(tx, int(id), input.Title, input.Content, authorID, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	s.indexPost(int(id), input.Title, searchText)
+
 	// Return the new post
-	return &Post{
-		ID:        int(id),
-		Title:     title,
-		Content:   content,
-		AuthorID:  authorID,
-		Author:    username,
-		CreatedAt: now,
-		UpdatedAt: now,
-	}, nil
+	return s.GetPostByID(int(id))
 }
 
-// GetPostByID retrieves a post by ID
-func GetPostByID(db *sql.DB, id int) (*Post, error) {
-	var post Post
-	err := db.QueryRow(`
-		SELECT p.id, p.title, p.content, p.author_id, u.username, p.created_at, p.updated_at 
-		FROM posts p 
-		JOIN users u ON p.author_id = u.id 
+// GetPostByID retrieves a post by ID, whatever its status.
+// Callers serving users must check Post.VisibleTo.
+func (s *SQLStore) GetPostByID(id int) (*Post, error) {
+	post, err := scanPost(s.db.QueryRow(postSelect+`
 		WHERE p.id = ?`,
 		id,
-	).Scan(
-		&post.ID, &post.Title, &post.Content, &post.AuthorID, &post.Author, &post.CreatedAt, &post.UpdatedAt,
-	)
+	))
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPostNotFound
+		}
 		return nil, err
 	}
-	return &post, nil
+	if err := s.attachTags([]*Post{post}); err != nil {
+		return nil, err
+	}
+	return post, nil
+}
+
+// GetPosts retrieves all posts visible to the viewer: every published post plus
+// the viewer's own posts in any state, or every post for editors. Use the zero
+// Viewer for anonymous readers.
+func (s *SQLStore) GetPosts(viewer Viewer) ([]*Post, error) {
+	visible, args := visibleWhere(viewer)
+	return s.queryPosts(postSelect+`
+		WHERE `+visible+`
+		ORDER BY p.created_at DESC`,
+		args...,
+	)
 }
 
-// GetPosts retrieves all posts
-func GetPosts(db *sql.DB) ([]*Post, error) {
-	rows, err := db.Query(`
-		SELECT p.id, p.title, p.content, p.author_id, u.username, p.created_at, p.updated_at 
-		FROM posts p 
-		JOIN users u ON p.author_id = u.id 
-		ORDER BY p.created_at DESC`)
+// UpdatePost updates an existing post
+func (s *SQLStore) UpdatePost(id int, input PostInput, userID int) (*Post, error) {
+	// Check if the post exists and belongs to the user
+	post, err := s.GetPostByID(id)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var posts []*Post
-	for rows.Next() {
-		var post Post
-		if err := rows.Scan(
-			&post.ID, &post.Title, &post.Content, &post.AuthorID, &post.Author, &post.CreatedAt, &post.UpdatedAt,
-		); err != nil {
+	// Only the author or an editor can update the post
+	if err := s.checkPostWriter(post.AuthorID, userID, PermEditAnyPost, "update"); err != nil {
+		return nil, err
+	}
+
+	// Clean up the tags, if they are being replaced
+	var tags []string
+	if input.Tags != nil {
+		tags, err = NormalizeTags(input.Tags)
+		if err != nil {
 			return nil, err
 		}
-		posts = append(posts, &post)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+	// Check the new category, if it is being changed
+	categoryID := post.CategoryID
+	if input.CategoryID != nil {
+		if err := s.checkCategory(input.CategoryID); err != nil {
+			return nil, err
+		}
+		categoryID = input.CategoryID
 	}
 
-	return posts, nil
-}
-
-// UpdatePost updates an existing post
-func UpdatePost(db *sql.DB, id int, title, content string, userID int) (*Post, error) {
-	// Check if the post exists and belongs to the user
-	var authorID int
-	err := db.QueryRow("SELECT author_id FROM posts WHERE id = ?", id).Scan(&authorID)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("post not found")
+	// Check the new featured image and metadata, if they are being changed
+	featuredImageID := post.FeaturedImageID
+	if input.FeaturedImageID != nil {
+		if err := s.checkFeaturedImage(input.FeaturedImageID); err != nil {
+			return nil, err
 		}
+		featuredImageID = input.FeaturedImageID
+	}
+	seo, err := input.seo(post.SEO)
+	if err != nil {
 		return nil, err
 	}
 
-	// Only the author can update their own post
-	if authorID != userID {
-		return nil, errors.New("unauthorized: you can only update your own posts")
+	// Apply a status change if one was requested
+	now := s.now()
+	status, publishedAt := post.Status, post.PublishedAt
+	if target := input.targetStatus(); target != "" && (target != post.Status || input.PublishAt != nil) {
+		publishedAt, err = transition(post.Status, post.PublishedAt, target, input.PublishAt, now)
+		if err != nil {
+			return nil, err
+		}
+		status = target
 	}
 
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	// Update the post
-	now := time.Now()
-	_, err = db.Exec(
-		"UPDATE posts SET title = ?, content = ?, updated_at = ? WHERE id = ?",
-		title, content, now, id,
+	searchText := search.StripHTML(input.Content)
+	_, err = tx.Exec(
+		`UPDATE posts SET title = ?, content = ?, search_text = ?, word_count = ?, category_id = ?, status = ?, published_at = ?, updated_at = ?,
+			featured_image_id = ?, meta_title = ?, meta_description = ?, canonical_url = ?, og_title = ?, og_description = ?
+		WHERE id = ?`,
+		input.Title, input.Content, searchText, wordCount(searchText), optionalIDArg(categoryID), status, s.timeArg(publishedAt), now,
+		optionalIDArg(featuredImageID), seo.MetaTitle, seo.MetaDescription, seo.CanonicalURL, seo.OGTitle, seo.OGDescription, id,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	// Move to the new slug, keeping the old one for redirects
+	if input.Slug != "" && input.Slug != post.Slug {
+		slug, err := resolveSlug(tx, input.Slug, input.Title, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := changeSlug(tx, id, post.Slug, slug, now); err != nil {
+			return nil, err
+		}
+	}
+
+	// Replace the tags if new ones were given
+	if tags != nil {
+		if err := setPostTags(tx, id, tags); err != nil {
+			return nil, err
+		}
+	}
+
+	// Keep the new version in the revision history
+	if err := insertRevision(tx, id, input.Title, input.Content, userID, now); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	s.indexPost(id, input.Title, searchText)
+
 	// Get the updated post
-	return GetPostByID(db, id)
+	return s.GetPostByID(id)
 }
 
-// DeletePost deletes a post
-func DeletePost(db *sql.DB, id, userID int) error {
-	// Check if the post exists and belongs to the user
-	var authorID int
-	err := db.QueryRow("SELECT author_id FROM posts WHERE id = ?", id).Scan(&authorID)
+// SetPostStatus moves a post to a new lifecycle state.
+// publishAt is required when scheduling and ignored otherwise.
+func (s *SQLStore) SetPostStatus(id int, status PostStatus, publishAt *time.Time, userID int) (*Post, error) {
+	post, err := s.GetPostByID(id)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return errors.New("post not found")
-		}
-		return err
+		return nil, err
 	}
 
-	// Only the author can delete their own post
-	if authorID != userID {
-		return errors.New("unauthorized: you can only delete your own posts")
+	// Only the author or an editor can change the status of the post
+	if err := s.checkPostWriter(post.AuthorID, userID, PermEditAnyPost, "change the status of"); err != nil {
+		return nil, err
 	}
 
-	// Delete the post
-	_, err = db.Exec("DELETE FROM posts WHERE id = ?", id)
+	now := s.now()
+	publishedAt, err := transition(post.Status, post.PublishedAt, status, publishAt, now)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	_, err = s.db.Exec(
+		"UPDATE posts SET status = ?, published_at = ?, updated_at = ? WHERE id = ?",
+		status, s.timeArg(publishedAt), now, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPostByID(id)
 }
 
-// GetPostsByAuthor retrieves all posts by a specific author
-func GetPostsByAuthor(db *sql.DB, authorID int) ([]*Post, error) {
-	rows, err := db.Query(`
-		SELECT p.id, p.title, p.content, p.author_id, u.username, p.created_at, p.updated_at 
-		FROM posts p 
-		JOIN users u ON p.author_id = u.id 
-		WHERE p.author_id = ? 
-		ORDER BY p.created_at DESC`,
-		authorID,
+// PublishDuePosts publishes every scheduled post whose publication time has passed
+// and returns the posts this call published. Each post is flipped with a
+// conditional update, so when several API replicas run the scheduler at once
+// every post is published, and reported, exactly once.
+func (s *SQLStore) PublishDuePosts(now time.Time) ([]*Post, error) {
+	now = s.db.Dialect.Time(now)
+
+	// Collect the due posts first; SQLite cannot write while rows are open
+	rows, err := s.db.Query(
+		"SELECT id FROM posts WHERE status = ? AND published_at <= ?",
+		StatusScheduled, now,
 	)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var posts []*Post
+	var ids []int
 	for rows.Next() {
-		var post Post
-		if err := rows.Scan(
-			&post.ID, &post.Title, &post.Content, &post.AuthorID, &post.Author, &post.CreatedAt, &post.UpdatedAt,
-		); err != nil {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
 			return nil, err
 		}
-		posts = append(posts, &post)
+		ids = append(ids, id)
 	}
-
+	rows.Close()
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
-	return posts, nil
-}
\ No newline at end of file
+	var published []*Post
+	for _, id := range ids {
+		result, err := s.db.Exec(
+			"UPDATE posts SET status = ?, updated_at = ? WHERE id = ? AND status = ?",
+			StatusPublished, now, id, StatusScheduled,
+		)
+		if err != nil {
+			return published, err
+		}
+
+		// Another replica, or the author, got there first
+		if n, err := result.RowsAffected(); err != nil || n == 0 {
+			continue
+		}
+
+		post, err := s.GetPostByID(id)
+		if err != nil {
+			return published, err
+		}
+		published = append(published, post)
+	}
+
+	return published, nil
+}
+
+// DeletePost deletes a post
+func (s *SQLStore) DeletePost(id, userID int) error {
+	// Check if the post exists and belongs to the user
+	var authorID int
+	err := s.db.QueryRow("SELECT author_id FROM posts WHERE id = ?", id).Scan(&authorID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrPostNotFound
+		}
+		return err
+	}
+
+	// Only the author or an admin can delete the post
+	if err := s.checkPostWriter(authorID, userID, PermDeleteAnyPost, "delete"); err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Delete the comments, then the post
+	_, err = tx.Exec("DELETE FROM comments WHERE post_id = ?", id)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("DELETE FROM posts WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.unindexPost(id)
+	return nil
+}
+
+// GetPostsByAuthor retrieves all posts by a specific author that are visible to the viewer
+func (s *SQLStore) GetPostsByAuthor(authorID int, viewer Viewer) ([]*Post, error) {
+	visible, args := visibleWhere(viewer)
+	return s.queryPosts(postSelect+`
+		WHERE p.author_id = ? AND `+visible+`
+		ORDER BY p.created_at DESC`,
+		append([]interface{}{authorID}, args...)...,
+	)
+}
@@ -0,0 +1,109 @@
+// backend/internal/models/revision.go
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"blog-app/internal/database"
+)
+
+// ErrRevisionNotFound is returned when a post has no revision with the requested number
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// PostRevision is an immutable snapshot of a post, saved every time it is written
+type PostRevision struct {
+	ID        int       `json:"id"`
+	PostID    int       `json:"post_id"`
+	Revision  int       `json:"revision"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	EditorID  int       `json:"editor_id"` // 0 if the editor has since been deleted
+	Editor    string    `json:"editor"`    // Username of the editor
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// GetPostRevisions retrieves every revision of a post, newest first
+func (s *SQLStore) GetPostRevisions(postID int) ([]*PostRevision, error) {
+	// Make sure the post exists so callers can tell "no post" from "no history"
+	var exists bool
+	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM posts WHERE id = ?)", postID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrPostNotFound
+	}
+
+	rows, err := s.db.Query(`
+		SELECT r.id, r.post_id, r.revision, r.title, r.content, COALESCE(r.editor_id, 0), COALESCE(u.username, ''), r.created_at
+		FROM post_revisions r
+		LEFT JOIN users u ON r.editor_id = u.id
+		WHERE r.post_id = ?
+		ORDER BY r.revision DESC`,
+		postID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []*PostRevision
+	for rows.Next() {
+		var rev PostRevision
+		if err := rows.Scan(
+			&rev.ID, &rev.PostID, &rev.Revision, &rev.Title, &rev.Content, &rev.EditorID, &rev.Editor, &rev.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, &rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// GetPostRevision retrieves a single revision of a post
+func (s *SQLStore) GetPostRevision(postID, revision int) (*PostRevision, error) {
+	var rev PostRevision
+	err := s.db.QueryRow(`
+		SELECT r.id, r.post_id, r.revision, r.title, r.content, COALESCE(r.editor_id, 0), COALESCE(u.username, ''), r.created_at
+		FROM post_revisions r
+		LEFT JOIN users u ON r.editor_id = u.id
+		WHERE r.post_id = ? AND r.revision = ?`,
+		postID, revision,
+	).Scan(
+		&rev.ID, &rev.PostID, &rev.Revision, &rev.Title, &rev.Content, &rev.EditorID, &rev.Editor, &rev.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrRevisionNotFound
+		}
+		return nil, err
+	}
+	return &rev, nil
+}
+
+// insertRevision appends the next revision of a post inside an open transaction.
+// The number comes from the post's revision counter, whose row stays locked
+// until the transaction ends, so concurrent saves cannot pick the same one.
+func insertRevision(tx *database.Tx, postID int, title, content string, editorID int, createdAt time.Time) error {
+	_, err := tx.Exec("UPDATE posts SET revision = revision + 1 WHERE id = ?", postID)
+	if err != nil {
+		return err
+	}
+	var revision int
+	if err := tx.QueryRow("SELECT revision FROM posts WHERE id = ?", postID).Scan(&revision); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		"INSERT INTO post_revisions (post_id, revision, title, content, editor_id, created_at) VALUES (?, ?, ?, ?, ?, ?)",
+		postID, revision, title, content, editorID, createdAt,
+	)
+	return err
+}
@@ -0,0 +1,43 @@
+// backend/internal/models/revision_test.go
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPostRevisions(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		author := mustCreateUser(t, store, "alice")
+		post := mustCreatePost(t, store, author, PostInput{Title: "One", Content: "First"})
+		other := mustCreatePost(t, store, author, PostInput{Title: "Other", Content: "Elsewhere"})
+		for _, title := range []string{"Two", "Three"} {
+			if _, err := store.UpdatePost(post.ID, PostInput{Title: title, Content: title}, author.ID); err != nil {
+				t.Fatalf("UpdatePost() error = %v", err)
+			}
+		}
+
+		// Revisions are numbered per post, newest first
+		revisions, err := store.GetPostRevisions(post.ID)
+		if err != nil {
+			t.Fatalf("GetPostRevisions() error = %v", err)
+		}
+		want := []string{"Three", "Two", "One"}
+		if len(revisions) != len(want) {
+			t.Fatalf("GetPostRevisions() returned %d revisions, want %d", len(revisions), len(want))
+		}
+		for i, rev := range revisions {
+			if rev.Revision != len(want)-i || rev.Title != want[i] || rev.EditorID != author.ID {
+				t.Errorf("revision %d = #%d %q by %d, want #%d %q by %d", i, rev.Revision, rev.Title, rev.EditorID, len(want)-i, want[i], author.ID)
+			}
+		}
+
+		rev, err := store.GetPostRevision(other.ID, 1)
+		if err != nil || rev.Title != "Other" {
+			t.Errorf("GetPostRevision(other, 1) = %v, %v, want the first revision of the other post", rev, err)
+		}
+		if _, err := store.GetPostRevision(other.ID, 2); !errors.Is(err, ErrRevisionNotFound) {
+			t.Errorf("GetPostRevision(other, 2) error = %v, want %v", err, ErrRevisionNotFound)
+		}
+	})
+}
@@ -0,0 +1,146 @@
+// backend/internal/models/role.go
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Errors returned by role operations
+var (
+	ErrInvalidUserRole = errors.New("role must be admin, editor, author or reader")
+	ErrLastAdmin       = errors.New("there must be at least one admin")
+)
+
+// Role is what a user may do across the blog. Roles on a single website are
+// granted separately with WebsiteRole.
+type Role string
+
+// User roles, from most to least trusted
+const (
+	RoleAdmin  Role = "admin"  // Manages users and everything editors do
+	RoleEditor Role = "editor" // Edits and moderates everyone's posts
+	RoleAuthor Role = "author" // Writes their own posts
+	RoleReader Role = "reader" // Reads and comments
+)
+
+// DefaultRole is the role given to newly registered users
+const DefaultRole = RoleAuthor
+
+// Permission is something a user's role may allow
+type Permission string
+
+// User permissions
+const (
+	PermCreatePosts      Permission = "posts:create"      // Write new posts
+	PermEditAnyPost      Permission = "posts:edit_any"    // Update, restore and change the status of others' posts
+	PermDeleteAnyPost    Permission = "posts:delete_any"  // Delete others' posts
+	PermModerateComments Permission = "comments:moderate" // Moderate comments on every post
+	PermManageCategories Permission = "categories:manage" // Create, rename, move and delete categories
+	PermManageUsers      Permission = "users:manage"      // Delete users and change their roles
+	PermAdmin            Permission = "admin"             // Use the admin routes
+)
+
+// permissions lists what each role allows
+var permissions = map[Role][]Permission{
+	RoleAdmin:  {PermCreatePosts, PermEditAnyPost, PermDeleteAnyPost, PermModerateComments, PermManageCategories, PermManageUsers, PermAdmin},
+	RoleEditor: {PermCreatePosts, PermEditAnyPost, PermModerateComments, PermManageCategories},
+	RoleAuthor: {PermCreatePosts},
+	RoleReader: {},
+}
+
+// Valid reports whether r is a known role
+func (r Role) Valid() bool {
+	_, ok := permissions[r]
+	return ok
+}
+
+// Can reports whether the role allows the permission
+func (r Role) Can(perm Permission) bool {
+	for _, granted := range permissions[r] {
+		if granted == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// SetUserRole changes a user's role and logs out their sessions, so that
+// they sign in again with a token carrying the new role. The last admin
+// cannot be demoted.
+func (s *SQLStore) SetUserRole(id int, role Role) (*User, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidUserRole
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var current Role
+	err = tx.QueryRow("SELECT role FROM users WHERE id = ?", id).Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	if current == role {
+		// Nothing changes; end the transaction before reading, as SQLite has a single connection
+		tx.Rollback()
+		return s.GetUserByID(id)
+	}
+
+	// Demote an admin only while another admin remains, checked by the update
+	// itself. MySQL only reads the table being updated through a derived table.
+	now := s.now()
+	result, err := tx.Exec(`
+		UPDATE users SET role = ?, updated_at = ?
+		WHERE id = ? AND (role <> ? OR EXISTS(
+			SELECT 1 FROM (SELECT id FROM users WHERE role = ? AND id <> ?) admins
+		))`,
+		role, now, id, RoleAdmin, RoleAdmin, id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if changed != 1 {
+		return nil, ErrLastAdmin
+	}
+
+	// Log out the user's sessions
+	_, err = tx.Exec("UPDATE refresh_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL", now, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.GetUserByID(id)
+}
+
+// checkPostWriter returns an ErrNotAuthor error unless the user wrote the post
+// or their current role allows perm on the posts of others
+func (s *SQLStore) checkPostWriter(authorID, userID int, perm Permission, action string) error {
+	if authorID == userID {
+		return nil
+	}
+
+	var role Role
+	err := s.db.QueryRow("SELECT role FROM users WHERE id = ?", userID).Scan(&role)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+	if role.Can(perm) {
+		return nil
+	}
+	return fmt.Errorf("%w: you can only %s your own posts", ErrNotAuthor, action)
+}
@@ -0,0 +1,357 @@
+// backend/internal/models/search.go
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"blog-app/internal/database"
+	"blog-app/internal/search"
+)
+
+// ErrEmptyQuery is returned when a search query has no words to look for
+var ErrEmptyQuery = errors.New("search query must contain at least one word of two or more letters or digits")
+
+// snippetLength is the approximate length of search result snippets, in bytes
+const snippetLength = 200
+
+// SearchResult is a post matching a search query
+type SearchResult struct {
+	Post    *Post   `json:"post"`
+	Score   float64 `json:"score"`   // Relevance; only comparable within the same search
+	Snippet string  `json:"snippet"` // HTML-escaped plain text with matching words wrapped in <mark>
+}
+
+// newSearchResult builds the result for a post, with a snippet highlighting the query terms
+func newSearchResult(post *Post, score float64, terms []string) *SearchResult {
+	return &SearchResult{
+		Post:    post,
+		Score:   score,
+		Snippet: search.Snippet(search.StripHTML(post.Content), terms, snippetLength),
+	}
+}
+
+// pageOfHits returns the given page of hits and the total number of hits
+func pageOfHits(hits []search.Hit, page, perPage int) ([]search.Hit, int) {
+	total := len(hits)
+	start := (page - 1) * perPage
+	if start >= total {
+		return nil, total
+	}
+	end := start + perPage
+	if end > total {
+		end = total
+	}
+	return hits[start:end], total
+}
+
+// SearchPosts retrieves a page of posts visible to the viewer that match the
+// query, best match first, and the total number of matches. MySQL uses its
+// FULLTEXT index and PostgreSQL its GIN-indexed search_vector column; SQLite
+// uses an in-memory index built from posts.search_text.
+func (s *SQLStore) SearchPosts(query string, viewer Viewer, page, perPage int) ([]*SearchResult, int, error) {
+	terms := search.Tokenize(query)
+	if len(terms) == 0 {
+		return nil, 0, ErrEmptyQuery
+	}
+
+	if err := s.prepareSearch(); err != nil {
+		return nil, 0, err
+	}
+
+	var hits []search.Hit
+	var total int
+	var err error
+	switch s.db.Dialect {
+	case database.MySQL:
+		hits, total, err = s.fullTextSearch(query, viewer, page, perPage)
+	case database.Postgres:
+		hits, total, err = s.vectorSearch(terms, viewer, page, perPage)
+	default:
+		hits, total, err = s.indexSearch(query, viewer, page, perPage)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Load the posts on the page
+	results := []*SearchResult{}
+	if len(hits) == 0 {
+		return results, total, nil
+	}
+	args := make([]interface{}, len(hits))
+	for i, hit := range hits {
+		args[i] = hit.ID
+	}
+	posts, err := s.queryPosts(postSelect+`
+		WHERE p.id IN (?`+strings.Repeat(", ?", len(hits)-1)+`)`,
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[int]*Post, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
+	}
+
+	for _, hit := range hits {
+		if post, ok := byID[hit.ID]; ok {
+			results = append(results, newSearchResult(post, hit.Score, terms))
+		}
+	}
+
+	return results, total, nil
+}
+
+// fullTextSearch runs a search against the MySQL FULLTEXT index
+func (s *SQLStore) fullTextSearch(query string, viewer Viewer, page, perPage int) ([]search.Hit, int, error) {
+	const match = "MATCH(p.title, p.search_text) AGAINST (? IN NATURAL LANGUAGE MODE)"
+	visible, visibleArgs := visibleWhere(viewer)
+
+	var total int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM posts p
+		WHERE `+match+` AND `+visible,
+		append([]interface{}{query}, visibleArgs...)...,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT p.id, `+match+` AS score FROM posts p
+		WHERE `+match+` AND `+visible+`
+		ORDER BY score DESC, p.id DESC
+		LIMIT ? OFFSET ?`,
+		append(append([]interface{}{query, query}, visibleArgs...), perPage, (page-1)*perPage)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	hits, err := scanHits(rows)
+	return hits, total, err
+}
+
+// vectorSearch runs a search against the PostgreSQL search_vector column.
+// Like the other backends, a post matches if it contains any of the terms.
+func (s *SQLStore) vectorSearch(terms []string, viewer Viewer, page, perPage int) ([]search.Hit, int, error) {
+	// Terms are made of letters and digits only, so they need no quoting
+	query := strings.Join(terms, " | ")
+	const match = "p.search_vector @@ to_tsquery('simple', ?)"
+	visible, visibleArgs := visibleWhere(viewer)
+
+	var total int
+	err := s.db.QueryRow(`
+		SELECT COUNT(*) FROM posts p
+		WHERE `+match+` AND `+visible,
+		append([]interface{}{query}, visibleArgs...)...,
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT p.id, ts_rank(p.search_vector, to_tsquery('simple', ?)) AS score FROM posts p
+		WHERE `+match+` AND `+visible+`
+		ORDER BY score DESC, p.id DESC
+		LIMIT ? OFFSET ?`,
+		append(append([]interface{}{query, query}, visibleArgs...), perPage, (page-1)*perPage)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	hits, err := scanHits(rows)
+	return hits, total, err
+}
+
+// scanHits reads the id and score columns of a search query, closing the rows
+func scanHits(rows *sql.Rows) ([]search.Hit, error) {
+	defer rows.Close()
+
+	var hits []search.Hit
+	for rows.Next() {
+		var hit search.Hit
+		if err := rows.Scan(&hit.ID, &hit.Score); err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+	return hits, rows.Err()
+}
+
+// indexSearch runs a search against the in-memory index, keeping only the
+// posts visible to the viewer
+func (s *SQLStore) indexSearch(query string, viewer Viewer, page, perPage int) ([]search.Hit, int, error) {
+	s.searchMu.Lock()
+	matches := s.searchIndex.Search(query)
+	s.searchMu.Unlock()
+
+	// Look up the visibility of the matching posts only, a batch at a time
+	where, whereArgs := visibleWhere(viewer)
+	visible := make(map[int]bool)
+	for start := 0; start < len(matches); start += searchBatchSize {
+		batch := matches[start:]
+		if len(batch) > searchBatchSize {
+			batch = batch[:searchBatchSize]
+		}
+		args := make([]interface{}, 0, len(batch)+2)
+		for _, hit := range batch {
+			args = append(args, hit.ID)
+		}
+		args = append(args, whereArgs...)
+
+		rows, err := s.db.Query(`
+			SELECT p.id FROM posts p
+			WHERE p.id IN (?`+strings.Repeat(", ?", len(batch)-1)+`) AND `+where,
+			args...,
+		)
+		if err != nil {
+			return nil, 0, err
+		}
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, 0, err
+			}
+			visible[id] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	var hits []search.Hit
+	for _, hit := range matches {
+		if visible[hit.ID] {
+			hits = append(hits, hit)
+		}
+	}
+
+	hits, total := pageOfHits(hits, page, perPage)
+	return hits, total, nil
+}
+
+// searchBatchSize is how many matching posts indexSearch checks per query
+const searchBatchSize = 500
+
+// prepareSearch builds the in-memory index on SQLite, and rebuilds it from
+// posts.search_text whenever another process, such as a second API server,
+// has written to the database since
+func (s *SQLStore) prepareSearch() error {
+	s.searchMu.Lock()
+	defer s.searchMu.Unlock()
+
+	if s.db.Dialect != database.SQLite {
+		return nil
+	}
+
+	// data_version changes when another connection commits, but not when this one does;
+	// the store's own writes update the index as they are made
+	var version int64
+	if err := s.db.QueryRow("PRAGMA data_version").Scan(&version); err != nil {
+		return err
+	}
+	if s.searchIndex != nil && version == s.searchVersion {
+		return nil
+	}
+
+	index := search.NewIndex()
+	rows, err := s.db.Query("SELECT id, title, COALESCE(search_text, '') FROM posts")
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var title, text string
+		if err := rows.Scan(&id, &title, &text); err != nil {
+			return err
+		}
+		index.Add(id, title, text)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	s.searchIndex = index
+	s.searchVersion = version
+	return nil
+}
+
+// FillSearchText strips the HTML from posts that have no search text or word
+// count yet, which are the posts written before search and word counts
+// existed, and returns how many it filled in. It is run once after the
+// migrations that add them.
+func (s *SQLStore) FillSearchText() (int, error) {
+	type pending struct {
+		id      int
+		content string
+	}
+
+	filled := 0
+	for {
+		rows, err := s.db.Query("SELECT id, content FROM posts WHERE search_text IS NULL OR word_count IS NULL ORDER BY id LIMIT ?", searchBatchSize)
+		if err != nil {
+			return filled, err
+		}
+		var posts []pending
+		for rows.Next() {
+			var p pending
+			if err := rows.Scan(&p.id, &p.content); err != nil {
+				rows.Close()
+				return filled, err
+			}
+			posts = append(posts, p)
+		}
+
+		// Close the rows before updating; SQLite has a single connection
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return filled, err
+		}
+		if len(posts) == 0 {
+			break
+		}
+
+		for _, p := range posts {
+			text := search.StripHTML(p.content)
+			if _, err := s.db.Exec("UPDATE posts SET search_text = ?, word_count = ? WHERE id = ?", text, wordCount(text), p.id); err != nil {
+				return filled, err
+			}
+			filled++
+		}
+	}
+
+	// Rebuild the SQLite index on the next search
+	s.searchMu.Lock()
+	s.searchIndex = nil
+	s.searchMu.Unlock()
+	return filled, nil
+}
+
+// indexPost updates the in-memory search index, if it has been built, after a post was saved
+func (s *SQLStore) indexPost(id int, title, text string) {
+	s.searchMu.Lock()
+	defer s.searchMu.Unlock()
+
+	if s.searchIndex != nil {
+		s.searchIndex.Add(id, title, text)
+	}
+}
+
+// unindexPost removes deleted posts from the in-memory search index, if it has been built
+func (s *SQLStore) unindexPost(ids ...int) {
+	s.searchMu.Lock()
+	defer s.searchMu.Unlock()
+
+	if s.searchIndex != nil {
+		for _, id := range ids {
+			s.searchIndex.Remove(id)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// backend/internal/models/search_test.go
+package models
+
+import (
+	"testing"
+)
+
+func TestFillSearchText(t *testing.T) {
+	store := newSQLiteStore(t)
+	alice := mustCreateUser(t, store, "alice")
+	post := mustCreatePost(t, store, alice, PostInput{Title: "Older post", Content: "<p>Written before <b>search</b> existed</p>", Status: StatusPublished})
+
+	// Posts written before the search and word count migrations have neither
+	if _, err := store.db.Exec("UPDATE posts SET search_text = NULL, word_count = NULL"); err != nil {
+		t.Fatalf("failed to clear search text: %v", err)
+	}
+	store.searchIndex = nil
+
+	if _, total, err := store.SearchPosts("existed", Viewer{}, 1, 10); err != nil || total != 0 {
+		t.Fatalf("SearchPosts() before filling = %d results, %v; want none", total, err)
+	}
+
+	filled, err := store.FillSearchText()
+	if err != nil {
+		t.Fatalf("FillSearchText() error = %v", err)
+	}
+	if filled != 1 {
+		t.Errorf("FillSearchText() = %d, want 1", filled)
+	}
+
+	var text string
+	var words int
+	if err := store.db.QueryRow("SELECT search_text, word_count FROM posts WHERE id = ?", post.ID).Scan(&text, &words); err != nil {
+		t.Fatalf("failed to read search text: %v", err)
+	}
+	if want := "Written before search existed"; text != want || words != 4 {
+		t.Errorf("search_text = %q with %d words, want %q with 4", text, words, want)
+	}
+
+	results, total, err := store.SearchPosts("existed", Viewer{}, 1, 10)
+	if err != nil {
+		t.Fatalf("SearchPosts() error = %v", err)
+	}
+	if total != 1 || results[0].Post.ID != post.ID {
+		t.Errorf("SearchPosts() after filling = %d results, want post %d", total, post.ID)
+	}
+
+	// A second run finds nothing left to fill
+	if filled, err := store.FillSearchText(); err != nil || filled != 0 {
+		t.Errorf("second FillSearchText() = %d, %v; want 0, nil", filled, err)
+	}
+}
@@ -0,0 +1,104 @@
+// backend/internal/models/seo.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+// Errors returned for invalid post metadata
+var (
+	ErrInvalidSEO           = errors.New("invalid SEO metadata")
+	ErrFeaturedImageMissing = errors.New("featured image does not exist")
+)
+
+// SEO field limits, in characters
+const (
+	MaxSEOTitleLength       = 100
+	MaxSEODescriptionLength = 300
+	MaxCanonicalURLLength   = 2000
+)
+
+// PostSEO holds the optional metadata that search engines and social networks
+// show for a post. Empty fields fall back to the post's title, excerpt and
+// address when the post is rendered.
+type PostSEO struct {
+	MetaTitle       string `json:"meta_title"`
+	MetaDescription string `json:"meta_description"`
+	CanonicalURL    string `json:"canonical_url"`  // Absolute http or https URL of the original, for cross-posted content
+	OGTitle         string `json:"og_title"`       // Open Graph title for link previews
+	OGDescription   string `json:"og_description"` // Open Graph description for link previews
+}
+
+// Normalize trims the surrounding whitespace from every field
+func (s PostSEO) Normalize() PostSEO {
+	return PostSEO{
+		MetaTitle:       strings.TrimSpace(s.MetaTitle),
+		MetaDescription: strings.TrimSpace(s.MetaDescription),
+		CanonicalURL:    strings.TrimSpace(s.CanonicalURL),
+		OGTitle:         strings.TrimSpace(s.OGTitle),
+		OGDescription:   strings.TrimSpace(s.OGDescription),
+	}
+}
+
+// Validate checks the length of every field and that the canonical URL is absolute
+func (s PostSEO) Validate() error {
+	fields := []struct {
+		name, value string
+		max         int
+	}{
+		{"meta_title", s.MetaTitle, MaxSEOTitleLength},
+		{"meta_description", s.MetaDescription, MaxSEODescriptionLength},
+		{"canonical_url", s.CanonicalURL, MaxCanonicalURLLength},
+		{"og_title", s.OGTitle, MaxSEOTitleLength},
+		{"og_description", s.OGDescription, MaxSEODescriptionLength},
+	}
+	for _, f := range fields {
+		if utf8.RuneCountInString(f.value) > f.max {
+			return fmt.Errorf("%w: %s must be at most %d characters", ErrInvalidSEO, f.name, f.max)
+		}
+		if strings.ContainsAny(f.value, "\r\n") {
+			return fmt.Errorf("%w: %s must be a single line", ErrInvalidSEO, f.name)
+		}
+	}
+
+	if s.CanonicalURL != "" {
+		u, err := url.Parse(s.CanonicalURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("%w: canonical_url must be an absolute http or https URL", ErrInvalidSEO)
+		}
+	}
+
+	return nil
+}
+
+// seo returns the cleaned up metadata the input asks for, or current if it leaves it alone
+func (in PostInput) seo(current PostSEO) (PostSEO, error) {
+	if in.SEO == nil {
+		return current, nil
+	}
+	seo := in.SEO.Normalize()
+	if err := seo.Validate(); err != nil {
+		return PostSEO{}, err
+	}
+	return seo, nil
+}
+
+// checkFeaturedImage returns ErrFeaturedImageMissing unless the featured image exists.
+// Nil and 0 mean no image.
+func (s *SQLStore) checkFeaturedImage(id *int) error {
+	if id == nil || *id == 0 {
+		return nil
+	}
+	exists, err := s.mediaExists(*id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrFeaturedImageMissing
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+// backend/internal/models/slug.go
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"blog-app/internal/database"
+)
+
+// Errors returned by slug changes
+var (
+	ErrInvalidSlug = errors.New("slugs may only contain lowercase letters, digits and single hyphens")
+	ErrSlugTaken   = errors.New("slug is already in use")
+)
+
+// maxSlugLength keeps generated slugs well inside the VARCHAR(255) column
+const maxSlugLength = 100
+
+// accents folds common accented Latin letters to their plain ASCII form
+var accents = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "æ", "ae",
+	"ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "œ", "oe",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ý", "y", "ÿ", "y", "ß", "ss",
+)
+
+// Slugify turns a title into a URL-friendly slug, e.g. "Hello, World!" becomes "hello-world".
+// Common accents are folded and anything else that is not a letter or digit becomes a hyphen.
+func Slugify(title string) string {
+	if slug := slugify(title, maxSlugLength); slug != "" {
+		return slug
+	}
+	return "post"
+}
+
+// slugify does the work of Slugify, returning "" when nothing usable is left
+func slugify(title string, maxLength int) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range accents.Replace(strings.ToLower(title)) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+			continue
+		}
+		hyphen = true
+	}
+
+	slug := b.String()
+	if len(slug) > maxLength {
+		slug = strings.TrimRight(slug[:maxLength], "-")
+	}
+	return slug
+}
+
+// ValidSlug reports whether s is already in the form Slugify produces
+func ValidSlug(s string) bool {
+	if s == "" || len(s) > maxSlugLength || s[0] == '-' || s[len(s)-1] == '-' || strings.Contains(s, "--") {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// uniqueSlug returns base, or base with the lowest numeric suffix that is not taken
+func uniqueSlug(base string, taken func(slug string) (bool, error)) (string, error) {
+	slug := base
+	for n := 2; ; n++ {
+		used, err := taken(slug)
+		if err != nil {
+			return "", err
+		}
+		if !used {
+			return slug, nil
+		}
+		slug = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// GetPostBySlug retrieves a post by its current slug or by one it used to have.
+// Callers can compare Post.Slug with the requested slug to detect a redirect.
+func (s *SQLStore) GetPostBySlug(slug string) (*Post, error) {
+	post, err := scanPost(s.db.QueryRow(postSelect+`
+		WHERE p.slug = ?`,
+		slug,
+	))
+	if err == nil {
+		if err := s.attachTags([]*Post{post}); err != nil {
+			return nil, err
+		}
+		return post, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	// Fall back to the slug history
+	var postID int
+	err = s.db.QueryRow("SELECT post_id FROM post_slugs WHERE slug = ?", slug).Scan(&postID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPostNotFound
+		}
+		return nil, err
+	}
+	return s.GetPostByID(postID)
+}
+
+// GetPostSlugHistory retrieves the previous slugs of a post, newest first
+func (s *SQLStore) GetPostSlugHistory(postID int) ([]string, error) {
+	rows, err := s.db.Query("SELECT slug FROM post_slugs WHERE post_id = ? ORDER BY id DESC", postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slugs []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+		slugs = append(slugs, slug)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return slugs, nil
+}
+
+// slugTaken reports whether a slug is used, now or in the past, by a post other than postID.
+// Pass a postID of 0 when creating a post.
+func slugTaken(tx *database.Tx, slug string, postID int) (bool, error) {
+	var taken bool
+	err := tx.QueryRow(`
+		SELECT EXISTS(SELECT 1 FROM posts WHERE slug = ? AND id <> ?)
+			OR EXISTS(SELECT 1 FROM post_slugs WHERE slug = ? AND post_id <> ?)`,
+		slug, postID, slug, postID,
+	).Scan(&taken)
+	return taken, err
+}
+
+// resolveSlug works out the slug to store for a post inside an open transaction.
+// An explicit slug must be valid and free; otherwise one is generated from the title.
+func resolveSlug(tx *database.Tx, requested, title string, postID int) (string, error) {
+	taken := func(slug string) (bool, error) { return slugTaken(tx, slug, postID) }
+
+	if requested == "" {
+		return uniqueSlug(Slugify(title), taken)
+	}
+	if !ValidSlug(requested) {
+		return "", ErrInvalidSlug
+	}
+	used, err := taken(requested)
+	if err != nil {
+		return "", err
+	}
+	if used {
+		return "", ErrSlugTaken
+	}
+	return requested, nil
+}
+
+// changeSlug moves a post to a new slug inside an open transaction, keeping the
+// old one in the history so it keeps resolving. A post may take back one of its
+// own old slugs, which then leaves the history.
+func changeSlug(tx *database.Tx, postID int, from, to string, now time.Time) error {
+	if from == to {
+		return nil
+	}
+
+	if _, err := tx.Exec("DELETE FROM post_slugs WHERE post_id = ? AND slug = ?", postID, to); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		"INSERT INTO post_slugs (post_id, slug, created_at) VALUES (?, ?, ?)",
+		postID, from, now,
+	); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec("UPDATE posts SET slug = ? WHERE id = ?", to, postID)
+	return err
+}
@@ -0,0 +1,104 @@
+// backend/internal/models/status.go
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PostStatus is the lifecycle state of a post
+type PostStatus string
+
+// Post lifecycle states
+const (
+	StatusDraft     PostStatus = "draft"
+	StatusPublished PostStatus = "published"
+	StatusScheduled PostStatus = "scheduled"
+	StatusArchived  PostStatus = "archived"
+)
+
+// Errors returned by status changes
+var (
+	ErrInvalidStatus     = errors.New("invalid post status")
+	ErrInvalidTransition = errors.New("invalid status change")
+	ErrPublishAtRequired = errors.New("a future publish_at time is required to schedule a post")
+)
+
+// transitions lists the states each state may move to
+var transitions = map[PostStatus][]PostStatus{
+	StatusDraft:     {StatusPublished, StatusScheduled, StatusArchived},
+	StatusScheduled: {StatusDraft, StatusPublished, StatusScheduled, StatusArchived},
+	StatusPublished: {StatusDraft, StatusArchived},
+	StatusArchived:  {StatusDraft, StatusPublished},
+}
+
+// Valid reports whether s is a known status
+func (s PostStatus) Valid() bool {
+	_, ok := transitions[s]
+	return ok
+}
+
+// CanTransition reports whether a post may move from one status to another
+func CanTransition(from, to PostStatus) bool {
+	for _, allowed := range transitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Viewer is the user posts are shown to. The zero Viewer is an anonymous reader.
+type Viewer struct {
+	ID   int
+	Role Role
+}
+
+// VisibleTo reports whether the viewer may see the post.
+// Published posts are public; every other state is only visible to the author
+// and to users who may edit any post.
+func (p *Post) VisibleTo(v Viewer) bool {
+	return p.Status == StatusPublished || p.AuthorID == v.ID || v.Role.Can(PermEditAnyPost)
+}
+
+// visibleWhere is the SQL condition matching VisibleTo for posts aliased as p
+func visibleWhere(v Viewer) (string, []interface{}) {
+	if v.Role.Can(PermEditAnyPost) {
+		return "1 = 1", nil
+	}
+	return "(p.status = ? OR p.author_id = ?)", []interface{}{StatusPublished, v.ID}
+}
+
+// transition validates a status change and works out the new published_at.
+// Moving to the current status is a no-op, except for rescheduling.
+// For scheduled posts published_at holds the time the post will go live.
+func transition(from PostStatus, publishedAt *time.Time, to PostStatus, publishAt *time.Time, now time.Time) (*time.Time, error) {
+	if !to.Valid() {
+		return nil, ErrInvalidStatus
+	}
+	if from == to && to != StatusScheduled {
+		return publishedAt, nil
+	}
+	if !CanTransition(from, to) {
+		return nil, fmt.Errorf("%w from %s to %s", ErrInvalidTransition, from, to)
+	}
+
+	switch to {
+	case StatusPublished:
+		// Republishing an archived post keeps its original publication date
+		if from == StatusArchived && publishedAt != nil {
+			return publishedAt, nil
+		}
+		return &now, nil
+	case StatusScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return nil, ErrPublishAtRequired
+		}
+		return publishAt, nil
+	case StatusDraft:
+		return nil, nil
+	default:
+		return publishedAt, nil
+	}
+}
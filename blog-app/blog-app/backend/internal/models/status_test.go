@@ -0,0 +1,180 @@
+// backend/internal/models/status_test.go
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPostVisibility(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		mustCreateUser(t, store, "admin")
+		alice := mustCreateUser(t, store, "alice")
+		bob := mustCreateUser(t, store, "bob")
+		editor := mustCreateUser(t, store, "editor")
+		mustSetRole(t, store, editor, RoleEditor)
+
+		published := mustCreatePost(t, store, alice, PostInput{Title: "Published", Content: "Shared words", Status: StatusPublished, Tags: []string{"public"}})
+		draft := mustCreatePost(t, store, alice, PostInput{Title: "Draft", Content: "Shared words", Tags: []string{"private"}})
+
+		// Single posts, listings, tags and search must agree on what each viewer sees
+		tests := []struct {
+			name   string
+			viewer Viewer
+			want   []*Post
+		}{
+			{"anonymous", Viewer{}, []*Post{published}},
+			{"author", Viewer{ID: alice.ID, Role: alice.Role}, []*Post{published, draft}},
+			{"other author", Viewer{ID: bob.ID, Role: bob.Role}, []*Post{published}},
+			{"editor", Viewer{ID: editor.ID, Role: editor.Role}, []*Post{published, draft}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				want := make(map[int]bool)
+				for _, post := range tt.want {
+					want[post.ID] = true
+				}
+
+				for _, post := range []*Post{published, draft} {
+					if got := post.VisibleTo(tt.viewer); got != want[post.ID] {
+						t.Errorf("VisibleTo(%q) = %v, want %v", post.Title, got, want[post.ID])
+					}
+				}
+
+				page, err := store.ListPosts(PostFilter{}, tt.viewer)
+				if err != nil {
+					t.Fatalf("ListPosts() error = %v", err)
+				}
+				listed := make([]*Post, len(page.Posts))
+				for i, summary := range page.Posts {
+					listed[i] = &Post{ID: summary.ID}
+				}
+				checkPostIDs(t, "ListPosts", listed, want)
+
+				posts, err := store.GetPosts(tt.viewer)
+				if err != nil {
+					t.Fatalf("GetPosts() error = %v", err)
+				}
+				checkPostIDs(t, "GetPosts", posts, want)
+
+				posts, err = store.GetPostsByAuthor(alice.ID, tt.viewer)
+				if err != nil {
+					t.Fatalf("GetPostsByAuthor() error = %v", err)
+				}
+				checkPostIDs(t, "GetPostsByAuthor", posts, want)
+
+				results, total, err := store.SearchPosts("shared", tt.viewer, 1, 10)
+				if err != nil {
+					t.Fatalf("SearchPosts() error = %v", err)
+				}
+				if total != len(want) {
+					t.Errorf("SearchPosts() total = %d, want %d", total, len(want))
+				}
+				var found []*Post
+				for _, result := range results {
+					found = append(found, result.Post)
+				}
+				checkPostIDs(t, "SearchPosts", found, want)
+
+				tags, err := store.GetTags(tt.viewer)
+				if err != nil {
+					t.Fatalf("GetTags() error = %v", err)
+				}
+				if len(tags) != len(want) {
+					t.Errorf("GetTags() returned %d tags, want %d", len(tags), len(want))
+				}
+			})
+		}
+	})
+}
+
+// checkPostIDs reports an error unless posts holds exactly the posts in want
+func checkPostIDs(t *testing.T, what string, posts []*Post, want map[int]bool) {
+	t.Helper()
+
+	got := make(map[int]bool)
+	for _, post := range posts {
+		got[post.ID] = true
+	}
+	if len(got) != len(want) {
+		t.Errorf("%s returned %d posts, want %d", what, len(got), len(want))
+		return
+	}
+	for id := range want {
+		if !got[id] {
+			t.Errorf("%s is missing post %d", what, id)
+		}
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from, to PostStatus
+		want     bool
+	}{
+		{StatusDraft, StatusPublished, true},
+		{StatusDraft, StatusScheduled, true},
+		{StatusPublished, StatusArchived, true},
+		{StatusArchived, StatusScheduled, false},
+	}
+	for _, tt := range tests {
+		if got := CanTransition(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestPublishDuePosts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		alice := mustCreateUser(t, store, "alice")
+
+		now := time.Now().UTC().Truncate(time.Second)
+		soon, later := now.Add(time.Hour), now.Add(2*time.Hour)
+		first := mustCreatePost(t, store, alice, PostInput{Title: "Soon", Content: "Soon", PublishAt: &soon})
+		second := mustCreatePost(t, store, alice, PostInput{Title: "Later", Content: "Later", PublishAt: &later})
+		mustCreatePost(t, store, alice, PostInput{Title: "Draft", Content: "Draft"})
+
+		// publish runs the scheduler at the given time and checks what it published
+		publish := func(at time.Time, want ...*Post) {
+			t.Helper()
+
+			posts, err := store.PublishDuePosts(at)
+			if err != nil {
+				t.Fatalf("PublishDuePosts(%v) error = %v", at, err)
+			}
+			wantIDs := make(map[int]bool)
+			for _, post := range want {
+				wantIDs[post.ID] = true
+			}
+			checkPostIDs(t, "PublishDuePosts", posts, wantIDs)
+			for _, post := range posts {
+				if post.Status != StatusPublished {
+					t.Errorf("PublishDuePosts() returned post %d as %s, want published", post.ID, post.Status)
+				}
+			}
+		}
+
+		// Nothing is due yet
+		publish(now)
+		if post, err := store.GetPostByID(first.ID); err != nil || post.VisibleTo(Viewer{}) {
+			t.Fatalf("scheduled post visible before its time, err = %v", err)
+		}
+
+		// The first post falls due, and is published only once
+		publish(soon.Add(time.Minute), first)
+		publish(soon.Add(time.Minute))
+		post, err := store.GetPostByID(first.ID)
+		if err != nil {
+			t.Fatalf("GetPostByID() error = %v", err)
+		}
+		if post.Status != StatusPublished || !post.VisibleTo(Viewer{}) {
+			t.Errorf("due post is %s, want published and visible", post.Status)
+		}
+		if post.PublishedAt == nil || !post.PublishedAt.Equal(soon) {
+			t.Errorf("due post published_at = %v, want the scheduled time %v", post.PublishedAt, soon)
+		}
+
+		// A run after a long outage catches up on the rest
+		publish(later.Add(24*time.Hour), second)
+	})
+}
@@ -0,0 +1,204 @@
+// backend/internal/models/store.go
+package models
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"blog-app/internal/database"
+	"blog-app/internal/search"
+)
+
+// Errors shared by every store implementation
+var (
+	ErrUserNotFound = errors.New("user not found")
+	ErrUserExists   = errors.New("username or email already exists")
+	ErrPostNotFound = errors.New("post not found")
+	ErrNoAuthor     = errors.New("author does not exist")
+	ErrNotAuthor    = errors.New("unauthorized")
+)
+
+// UserStore is the persistence interface for users
+type UserStore interface {
+	CreateUser(username, email, password string) (*User, error)
+	GetUserByID(id int) (*User, error)
+	GetUserByEmail(email string) (*User, error)
+	GetUsers() ([]*UserResponse, error)
+	SetUserRole(id int, role Role) (*User, error)
+	DeleteUser(id, userID int) error
+}
+
+// PostStore is the persistence interface for posts.
+// Listing methods take the user asking, so unpublished posts are only returned
+// to those allowed to see them (see Post.VisibleTo); pass the zero Viewer for
+// anonymous readers.
+type PostStore interface {
+	CreatePost(input PostInput, authorID int) (*Post, error)
+	GetPostByID(id int) (*Post, error)
+	GetPostBySlug(slug string) (*Post, error)
+	GetPostSlugHistory(postID int) ([]string, error)
+	GetPosts(viewer Viewer) ([]*Post, error)
+	ListPosts(filter PostFilter, viewer Viewer) (*PostPage, error)
+	GetPostsByAuthor(authorID int, viewer Viewer) ([]*Post, error)
+	UpdatePost(id int, input PostInput, userID int) (*Post, error)
+	SetPostStatus(id int, status PostStatus, publishAt *time.Time, userID int) (*Post, error)
+	PublishDuePosts(now time.Time) ([]*Post, error)
+	DeletePost(id, userID int) error
+}
+
+// RevisionStore is the persistence interface for post revision history.
+// Revisions are written by PostStore.CreatePost and PostStore.UpdatePost.
+type RevisionStore interface {
+	GetPostRevisions(postID int) ([]*PostRevision, error)
+	GetPostRevision(postID, revision int) (*PostRevision, error)
+}
+
+// TagStore is the persistence interface for tags.
+// Tags are assigned through PostInput.Tags.
+type TagStore interface {
+	GetTags(viewer Viewer) ([]*Tag, error)
+}
+
+// CategoryStore is the persistence interface for the category tree.
+// Posts are assigned to a category through PostInput.CategoryID.
+type CategoryStore interface {
+	GetCategories() ([]*Category, error)
+	GetCategory(id int) (*Category, error)
+	CreateCategory(name string, parentID *int) (*Category, error)
+	UpdateCategory(id int, name string) (*Category, error)
+	MoveCategory(id int, parentID *int, position int) (*Category, error)
+	DeleteCategory(id int) error
+}
+
+// CommentStore is the persistence interface for threaded comments and their moderation.
+// Comments are deleted along with their post.
+type CommentStore interface {
+	GetComments(postID, viewerID int, moderator bool, page, perPage int) ([]*Comment, int, error)
+	GetComment(id int) (*Comment, error)
+	CreateComment(postID int, input CommentInput, authorID int) (*Comment, error)
+	UpdateComment(id int, input CommentInput, userID int) (*Comment, error)
+	DeleteComment(id, userID int) error
+	GetModerationQueue(status CommentStatus, postAuthorID, page, perPage int) ([]*Comment, int, error)
+	ModerateComment(id int, status CommentStatus, moderatorID int) (*Comment, error)
+}
+
+// SearchStore is the persistence interface for full-text search over posts.
+// Posts are indexed as they are created, updated and deleted.
+type SearchStore interface {
+	SearchPosts(query string, viewer Viewer, page, perPage int) ([]*SearchResult, int, error)
+}
+
+// MediaStore is the persistence interface for uploaded media.
+// It records the renditions of each upload; storing the files is up to the caller.
+type MediaStore interface {
+	CreateMedia(ownerID int, filename string, variants []*MediaVariant) (*Media, error)
+	GetMedia(id int) (*Media, error)
+	GetMediaByOwner(ownerID int) ([]*Media, error)
+	DeleteMedia(id, userID int) error
+}
+
+// WebsiteStore is the persistence interface for managed websites, their pages
+// and the items on each page. Deleting a website or page deletes everything on it.
+type WebsiteStore interface {
+	GetWebsites() ([]*Website, error)
+	GetWebsite(id int) (*Website, error)
+	CreateWebsite(input WebsiteInput, ownerID int) (*Website, error)
+	UpdateWebsite(id int, input WebsiteInput) (*Website, error)
+	DeleteWebsite(id int) error
+	GetPages(websiteID int) ([]*Page, error)
+	GetPage(websiteID, id int) (*Page, error)
+	CreatePage(websiteID int, input PageInput) (*Page, error)
+	UpdatePage(websiteID, id int, input PageInput) (*Page, error)
+	DeletePage(websiteID, id int) error
+	GetItems(pageID int) ([]*Item, error)
+	GetItem(pageID, id int) (*Item, error)
+	CreateItem(pageID int, input ItemInput) (*Item, error)
+	UpdateItem(pageID, id int, input ItemInput) (*Item, error)
+	MoveItem(pageID, id, position int) (*Item, error)
+	DeleteItem(pageID, id int) error
+}
+
+// WebsiteMemberStore is the persistence interface for the users who may work on
+// a website and the role each of them has there. The last owner of a website
+// can be neither demoted nor removed.
+type WebsiteMemberStore interface {
+	GetWebsitesByMember(userID int) ([]*Website, error)
+	GetWebsiteMembers(websiteID int) ([]*WebsiteMember, error)
+	GetWebsiteMember(websiteID, userID int) (*WebsiteMember, error)
+	AddWebsiteMember(websiteID, userID int, role WebsiteRole) (*WebsiteMember, error)
+	UpdateWebsiteMember(websiteID, userID int, role WebsiteRole) (*WebsiteMember, error)
+	RemoveWebsiteMember(websiteID, userID int) error
+}
+
+// WebsiteInviteStore is the persistence interface for invites to websites
+type WebsiteInviteStore interface {
+	InviteWebsiteMember(websiteID int, email string, role WebsiteRole, invitedBy int) (*WebsiteInvite, error)
+	GetWebsiteInvites(websiteID int) ([]*WebsiteInvite, error)
+	GetUserInvites(userID int) ([]*WebsiteInvite, error)
+	AcceptWebsiteInvite(id, userID int) (*WebsiteMember, error)
+	DeclineWebsiteInvite(id, userID int) error
+	DeleteWebsiteInvite(websiteID, id int) error
+}
+
+// TokenStore is the persistence interface for refresh tokens and revoked access tokens
+type TokenStore interface {
+	CreateRefreshToken(userID int, familyID, tokenHash string, expiresAt time.Time) error
+	UseRefreshToken(tokenHash string) (*RefreshToken, error)
+	RevokeRefreshToken(tokenHash string, userID int) error
+	RevokeToken(tokenID string, expiresAt time.Time) error
+	IsTokenRevoked(tokenID string) (bool, error)
+}
+
+// PersonalTokenStore is the persistence interface for personal access tokens
+type PersonalTokenStore interface {
+	CreatePersonalToken(userID int, name string, scopes []Scope, prefix, tokenHash string, expiresAt time.Time) (*PersonalToken, error)
+	GetPersonalTokens(userID int) ([]*PersonalToken, error)
+	RevokePersonalToken(id, userID int) error
+	GetPersonalTokenByHash(tokenHash string) (*PersonalToken, error)
+	RecordPersonalTokenUse(id int) error
+}
+
+// Store groups every persistence interface used by the application
+type Store interface {
+	UserStore
+	PostStore
+	RevisionStore
+	TagStore
+	CategoryStore
+	CommentStore
+	SearchStore
+	MediaStore
+	WebsiteStore
+	WebsiteMemberStore
+	WebsiteInviteStore
+	TokenStore
+	PersonalTokenStore
+}
+
+// SQLStore implements Store on top of a SQL database
+type SQLStore struct {
+	db *database.DB
+
+	searchMu      sync.Mutex
+	searchIndex   *search.Index // SQLite only; MySQL and PostgreSQL search in the database
+	searchVersion int64         // PRAGMA data_version the index was built at
+}
+
+// NewSQLStore creates a store backed by the given database connection
+func NewSQLStore(db *database.DB) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+// now returns the current time, normalised for the store's dialect
+func (s *SQLStore) now() time.Time {
+	return s.db.Dialect.Time(time.Now())
+}
+
+// timeArg converts an optional timestamp into a query argument, normalised for the dialect
+func (s *SQLStore) timeArg(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return s.db.Dialect.Time(*t)
+}
@@ -0,0 +1,77 @@
+// backend/internal/models/store_test.go
+package models
+
+import (
+	"path/filepath"
+	"testing"
+
+	"blog-app/internal/database"
+)
+
+// forEachStore runs a test against a fresh MemoryStore and a fresh SQLStore on
+// a migrated SQLite database, which must behave the same
+func forEachStore(t *testing.T, test func(t *testing.T, store Store)) {
+	t.Helper()
+
+	t.Run("memory", func(t *testing.T) {
+		test(t, NewMemoryStore())
+	})
+	t.Run("sqlite", func(t *testing.T) {
+		test(t, newSQLiteStore(t))
+	})
+}
+
+// newSQLiteStore creates a SQLStore on a new SQLite file with every migration applied
+func newSQLiteStore(t *testing.T) *SQLStore {
+	t.Helper()
+
+	db, err := database.NewConnection(database.Config{
+		Driver: string(database.SQLite),
+		Path:   filepath.Join(t.TempDir(), "blog.db"),
+	})
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+	return NewSQLStore(db)
+}
+
+// mustCreateUser creates a user named name with an address derived from it
+func mustCreateUser(t *testing.T, store Store, name string) *User {
+	t.Helper()
+
+	user, err := store.CreateUser(name, name+"@example.com", "password")
+	if err != nil {
+		t.Fatalf("failed to create user %s: %v", name, err)
+	}
+	return user
+}
+
+// mustSetRole gives a user a site-wide role
+func mustSetRole(t *testing.T, store Store, user *User, role Role) {
+	t.Helper()
+
+	if _, err := store.SetUserRole(user.ID, role); err != nil {
+		t.Fatalf("failed to make %s %s: %v", user.Username, role, err)
+	}
+	user.Role = role
+}
+
+// mustCreatePost creates a post by the author
+func mustCreatePost(t *testing.T, store Store, author *User, input PostInput) *Post {
+	t.Helper()
+
+	post, err := store.CreatePost(input, author.ID)
+	if err != nil {
+		t.Fatalf("failed to create post %q: %v", input.Title, err)
+	}
+	return post
+}
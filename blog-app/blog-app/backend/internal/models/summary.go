@@ -0,0 +1,93 @@
+// backend/internal/models/summary.go
+package models
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"blog-app/internal/search"
+)
+
+// Summary settings
+const (
+	ExcerptLength  = 300 // Longest excerpt in bytes, not counting the ellipsis
+	WordsPerMinute = 200 // Reading speed used to estimate reading time
+)
+
+// PostSummary is the list representation of a post: its metadata, with a
+// plain text excerpt and reading statistics instead of the HTML content.
+// Content is only filled in when a listing asks for it.
+type PostSummary struct {
+	ID              int        `json:"id"`
+	Title           string     `json:"title"`
+	Slug            string     `json:"slug"`
+	Excerpt         string     `json:"excerpt"`
+	WordCount       int        `json:"word_count"`
+	ReadingTime     int        `json:"reading_time"` // Estimated minutes, at least 1 for posts with any words
+	AuthorID        int        `json:"author_id"`
+	Author          string     `json:"author"`
+	CategoryID      *int       `json:"category_id"`
+	Tags            []string   `json:"tags"`
+	FeaturedImageID *int       `json:"featured_image_id"`
+	CommentCount    int        `json:"comment_count"`
+	Status          PostStatus `json:"status"`
+	PublishedAt     *time.Time `json:"published_at"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+	Content         string     `json:"-"`
+}
+
+// Summarize builds the list representation of a post
+func Summarize(post *Post) *PostSummary {
+	text := search.StripHTML(post.Content)
+	words := wordCount(text)
+
+	return &PostSummary{
+		ID:              post.ID,
+		Title:           post.Title,
+		Slug:            post.Slug,
+		Excerpt:         excerpt(text, ExcerptLength),
+		WordCount:       words,
+		ReadingTime:     readingTime(words),
+		AuthorID:        post.AuthorID,
+		Author:          post.Author,
+		CategoryID:      post.CategoryID,
+		Tags:            post.Tags,
+		FeaturedImageID: post.FeaturedImageID,
+		CommentCount:    post.CommentCount,
+		Status:          post.Status,
+		PublishedAt:     post.PublishedAt,
+		CreatedAt:       post.CreatedAt,
+		UpdatedAt:       post.UpdatedAt,
+		Content:         post.Content,
+	}
+}
+
+// wordCount counts the words of plain text
+func wordCount(text string) int {
+	return len(strings.Fields(text))
+}
+
+// readingTime estimates the minutes it takes to read the given number of words
+func readingTime(words int) int {
+	return (words + WordsPerMinute - 1) / WordsPerMinute
+}
+
+// excerpt shortens plain text to at most max bytes, cutting between words
+// where possible and adding an ellipsis if anything was cut
+func excerpt(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+
+	cut := strings.LastIndexByte(text[:max+1], ' ')
+	if cut <= 0 {
+		// A single long word is cut on a character boundary
+		cut = max
+		for !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+	}
+	return strings.TrimRight(text[:cut], " ,.;:") + "…"
+}
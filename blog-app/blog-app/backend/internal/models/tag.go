@@ -0,0 +1,194 @@
+// backend/internal/models/tag.go
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"blog-app/internal/database"
+)
+
+// Errors returned by tag assignment
+var (
+	ErrInvalidTag  = errors.New("tags must contain at least one letter or digit")
+	ErrTooManyTags = fmt.Errorf("a post can have at most %d tags", maxTagsPerPost)
+)
+
+// Tag limits
+const (
+	maxTagLength   = 50
+	maxTagsPerPost = 10
+)
+
+// Tag is a label used to classify posts
+type Tag struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	PostCount int    `json:"post_count"` // Number of posts with the tag visible to the viewer
+}
+
+// NormalizeTags cleans up tag names as entered by users: each one is reduced to
+// slug form ("Web Dev" becomes "web-dev"), duplicates are dropped and the result
+// is sorted.
+func NormalizeTags(names []string) ([]string, error) {
+	seen := make(map[string]bool)
+	tags := []string{}
+	for _, name := range names {
+		tag := slugify(name, maxTagLength)
+		if tag == "" {
+			return nil, ErrInvalidTag
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	if len(tags) > maxTagsPerPost {
+		return nil, ErrTooManyTags
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// GetTags retrieves every tag used by a post visible to the viewer, with post counts
+func (s *SQLStore) GetTags(viewer Viewer) ([]*Tag, error) {
+	visible, args := visibleWhere(viewer)
+	rows, err := s.db.Query(`
+		SELECT t.id, t.name, COUNT(p.id)
+		FROM tags t
+		JOIN post_tags pt ON pt.tag_id = t.id
+		JOIN posts p ON p.id = pt.post_id
+		WHERE `+visible+`
+		GROUP BY t.id, t.name
+		ORDER BY t.name`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []*Tag
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.PostCount); err != nil {
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// attachTags fills in the tags of the given posts
+func (s *SQLStore) attachTags(posts []*Post) error {
+	ids := make([]int, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	tags, err := s.postTags(ids)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		post.Tags = append([]string{}, tags[post.ID]...)
+	}
+	return nil
+}
+
+// attachSummaryTags fills in the tags of the given post summaries
+func (s *SQLStore) attachSummaryTags(summaries []*PostSummary) error {
+	ids := make([]int, len(summaries))
+	for i, summary := range summaries {
+		ids[i] = summary.ID
+	}
+	tags, err := s.postTags(ids)
+	if err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		summary.Tags = append([]string{}, tags[summary.ID]...)
+	}
+	return nil
+}
+
+// postTags retrieves the tags of the given posts in alphabetical order, keyed by post ID
+func (s *SQLStore) postTags(ids []int) (map[int][]string, error) {
+	tags := make(map[int][]string, len(ids))
+	if len(ids) == 0 {
+		return tags, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	rows, err := s.db.Query(`
+		SELECT pt.post_id, t.name
+		FROM post_tags pt
+		JOIN tags t ON t.id = pt.tag_id
+		WHERE pt.post_id IN (?`+strings.Repeat(", ?", len(args)-1)+`)
+		ORDER BY t.name`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID int
+		var name string
+		if err := rows.Scan(&postID, &name); err != nil {
+			return nil, err
+		}
+		tags[postID] = append(tags[postID], name)
+	}
+
+	return tags, rows.Err()
+}
+
+// setPostTags replaces the tags of a post inside an open transaction,
+// creating any tags that do not exist yet. tags must be normalized.
+func setPostTags(tx *database.Tx, postID int, tags []string) error {
+	if _, err := tx.Exec("DELETE FROM post_tags WHERE post_id = ?", postID); err != nil {
+		return err
+	}
+
+	for _, name := range tags {
+		tagID, err := findOrCreateTag(tx, name)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec("INSERT INTO post_tags (post_id, tag_id) VALUES (?, ?)", postID, tagID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findOrCreateTag returns the ID of the tag with the given name, creating it if needed
+func findOrCreateTag(tx *database.Tx, name string) (int, error) {
+	var id int
+	err := tx.QueryRow("SELECT id FROM tags WHERE name = ?", name).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	newID, err := tx.Insert("INSERT INTO tags (name) VALUES (?)", name)
+	if err != nil {
+		return 0, err
+	}
+	return int(newID), nil
+}
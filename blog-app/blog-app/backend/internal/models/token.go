@@ -0,0 +1,155 @@
+// backend/internal/models/token.go
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"blog-app/internal/database"
+)
+
+// Errors returned by token operations
+var (
+	ErrRefreshTokenInvalid = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used; its session has been logged out")
+)
+
+// RefreshToken is a stored refresh token. Each refresh replaces the token with
+// a new one of the same family, so a family is one login session.
+type RefreshToken struct {
+	ID        int
+	UserID    int
+	FamilyID  string
+	TokenHash string // Hex SHA-256 of the token, which is never stored
+	ExpiresAt time.Time
+	UsedAt    *time.Time // Set once the token has been exchanged for a new one
+	RevokedAt *time.Time // Set when the session is logged out or the family is revoked
+	CreatedAt time.Time
+}
+
+// CreateRefreshToken stores a refresh token of the given family, clearing out
+// the user's expired tokens while at it
+func (s *SQLStore) CreateRefreshToken(userID int, familyID, tokenHash string, expiresAt time.Time) error {
+	now := s.now()
+	_, err := s.db.Exec("DELETE FROM refresh_tokens WHERE user_id = ? AND expires_at < ?", userID, now)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Exec(
+		"INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at) VALUES (?, ?, ?, ?, ?)",
+		userID, familyID, tokenHash, s.db.Dialect.Time(expiresAt), now,
+	)
+	return err
+}
+
+// UseRefreshToken exchanges a refresh token, marking it used so that it works
+// only once. A token that was already used is being replayed, possibly by
+// someone who stole it, so its whole family is revoked and ErrRefreshTokenReused
+// returned. Unknown, expired and revoked tokens give ErrRefreshTokenInvalid.
+func (s *SQLStore) UseRefreshToken(tokenHash string) (*RefreshToken, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var t RefreshToken
+	var usedAt, revokedAt sql.NullTime
+	err = tx.QueryRow(
+		"SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at FROM refresh_tokens WHERE token_hash = ?",
+		tokenHash,
+	).Scan(&t.ID, &t.UserID, &t.FamilyID, &t.TokenHash, &t.ExpiresAt, &usedAt, &revokedAt, &t.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	now := s.now()
+	if usedAt.Valid {
+		return nil, reuseRefreshToken(tx, t.FamilyID, now)
+	}
+	if revokedAt.Valid || !now.Before(t.ExpiresAt) {
+		return nil, ErrRefreshTokenInvalid
+	}
+
+	// Only one of two concurrent exchanges of the token can mark it used;
+	// the other is treated as a replay
+	result, err := tx.Exec("UPDATE refresh_tokens SET used_at = ? WHERE id = ? AND used_at IS NULL", now, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	marked, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if marked != 1 {
+		return nil, reuseRefreshToken(tx, t.FamilyID, now)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	t.UsedAt = &now
+	return &t, nil
+}
+
+// reuseRefreshToken ends every session of a family whose token was replayed,
+// committing the transaction, and returns ErrRefreshTokenReused
+func reuseRefreshToken(tx *database.Tx, familyID string, now time.Time) error {
+	_, err := tx.Exec("UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL", now, familyID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// RevokeRefreshToken logs out the session of one of the user's refresh tokens
+// by revoking its whole family
+func (s *SQLStore) RevokeRefreshToken(tokenHash string, userID int) error {
+	var familyID string
+	err := s.db.QueryRow("SELECT family_id FROM refresh_tokens WHERE token_hash = ? AND user_id = ?", tokenHash, userID).Scan(&familyID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrRefreshTokenInvalid
+		}
+		return err
+	}
+
+	_, err = s.db.Exec("UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL", s.now(), familyID)
+	return err
+}
+
+// RevokeToken records that the access token with the given ID (jti) may no
+// longer be used. It is remembered until the token would have expired anyway.
+func (s *SQLStore) RevokeToken(tokenID string, expiresAt time.Time) error {
+	now := s.now()
+	_, err := s.db.Exec("DELETE FROM revoked_tokens WHERE expires_at < ?", now)
+	if err != nil {
+		return err
+	}
+
+	var revoked bool
+	err = s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE token_id = ?)", tokenID).Scan(&revoked)
+	if err != nil || revoked {
+		return err
+	}
+	_, err = s.db.Exec(
+		"INSERT INTO revoked_tokens (token_id, expires_at, created_at) VALUES (?, ?, ?)",
+		tokenID, s.db.Dialect.Time(expiresAt), now,
+	)
+	return err
+}
+
+// IsTokenRevoked reports whether the access token with the given ID has been revoked
+func (s *SQLStore) IsTokenRevoked(tokenID string) (bool, error) {
+	var revoked bool
+	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE token_id = ?)", tokenID).Scan(&revoked)
+	return revoked, err
+}
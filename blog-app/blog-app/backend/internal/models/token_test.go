@@ -0,0 +1,175 @@
+// backend/internal/models/token_test.go
+package models
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRefreshTokenRotation(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		user := mustCreateUser(t, store, "alice")
+		expires := time.Now().Add(time.Hour)
+		mustCreateRefreshToken(t, store, user.ID, "session", "first", expires)
+		mustCreateRefreshToken(t, store, user.ID, "other", "elsewhere", expires)
+
+		// Exchanging a token works once; the rotated token joins its family
+		used, err := store.UseRefreshToken("first")
+		if err != nil {
+			t.Fatalf("UseRefreshToken() error = %v", err)
+		}
+		if used.UserID != user.ID || used.FamilyID != "session" {
+			t.Errorf("UseRefreshToken() = user %d family %q, want user %d family %q", used.UserID, used.FamilyID, user.ID, "session")
+		}
+		mustCreateRefreshToken(t, store, user.ID, "session", "second", expires)
+
+		// Replaying the used token revokes the session, but not the user's other sessions
+		if _, err := store.UseRefreshToken("first"); !errors.Is(err, ErrRefreshTokenReused) {
+			t.Fatalf("replayed UseRefreshToken() error = %v, want %v", err, ErrRefreshTokenReused)
+		}
+		if _, err := store.UseRefreshToken("second"); !errors.Is(err, ErrRefreshTokenInvalid) {
+			t.Errorf("UseRefreshToken() of the revoked session error = %v, want %v", err, ErrRefreshTokenInvalid)
+		}
+		if _, err := store.UseRefreshToken("elsewhere"); err != nil {
+			t.Errorf("UseRefreshToken() of another session error = %v", err)
+		}
+	})
+}
+
+func TestUseRefreshTokenInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, store Store, userID int)
+	}{
+		{
+			name:  "unknown",
+			setup: func(t *testing.T, store Store, userID int) {},
+		},
+		{
+			name: "expired",
+			setup: func(t *testing.T, store Store, userID int) {
+				mustCreateRefreshToken(t, store, userID, "session", "token", time.Now().Add(-time.Minute))
+			},
+		},
+		{
+			name: "logged out",
+			setup: func(t *testing.T, store Store, userID int) {
+				mustCreateRefreshToken(t, store, userID, "session", "token", time.Now().Add(time.Hour))
+				if err := store.RevokeRefreshToken("token", userID); err != nil {
+					t.Fatalf("RevokeRefreshToken() error = %v", err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachStore(t, func(t *testing.T, store Store) {
+				user := mustCreateUser(t, store, "alice")
+				tt.setup(t, store, user.ID)
+
+				if _, err := store.UseRefreshToken("token"); !errors.Is(err, ErrRefreshTokenInvalid) {
+					t.Errorf("UseRefreshToken() error = %v, want %v", err, ErrRefreshTokenInvalid)
+				}
+			})
+		})
+	}
+}
+
+func TestRevokeRefreshTokenOfAnotherUser(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		alice := mustCreateUser(t, store, "alice")
+		bob := mustCreateUser(t, store, "bob")
+		mustCreateRefreshToken(t, store, alice.ID, "session", "token", time.Now().Add(time.Hour))
+
+		if err := store.RevokeRefreshToken("token", bob.ID); !errors.Is(err, ErrRefreshTokenInvalid) {
+			t.Fatalf("RevokeRefreshToken() error = %v, want %v", err, ErrRefreshTokenInvalid)
+		}
+		if _, err := store.UseRefreshToken("token"); err != nil {
+			t.Errorf("UseRefreshToken() error = %v", err)
+		}
+	})
+}
+
+func TestUseRefreshTokenConcurrently(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		user := mustCreateUser(t, store, "alice")
+		mustCreateRefreshToken(t, store, user.ID, "session", "token", time.Now().Add(time.Hour))
+
+		// Exactly one exchange may win; every other one is a replay
+		const attempts = 8
+		errs := make(chan error, attempts)
+		var wg sync.WaitGroup
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := store.UseRefreshToken("token")
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		won := 0
+		for err := range errs {
+			switch {
+			case err == nil:
+				won++
+			case !errors.Is(err, ErrRefreshTokenReused):
+				t.Errorf("UseRefreshToken() error = %v, want nil or %v", err, ErrRefreshTokenReused)
+			}
+		}
+		if won != 1 {
+			t.Errorf("%d exchanges succeeded, want 1", won)
+		}
+	})
+}
+
+// mustCreateRefreshToken stores a refresh token whose hash is hash
+func mustCreateRefreshToken(t *testing.T, store Store, userID int, familyID, hash string, expiresAt time.Time) {
+	t.Helper()
+
+	if err := store.CreateRefreshToken(userID, familyID, hash, expiresAt); err != nil {
+		t.Fatalf("CreateRefreshToken() error = %v", err)
+	}
+}
+
+func TestPersonalTokenLookup(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		user := mustCreateUser(t, store, "alice")
+		pat, err := store.CreatePersonalToken(user.ID, "ci", []Scope{ScopePostsRead}, "bpat_abcdef", "hash", time.Now().Add(time.Hour))
+		if err != nil {
+			t.Fatalf("CreatePersonalToken() error = %v", err)
+		}
+
+		// Looking a token up does not count as using it
+		found, err := store.GetPersonalTokenByHash("hash")
+		if err != nil {
+			t.Fatalf("GetPersonalTokenByHash() error = %v", err)
+		}
+		if found.ID != pat.ID || !found.HasScope(ScopePostsRead) || found.LastUsedAt != nil {
+			t.Errorf("GetPersonalTokenByHash() = %+v, want token %d, never used", found, pat.ID)
+		}
+		if _, err := store.GetPersonalTokenByHash("other"); !errors.Is(err, ErrPersonalTokenInvalid) {
+			t.Errorf("GetPersonalTokenByHash() of an unknown hash error = %v, want %v", err, ErrPersonalTokenInvalid)
+		}
+
+		if err := store.RecordPersonalTokenUse(pat.ID); err != nil {
+			t.Fatalf("RecordPersonalTokenUse() error = %v", err)
+		}
+		if found, err := store.GetPersonalTokenByHash("hash"); err != nil || found.LastUsedAt == nil {
+			t.Errorf("GetPersonalTokenByHash() after use = %+v, %v; want last_used_at set", found, err)
+		}
+
+		// Revoked tokens are invalid
+		if err := store.RevokePersonalToken(pat.ID, user.ID); err != nil {
+			t.Fatalf("RevokePersonalToken() error = %v", err)
+		}
+		if _, err := store.GetPersonalTokenByHash("hash"); !errors.Is(err, ErrPersonalTokenInvalid) {
+			t.Errorf("GetPersonalTokenByHash() of a revoked token error = %v, want %v", err, ErrPersonalTokenInvalid)
+		}
+	})
+}
@@ -3,7 +3,7 @@ package models
 
 import (
 	"database/sql"
-	"errors"
+	"fmt"
 	"time"
 
 	"golang.org/x/crypto/bcrypt"
@@ -15,6 +15,7 @@ type User struct {
 	Username  string    `json:"username"`
 	Email     string    `json:"email"`
 	Password  string    `json:"-"` // Never send password to client
+	Role      Role      `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -23,7 +24,8 @@ type User struct {
 type UserResponse struct {
 	ID        int       `json:"id"`
 	Username  string    `json:"username"`
-	Email     string    `json:"email"`
+	Email     string    `json:"email,omitempty"` // Only shown to admins
+	Role      Role      `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
@@ -33,12 +35,13 @@ func (u *User) ToResponse() *UserResponse {
 		ID:        u.ID,
 		Username:  u.Username,
 		Email:     u.Email,
+		Role:      u.Role,
 		CreatedAt: u.CreatedAt,
 	}
 }
 
 // CreateUser creates a new user in the database
-func CreateUser(db *sql.DB, username, email, password string) (*User, error) {
+func (s *SQLStore) CreateUser(username, email, password string) (*User, error) {
 	// Hash the password
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -47,26 +50,31 @@ func CreateUser(db *sql.DB, username, email, password string) (*User, error) {
 
 	// Check if username or email already exists
 	var exists bool
-	err = db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE username = ? OR email = ?)", username, email).Scan(&exists)
+	err = s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE username = ? OR email = ?)", username, email).Scan(&exists)
 	if err != nil {
 		return nil, err
 	}
 	if exists {
-		return nil, errors.New("username or email already exists")
+		return nil, ErrUserExists
 	}
 
-	// Create the user
-	now := time.Now()
-	result, err := db.Exec(
-		"INSERT INTO users (username, email, password, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
-		username, email, hashedPassword, now, now,
-	)
+	// The first user becomes an admin, so that someone can manage the others
+	role := DefaultRole
+	var first bool
+	err = s.db.QueryRow("SELECT NOT EXISTS(SELECT 1 FROM users)").Scan(&first)
 	if err != nil {
 		return nil, err
 	}
+	if first {
+		role = RoleAdmin
+	}
 
-	// Get the ID of the new user
-	id, err := result.LastInsertId()
+	// Create the user
+	now := s.now()
+	id, err := s.db.Insert(
+		"INSERT INTO users (username, email, password, role, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)",
+		username, email, string(hashedPassword), role, now, now,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -77,44 +85,51 @@ func CreateUser(db *sql.DB, username, email, password string) (*User, error) {
 		Username:  username,
 		Email:     email,
 		Password:  string(hashedPassword),
+		Role:      role,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}, nil
 }
 
 // GetUserByID retrieves a user by ID
-func GetUserByID(db *sql.DB, id int) (*User, error) {
+func (s *SQLStore) GetUserByID(id int) (*User, error) {
 	var user User
-	err := db.QueryRow(
-		"SELECT id, username, email, password, created_at, updated_at FROM users WHERE id = ?",
+	err := s.db.QueryRow(
+		"SELECT id, username, email, password, role, created_at, updated_at FROM users WHERE id = ?",
 		id,
 	).Scan(
-		&user.ID, &user.Username, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Username, &user.Email, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
 		return nil, err
 	}
 	return &user, nil
 }
 
 // GetUserByEmail retrieves a user by email
-func GetUserByEmail(db *sql.DB, email string) (*User, error) {
+func (s *SQLStore) GetUserByEmail(email string) (*User, error) {
 	var user User
-	err := db.QueryRow(
-		"SELECT id, username, email, password, created_at, updated_at FROM users WHERE email = ?",
+	err := s.db.QueryRow(
+		"SELECT id, username, email, password, role, created_at, updated_at FROM users WHERE email = ?",
 		email,
 	).Scan(
-		&user.ID, &user.Username, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt,
+		&user.ID, &user.Username, &user.Email, &user.Password, &user.Role, &user.CreatedAt, &user.UpdatedAt,
 	)
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
 		return nil, err
 	}
 	return &user, nil
 }
 
 // GetUsers retrieves all users
-func GetUsers(db *sql.DB) ([]*UserResponse, error) {
-	rows, err := db.Query("SELECT id, username, email, created_at FROM users")
+func (s *SQLStore) GetUsers() ([]*UserResponse, error) {
+	rows, err := s.db.Query("SELECT id, username, email, role, created_at FROM users")
 	if err != nil {
 		return nil, err
 	}
@@ -123,7 +138,7 @@ func GetUsers(db *sql.DB) ([]*UserResponse, error) {
 	var users []*UserResponse
 	for rows.Next() {
 		var user UserResponse
-		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.CreatedAt); err != nil {
+		if err := rows.Scan(&user.ID, &user.Username, &user.Email, &user.Role, &user.CreatedAt); err != nil {
 			return nil, err
 		}
 		users = append(users, &user)
@@ -136,16 +151,106 @@ func GetUsers(db *sql.DB) ([]*UserResponse, error) {
 	return users, nil
 }
 
-// DeleteUser deletes a user from the database
-func DeleteUser(db *sql.DB, id int) error {
+// DeleteUser deletes a user from the database on behalf of userID. Users may
+// delete themselves; deleting anyone else needs a role, as currently stored,
+// with the users:manage permission. The last admin cannot be deleted.
+func (s *SQLStore) DeleteUser(id, userID int) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Check the user asking may delete the account
+	if id != userID {
+		var role Role
+		err := tx.QueryRow("SELECT role FROM users WHERE id = ?", userID).Scan(&role)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if !role.Can(PermManageUsers) {
+			return fmt.Errorf("%w: you can only delete your own account", ErrNotAuthor)
+		}
+	}
+
+	// Keep at least one admin, even when they delete themselves
+	var role Role
+	err = tx.QueryRow("SELECT role FROM users WHERE id = ?", id).Scan(&role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if role == RoleAdmin {
+		var others bool
+		err := tx.QueryRow("SELECT EXISTS(SELECT 1 FROM users WHERE role = ? AND id <> ?)", RoleAdmin, id).Scan(&others)
+		if err != nil {
+			return err
+		}
+		if !others {
+			return ErrLastAdmin
+		}
+	}
+
+	// Websites outlive their creator, but not their last owner
+	var soleOwner bool
+	err = tx.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM website_members m
+			WHERE m.user_id = ? AND m.role = ? AND NOT EXISTS(
+				SELECT 1 FROM website_members o
+				WHERE o.website_id = m.website_id AND o.role = ? AND o.user_id <> m.user_id
+			)
+		)`,
+		id, WebsiteOwner, WebsiteOwner,
+	).Scan(&soleOwner)
+	if err != nil {
+		return err
+	}
+	if soleOwner {
+		return ErrSoleOwner
+	}
+
+	// Note the user's posts, which leave the search index once the delete is committed
+	rows, err := tx.Query("SELECT id FROM posts WHERE author_id = ?", id)
+	if err != nil {
+		return err
+	}
+	var postIDs []int
+	for rows.Next() {
+		var postID int
+		if err := rows.Scan(&postID); err != nil {
+			rows.Close()
+			return err
+		}
+		postIDs = append(postIDs, postID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
 	// Delete the user's posts first to maintain referential integrity
-	_, err := db.Exec("DELETE FROM posts WHERE author_id = ?", id)
+	_, err = tx.Exec("DELETE FROM posts WHERE author_id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	// Forget them as a comment moderator; SQLite has no foreign key to do it
+	_, err = tx.Exec("UPDATE comments SET moderated_by = NULL WHERE moderated_by = ?", id)
+	if err != nil {
+		return err
+	}
+
+	// Take their media off other authors' posts, for the same reason
+	_, err = tx.Exec("UPDATE posts SET featured_image_id = NULL WHERE featured_image_id IN (SELECT id FROM media WHERE owner_id = ?)", id)
 	if err != nil {
 		return err
 	}
 
 	// Delete the user
-	result, err := db.Exec("DELETE FROM users WHERE id = ?", id)
+	result, err := tx.Exec("DELETE FROM users WHERE id = ?", id)
 	if err != nil {
 		return err
 	}
@@ -156,9 +261,13 @@ func DeleteUser(db *sql.DB, id int) error {
 		return err
 	}
 	if rowsAffected == 0 {
-		return errors.New("user not found")
+		return ErrUserNotFound
 	}
 
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	s.unindexPost(postIDs...)
 	return nil
 }
 
@@ -0,0 +1,168 @@
+// backend/internal/models/user_test.go
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDeleteUser(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string // Username of the user to delete, or "" for a missing one
+		asker   string
+		website bool // The target owns a website on their own
+		wantErr error
+	}{
+		{name: "user deletes themselves", target: "alice", asker: "alice"},
+		{name: "admin deletes a user", target: "alice", asker: "admin"},
+		{name: "user deletes someone else", target: "bob", asker: "alice", wantErr: ErrNotAuthor},
+		{name: "last admin deletes themselves", target: "admin", asker: "admin", wantErr: ErrLastAdmin},
+		{name: "admin deletes a missing user", target: "", asker: "admin", wantErr: ErrUserNotFound},
+		{name: "sole website owner", target: "alice", asker: "alice", website: true, wantErr: ErrSoleOwner},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachStore(t, func(t *testing.T, store Store) {
+				users := map[string]*User{}
+				for _, name := range []string{"admin", "alice", "bob"} {
+					users[name] = mustCreateUser(t, store, name)
+				}
+				if users["admin"].Role != RoleAdmin {
+					t.Fatalf("first user has role %s, want admin", users["admin"].Role)
+				}
+
+				targetID := 999
+				var post *Post
+				if target := users[tt.target]; target != nil {
+					targetID = target.ID
+					post = mustCreatePost(t, store, target, PostInput{Title: "Post by " + tt.target, Content: "Hello"})
+				}
+				if tt.website {
+					if _, err := store.CreateWebsite(WebsiteInput{Name: "Site", Domain: "site.example.com"}, targetID); err != nil {
+						t.Fatalf("failed to create website: %v", err)
+					}
+				}
+
+				err := store.DeleteUser(targetID, users[tt.asker].ID)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("DeleteUser() error = %v, want %v", err, tt.wantErr)
+				}
+
+				// The user and their posts go together, or not at all
+				_, userErr := store.GetUserByID(targetID)
+				if tt.wantErr == nil && !errors.Is(userErr, ErrUserNotFound) {
+					t.Errorf("deleted user still found: %v", userErr)
+				}
+				if tt.wantErr != nil && tt.target != "" && userErr != nil {
+					t.Errorf("user deleted despite error: %v", userErr)
+				}
+				if post != nil {
+					_, postErr := store.GetPostByID(post.ID)
+					if deleted := errors.Is(postErr, ErrPostNotFound); deleted != (tt.wantErr == nil) {
+						t.Errorf("post deleted = %v, want %v", deleted, tt.wantErr == nil)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestGetUsers(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		mustCreateUser(t, store, "admin")
+		mustCreateUser(t, store, "alice")
+
+		users, err := store.GetUsers()
+		if err != nil {
+			t.Fatalf("GetUsers() error = %v", err)
+		}
+		if len(users) != 2 {
+			t.Fatalf("GetUsers() returned %d users, want 2", len(users))
+		}
+		for _, user := range users {
+			if user.Email != user.Username+"@example.com" {
+				t.Errorf("user %s has email %q", user.Username, user.Email)
+			}
+		}
+	})
+}
+
+func TestCreateUserRejectsDuplicates(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		mustCreateUser(t, store, "alice")
+
+		tests := []struct {
+			name     string
+			username string
+			email    string
+		}{
+			{"same username", "alice", "other@example.com"},
+			{"same email", "other", "alice@example.com"},
+		}
+		for _, tt := range tests {
+			if _, err := store.CreateUser(tt.username, tt.email, "password"); !errors.Is(err, ErrUserExists) {
+				t.Errorf("%s: CreateUser() error = %v, want %v", tt.name, err, ErrUserExists)
+			}
+		}
+	})
+}
+
+func TestSetUserRole(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string // Username of the user whose role changes, or "" for a missing one
+		role       Role
+		otherAdmin bool // alice is an admin too
+		wantErr    error
+	}{
+		{name: "promote to editor", target: "alice", role: RoleEditor},
+		{name: "demote the last admin", target: "admin", role: RoleAuthor, wantErr: ErrLastAdmin},
+		{name: "demote an admin", target: "admin", role: RoleAuthor, otherAdmin: true},
+		{name: "keep the last admin an admin", target: "admin", role: RoleAdmin},
+		{name: "invalid role", target: "alice", role: "owner", wantErr: ErrInvalidUserRole},
+		{name: "missing user", target: "", role: RoleEditor, wantErr: ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			forEachStore(t, func(t *testing.T, store Store) {
+				users := map[string]*User{}
+				for _, name := range []string{"admin", "alice"} {
+					users[name] = mustCreateUser(t, store, name)
+				}
+				if tt.otherAdmin {
+					mustSetRole(t, store, users["alice"], RoleAdmin)
+				}
+
+				targetID := 999
+				before := &User{}
+				if target := users[tt.target]; target != nil {
+					targetID = target.ID
+					before, _ = store.GetUserByID(targetID)
+					mustCreateRefreshToken(t, store, targetID, "family", "hash", time.Now().Add(time.Hour))
+				}
+
+				user, err := store.SetUserRole(targetID, tt.role)
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("SetUserRole() error = %v, want %v", err, tt.wantErr)
+				}
+				if err == nil && user.Role != tt.role {
+					t.Errorf("SetUserRole() role = %s, want %s", user.Role, tt.role)
+				}
+
+				// Changing the role logs the user out; anything else leaves them signed in
+				if tt.target == "" {
+					return
+				}
+				loggedOut := err == nil && before.Role != tt.role
+				_, err = store.UseRefreshToken("hash")
+				if gotOut := errors.Is(err, ErrRefreshTokenInvalid); gotOut != loggedOut {
+					t.Errorf("session logged out = %v, want %v (UseRefreshToken() error = %v)", gotOut, loggedOut, err)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,566 @@
+// backend/internal/models/website.go
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Errors returned by website, page and item operations
+var (
+	ErrWebsiteNotFound  = errors.New("website not found")
+	ErrPageNotFound     = errors.New("page not found")
+	ErrItemNotFound     = errors.New("item not found")
+	ErrInvalidDomain    = errors.New("domain must be a host name such as example.com")
+	ErrDomainTaken      = errors.New("domain is already in use")
+	ErrItemImageMissing = errors.New("item image does not exist")
+)
+
+// Website is a site whose content is managed here, made of pages of items
+type Website struct {
+	ID        int         `json:"id"`
+	Name      string      `json:"name"`
+	Domain    string      `json:"domain"`         // Lowercase host name, unique across websites
+	OwnerID   int         `json:"owner_id"`       // The user who created the website, 0 if they have since been deleted
+	Role      WebsiteRole `json:"role,omitempty"` // The current user's role, when known
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Page is a page of a website, such as example.com/irish
+type Page struct {
+	ID        int       `json:"id"`
+	WebsiteID int       `json:"website_id"`
+	Title     string    `json:"title"`
+	Slug      string    `json:"slug"` // Unique within the website
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Item is an entry on a page. Items are ordered by Position.
+type Item struct {
+	ID        int       `json:"id"`
+	PageID    int       `json:"page_id"`
+	Title     string    `json:"title"`
+	Subtitle  string    `json:"subtitle"`
+	ImageID   *int      `json:"image_id"` // Media ID, nil when the item has no image
+	Position  int       `json:"position"` // 0-based position on the page
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebsiteInput holds the writable fields of a website
+type WebsiteInput struct {
+	Name   string
+	Domain string
+}
+
+// PageInput holds the writable fields of a page. An empty Slug is generated
+// from the title on create and left unchanged on update.
+type PageInput struct {
+	Title string
+	Slug  string
+}
+
+// ItemInput holds the writable fields of an item. An ImageID of nil or 0 means no image.
+type ItemInput struct {
+	Title    string
+	Subtitle string
+	ImageID  *int
+}
+
+// NormalizeDomain lowercases a host name and checks that it has at least two
+// labels of letters, digits and inner hyphens, e.g. "TopBarsInSpain.com."
+// becomes "topbarsinspain.com"
+func NormalizeDomain(domain string) (string, error) {
+	domain = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+	if len(domain) > 253 {
+		return "", ErrInvalidDomain
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return "", ErrInvalidDomain
+	}
+	for _, label := range labels {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return "", ErrInvalidDomain
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return "", ErrInvalidDomain
+			}
+		}
+	}
+	return domain, nil
+}
+
+// pageSlug returns the slug a page asks for, or the one generated from its title
+func pageSlug(input PageInput) (string, error) {
+	if input.Slug != "" {
+		if !ValidSlug(input.Slug) {
+			return "", ErrInvalidSlug
+		}
+		return input.Slug, nil
+	}
+	if slug := slugify(input.Title, maxSlugLength); slug != "" {
+		return slug, nil
+	}
+	return "page", nil
+}
+
+// websiteSelect is the column list shared by every website query, read with scanWebsite
+const websiteSelect = "SELECT id, name, domain, COALESCE(owner_id, 0), created_at, updated_at FROM websites"
+
+// scanWebsite reads a row selected with websiteSelect
+func scanWebsite(row rowScanner) (*Website, error) {
+	var w Website
+	if err := row.Scan(&w.ID, &w.Name, &w.Domain, &w.OwnerID, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// GetWebsites retrieves every website ordered by name
+func (s *SQLStore) GetWebsites() ([]*Website, error) {
+	rows, err := s.db.Query(websiteSelect + " ORDER BY name, id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	websites := []*Website{}
+	for rows.Next() {
+		w, err := scanWebsite(rows)
+		if err != nil {
+			return nil, err
+		}
+		websites = append(websites, w)
+	}
+
+	return websites, rows.Err()
+}
+
+// GetWebsite retrieves a website by ID
+func (s *SQLStore) GetWebsite(id int) (*Website, error) {
+	w, err := scanWebsite(s.db.QueryRow(websiteSelect+" WHERE id = ?", id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrWebsiteNotFound
+		}
+		return nil, err
+	}
+	return w, nil
+}
+
+// CreateWebsite creates a website with the given user as its first owner
+func (s *SQLStore) CreateWebsite(input WebsiteInput, ownerID int) (*Website, error) {
+	domain, err := NormalizeDomain(input.Domain)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDomain(domain, 0); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := s.now()
+	id, err := tx.Insert(
+		"INSERT INTO websites (name, domain, owner_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		input.Name, domain, ownerID, now, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec(
+		"INSERT INTO website_members (website_id, user_id, role, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		id, ownerID, WebsiteOwner, now, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.GetWebsite(int(id))
+}
+
+// UpdateWebsite renames a website or moves it to another domain
+func (s *SQLStore) UpdateWebsite(id int, input WebsiteInput) (*Website, error) {
+	if _, err := s.GetWebsite(id); err != nil {
+		return nil, err
+	}
+	domain, err := NormalizeDomain(input.Domain)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkDomain(domain, id); err != nil {
+		return nil, err
+	}
+
+	_, err = s.db.Exec(
+		"UPDATE websites SET name = ?, domain = ?, updated_at = ? WHERE id = ?",
+		input.Name, domain, s.now(), id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetWebsite(id)
+}
+
+// DeleteWebsite deletes a website along with its pages and their items
+func (s *SQLStore) DeleteWebsite(id int) error {
+	result, err := s.db.Exec("DELETE FROM websites WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrWebsiteNotFound
+	}
+	return nil
+}
+
+// checkDomain returns ErrDomainTaken if a website other than websiteID uses the domain
+func (s *SQLStore) checkDomain(domain string, websiteID int) error {
+	var taken bool
+	err := s.db.QueryRow("SELECT EXISTS(SELECT 1 FROM websites WHERE domain = ? AND id <> ?)", domain, websiteID).Scan(&taken)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return ErrDomainTaken
+	}
+	return nil
+}
+
+// pageSelect is the column list shared by every page query, read with scanPage
+const pageSelect = "SELECT id, website_id, title, slug, created_at, updated_at FROM pages"
+
+// scanPage reads a row selected with pageSelect
+func scanPage(row rowScanner) (*Page, error) {
+	var p Page
+	if err := row.Scan(&p.ID, &p.WebsiteID, &p.Title, &p.Slug, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// GetPages retrieves the pages of a website ordered by slug
+func (s *SQLStore) GetPages(websiteID int) ([]*Page, error) {
+	if _, err := s.GetWebsite(websiteID); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.Query(pageSelect+" WHERE website_id = ? ORDER BY slug", websiteID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	pages := []*Page{}
+	for rows.Next() {
+		p, err := scanPage(rows)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, p)
+	}
+
+	return pages, rows.Err()
+}
+
+// GetPage retrieves a page of a website by ID
+func (s *SQLStore) GetPage(websiteID, id int) (*Page, error) {
+	p, err := scanPage(s.db.QueryRow(pageSelect+" WHERE id = ? AND website_id = ?", id, websiteID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPageNotFound
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// CreatePage creates a page on a website. A generated slug gets a numeric
+// suffix if the website already uses it; an explicit one must be free.
+func (s *SQLStore) CreatePage(websiteID int, input PageInput) (*Page, error) {
+	if _, err := s.GetWebsite(websiteID); err != nil {
+		return nil, err
+	}
+	slug, err := s.resolvePageSlug(websiteID, 0, input)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	id, err := s.db.Insert(
+		"INSERT INTO pages (website_id, title, slug, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		websiteID, input.Title, slug, now, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPage(websiteID, int(id))
+}
+
+// UpdatePage updates the title of a page and, if one is given, its slug
+func (s *SQLStore) UpdatePage(websiteID, id int, input PageInput) (*Page, error) {
+	page, err := s.GetPage(websiteID, id)
+	if err != nil {
+		return nil, err
+	}
+	slug := page.Slug
+	if input.Slug != "" {
+		if slug, err = s.resolvePageSlug(websiteID, id, input); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = s.db.Exec(
+		"UPDATE pages SET title = ?, slug = ?, updated_at = ? WHERE id = ?",
+		input.Title, slug, s.now(), id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPage(websiteID, id)
+}
+
+// DeletePage deletes a page of a website along with its items
+func (s *SQLStore) DeletePage(websiteID, id int) error {
+	result, err := s.db.Exec("DELETE FROM pages WHERE id = ? AND website_id = ?", id, websiteID)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrPageNotFound
+	}
+	return nil
+}
+
+// resolvePageSlug works out the slug to store for a page of a website.
+// Pass a pageID of 0 when creating a page.
+func (s *SQLStore) resolvePageSlug(websiteID, pageID int, input PageInput) (string, error) {
+	base, err := pageSlug(input)
+	if err != nil {
+		return "", err
+	}
+
+	taken := func(slug string) (bool, error) {
+		var used bool
+		err := s.db.QueryRow(
+			"SELECT EXISTS(SELECT 1 FROM pages WHERE website_id = ? AND slug = ? AND id <> ?)",
+			websiteID, slug, pageID,
+		).Scan(&used)
+		return used, err
+	}
+
+	if input.Slug == "" {
+		return uniqueSlug(base, taken)
+	}
+	used, err := taken(base)
+	if err != nil {
+		return "", err
+	}
+	if used {
+		return "", ErrSlugTaken
+	}
+	return base, nil
+}
+
+// itemSelect is the column list shared by every item query, read with scanItem
+const itemSelect = "SELECT id, page_id, title, subtitle, image_id, position, created_at, updated_at FROM items"
+
+// scanItem reads a row selected with itemSelect
+func scanItem(row rowScanner) (*Item, error) {
+	var item Item
+	var imageID sql.NullInt64
+	if err := row.Scan(&item.ID, &item.PageID, &item.Title, &item.Subtitle, &imageID, &item.Position, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if imageID.Valid {
+		id := int(imageID.Int64)
+		item.ImageID = &id
+	}
+	return &item, nil
+}
+
+// GetItems retrieves the items of a page in position order
+func (s *SQLStore) GetItems(pageID int) ([]*Item, error) {
+	rows, err := s.db.Query(itemSelect+" WHERE page_id = ? ORDER BY position, id", pageID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*Item{}
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	return items, rows.Err()
+}
+
+// GetItem retrieves an item of a page by ID
+func (s *SQLStore) GetItem(pageID, id int) (*Item, error) {
+	item, err := scanItem(s.db.QueryRow(itemSelect+" WHERE id = ? AND page_id = ?", id, pageID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrItemNotFound
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// CreateItem adds an item to the end of a page
+func (s *SQLStore) CreateItem(pageID int, input ItemInput) (*Item, error) {
+	if err := s.checkItemImage(input.ImageID); err != nil {
+		return nil, err
+	}
+	items, err := s.GetItems(pageID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	id, err := s.db.Insert(
+		"INSERT INTO items (page_id, title, subtitle, image_id, position, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?)",
+		pageID, input.Title, input.Subtitle, optionalIDArg(input.ImageID), len(items), now, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetItem(pageID, int(id))
+}
+
+// UpdateItem updates the fields of an item, keeping its position
+func (s *SQLStore) UpdateItem(pageID, id int, input ItemInput) (*Item, error) {
+	if _, err := s.GetItem(pageID, id); err != nil {
+		return nil, err
+	}
+	if err := s.checkItemImage(input.ImageID); err != nil {
+		return nil, err
+	}
+
+	_, err := s.db.Exec(
+		"UPDATE items SET title = ?, subtitle = ?, image_id = ?, updated_at = ? WHERE id = ?",
+		input.Title, input.Subtitle, optionalIDArg(input.ImageID), s.now(), id,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetItem(pageID, id)
+}
+
+// MoveItem moves an item to the given position on its page.
+// A negative position moves it to the end.
+func (s *SQLStore) MoveItem(pageID, id, position int) (*Item, error) {
+	items, err := s.GetItems(pageID)
+	if err != nil {
+		return nil, err
+	}
+	others, found := otherItems(items, id)
+	if !found {
+		return nil, ErrItemNotFound
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for i, itemID := range insertAt(others, id, position) {
+		if _, err := tx.Exec("UPDATE items SET position = ? WHERE id = ?", i, itemID); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := tx.Exec("UPDATE items SET updated_at = ? WHERE id = ?", s.now(), id); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.GetItem(pageID, id)
+}
+
+// DeleteItem deletes an item and closes the gap it leaves on the page
+func (s *SQLStore) DeleteItem(pageID, id int) error {
+	items, err := s.GetItems(pageID)
+	if err != nil {
+		return err
+	}
+	others, found := otherItems(items, id)
+	if !found {
+		return ErrItemNotFound
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM items WHERE id = ?", id); err != nil {
+		return err
+	}
+	for i, itemID := range others {
+		if _, err := tx.Exec("UPDATE items SET position = ? WHERE id = ?", i, itemID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// checkItemImage returns ErrItemImageMissing unless the item image exists.
+// Nil and 0 mean no image.
+func (s *SQLStore) checkItemImage(id *int) error {
+	if id == nil || *id == 0 {
+		return nil
+	}
+	exists, err := s.mediaExists(*id)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrItemImageMissing
+	}
+	return nil
+}
+
+// otherItems returns the IDs of the items in position order, leaving out the
+// item with the given ID, and whether that item was on the list
+func otherItems(items []*Item, except int) ([]int, bool) {
+	var ids []int
+	found := false
+	for _, item := range items {
+		if item.ID == except {
+			found = true
+			continue
+		}
+		ids = append(ids, item.ID)
+	}
+	return ids, found
+}
@@ -0,0 +1,199 @@
+// backend/internal/models/website_invite.go
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrInviteNotFound is returned for invites that do not exist or are not for the user asking
+var ErrInviteNotFound = errors.New("invite not found")
+
+// WebsiteInvite offers a role on a website to the user with an email address.
+// Inviting does not tell whether anyone has registered with the address; the
+// user becomes a member once they accept the invite.
+type WebsiteInvite struct {
+	ID          int         `json:"id"`
+	WebsiteID   int         `json:"website_id"`
+	WebsiteName string      `json:"website_name"`
+	Email       string      `json:"email"`
+	Role        WebsiteRole `json:"role"`
+	InvitedBy   int         `json:"invited_by"` // 0 once the inviting user is deleted
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// inviteSelect is the column list shared by every invite query, read with scanInvite
+const inviteSelect = `
+		SELECT i.id, i.website_id, w.name, i.email, i.role, COALESCE(i.invited_by, 0), i.created_at
+		FROM website_invites i
+		JOIN websites w ON w.id = i.website_id`
+
+// scanInvite reads a row selected with inviteSelect
+func scanInvite(row rowScanner) (*WebsiteInvite, error) {
+	var i WebsiteInvite
+	if err := row.Scan(&i.ID, &i.WebsiteID, &i.WebsiteName, &i.Email, &i.Role, &i.InvitedBy, &i.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// queryInvites runs a query selecting invites with inviteSelect
+func (s *SQLStore) queryInvites(query string, args ...interface{}) ([]*WebsiteInvite, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []*WebsiteInvite{}
+	for rows.Next() {
+		i, err := scanInvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, i)
+	}
+
+	return invites, rows.Err()
+}
+
+// InviteWebsiteMember invites the user with the given email address to a
+// website, replacing any pending invite of the address to the same website
+func (s *SQLStore) InviteWebsiteMember(websiteID int, email string, role WebsiteRole, invitedBy int) (*WebsiteInvite, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	if _, err := s.GetWebsite(websiteID); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := s.now()
+	var id int64
+	err = tx.QueryRow("SELECT id FROM website_invites WHERE website_id = ? AND email = ?", websiteID, email).Scan(&id)
+	switch {
+	case err == sql.ErrNoRows:
+		id, err = tx.Insert(
+			"INSERT INTO website_invites (website_id, email, role, invited_by, created_at) VALUES (?, ?, ?, ?, ?)",
+			websiteID, email, role, invitedBy, now,
+		)
+	case err == nil:
+		_, err = tx.Exec("UPDATE website_invites SET role = ?, invited_by = ?, created_at = ? WHERE id = ?", role, invitedBy, now, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return scanInvite(s.db.QueryRow(inviteSelect+" WHERE i.id = ?", id))
+}
+
+// GetWebsiteInvites retrieves the pending invites of a website ordered by email address
+func (s *SQLStore) GetWebsiteInvites(websiteID int) ([]*WebsiteInvite, error) {
+	return s.queryInvites(inviteSelect+`
+		WHERE i.website_id = ?
+		ORDER BY i.email`,
+		websiteID,
+	)
+}
+
+// GetUserInvites retrieves the pending invites of a user's email address,
+// newest first
+func (s *SQLStore) GetUserInvites(userID int) ([]*WebsiteInvite, error) {
+	return s.queryInvites(inviteSelect+`
+		JOIN users u ON u.email = i.email
+		WHERE u.id = ?
+		ORDER BY i.created_at DESC, i.id DESC`,
+		userID,
+	)
+}
+
+// AcceptWebsiteInvite makes the user a member of the website they were
+// invited to, with the role of the invite, and deletes the invite
+func (s *SQLStore) AcceptWebsiteInvite(id, userID int) (*WebsiteMember, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Only the user with the invited address may accept
+	var websiteID int
+	var role WebsiteRole
+	err = tx.QueryRow(`
+		SELECT i.website_id, i.role FROM website_invites i
+		JOIN users u ON u.email = i.email
+		WHERE i.id = ? AND u.id = ?`,
+		id, userID,
+	).Scan(&websiteID, &role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInviteNotFound
+		}
+		return nil, err
+	}
+
+	var member bool
+	err = tx.QueryRow("SELECT EXISTS(SELECT 1 FROM website_members WHERE website_id = ? AND user_id = ?)", websiteID, userID).Scan(&member)
+	if err != nil {
+		return nil, err
+	}
+	if member {
+		return nil, ErrMemberExists
+	}
+
+	now := s.now()
+	_, err = tx.Exec(
+		"INSERT INTO website_members (website_id, user_id, role, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		websiteID, userID, role, now, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("DELETE FROM website_invites WHERE id = ?", id); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return s.GetWebsiteMember(websiteID, userID)
+}
+
+// DeclineWebsiteInvite deletes an invite of the user's email address
+func (s *SQLStore) DeclineWebsiteInvite(id, userID int) error {
+	result, err := s.db.Exec(
+		"DELETE FROM website_invites WHERE id = ? AND email = (SELECT email FROM users WHERE id = ?)",
+		id, userID,
+	)
+	return inviteDeleted(result, err)
+}
+
+// DeleteWebsiteInvite withdraws a pending invite to a website
+func (s *SQLStore) DeleteWebsiteInvite(websiteID, id int) error {
+	result, err := s.db.Exec("DELETE FROM website_invites WHERE id = ? AND website_id = ?", id, websiteID)
+	return inviteDeleted(result, err)
+}
+
+// inviteDeleted returns ErrInviteNotFound if a DELETE of an invite matched no row
+func inviteDeleted(result sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrInviteNotFound
+	}
+	return nil
+}
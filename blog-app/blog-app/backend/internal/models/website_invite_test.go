@@ -0,0 +1,99 @@
+// backend/internal/models/website_invite_test.go
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestWebsiteInvites(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		owner := mustCreateUser(t, store, "owner")
+		alice := mustCreateUser(t, store, "alice")
+		bob := mustCreateUser(t, store, "bob")
+		website, err := store.CreateWebsite(WebsiteInput{Name: "Site", Domain: "site.example.com"}, owner.ID)
+		if err != nil {
+			t.Fatalf("failed to create website: %v", err)
+		}
+
+		// Registered and unknown addresses are invited alike
+		invite, err := store.InviteWebsiteMember(website.ID, alice.Email, WebsiteViewer, owner.ID)
+		if err != nil {
+			t.Fatalf("InviteWebsiteMember() error = %v", err)
+		}
+		if _, err := store.InviteWebsiteMember(website.ID, "nobody@example.com", WebsiteEditor, owner.ID); err != nil {
+			t.Fatalf("InviteWebsiteMember() for an unknown address error = %v", err)
+		}
+
+		// Inviting the same address again replaces the invite
+		again, err := store.InviteWebsiteMember(website.ID, alice.Email, WebsiteEditor, owner.ID)
+		if err != nil {
+			t.Fatalf("second InviteWebsiteMember() error = %v", err)
+		}
+		if again.ID != invite.ID || again.Role != WebsiteEditor || again.WebsiteName != "Site" {
+			t.Errorf("second invite = %+v, want invite %d as editor of Site", again, invite.ID)
+		}
+		invites, err := store.GetWebsiteInvites(website.ID)
+		if err != nil {
+			t.Fatalf("GetWebsiteInvites() error = %v", err)
+		}
+		if len(invites) != 2 || invites[0].Email != alice.Email || invites[1].Email != "nobody@example.com" {
+			t.Errorf("GetWebsiteInvites() = %+v, want alice's and nobody's", invites)
+		}
+
+		// Only the invited address sees and accepts the invite
+		if mine, err := store.GetUserInvites(bob.ID); err != nil || len(mine) != 0 {
+			t.Errorf("GetUserInvites(bob) = %d invites, %v; want none", len(mine), err)
+		}
+		if mine, err := store.GetUserInvites(alice.ID); err != nil || len(mine) != 1 || mine[0].ID != invite.ID {
+			t.Errorf("GetUserInvites(alice) = %+v, %v; want invite %d", mine, err, invite.ID)
+		}
+		if _, err := store.AcceptWebsiteInvite(invite.ID, bob.ID); !errors.Is(err, ErrInviteNotFound) {
+			t.Errorf("AcceptWebsiteInvite() by bob error = %v, want %v", err, ErrInviteNotFound)
+		}
+		if err := store.DeclineWebsiteInvite(invite.ID, bob.ID); !errors.Is(err, ErrInviteNotFound) {
+			t.Errorf("DeclineWebsiteInvite() by bob error = %v, want %v", err, ErrInviteNotFound)
+		}
+
+		member, err := store.AcceptWebsiteInvite(invite.ID, alice.ID)
+		if err != nil {
+			t.Fatalf("AcceptWebsiteInvite() error = %v", err)
+		}
+		if member.UserID != alice.ID || member.Role != WebsiteEditor {
+			t.Errorf("AcceptWebsiteInvite() = %+v, want alice as editor", member)
+		}
+		if _, err := store.AcceptWebsiteInvite(invite.ID, alice.ID); !errors.Is(err, ErrInviteNotFound) {
+			t.Errorf("accepting twice: error = %v, want %v", err, ErrInviteNotFound)
+		}
+
+		// A member invited again cannot accept a second time
+		invite, err = store.InviteWebsiteMember(website.ID, alice.Email, WebsiteOwner, owner.ID)
+		if err != nil {
+			t.Fatalf("InviteWebsiteMember() error = %v", err)
+		}
+		if _, err := store.AcceptWebsiteInvite(invite.ID, alice.ID); !errors.Is(err, ErrMemberExists) {
+			t.Errorf("accepting as a member: error = %v, want %v", err, ErrMemberExists)
+		}
+		if err := store.DeclineWebsiteInvite(invite.ID, alice.ID); err != nil {
+			t.Errorf("DeclineWebsiteInvite() error = %v", err)
+		}
+
+		// Invites are withdrawn one by one or with their website
+		bobInvite, err := store.InviteWebsiteMember(website.ID, bob.Email, WebsiteViewer, owner.ID)
+		if err != nil {
+			t.Fatalf("InviteWebsiteMember() error = %v", err)
+		}
+		if err := store.DeleteWebsiteInvite(website.ID+1, bobInvite.ID); !errors.Is(err, ErrInviteNotFound) {
+			t.Errorf("DeleteWebsiteInvite() on another website error = %v, want %v", err, ErrInviteNotFound)
+		}
+		if err := store.DeleteWebsiteInvite(website.ID, bobInvite.ID); err != nil {
+			t.Errorf("DeleteWebsiteInvite() error = %v", err)
+		}
+		if err := store.DeleteWebsite(website.ID); err != nil {
+			t.Fatalf("failed to delete website: %v", err)
+		}
+		if invites, err := store.GetWebsiteInvites(website.ID); err != nil || len(invites) != 0 {
+			t.Errorf("GetWebsiteInvites() after deleting the website = %d invites, %v; want none", len(invites), err)
+		}
+	})
+}
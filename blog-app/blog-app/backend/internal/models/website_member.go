@@ -0,0 +1,215 @@
+// backend/internal/models/website_member.go
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// Errors returned by website membership operations
+var (
+	ErrMemberNotFound = errors.New("user is not a member of this website")
+	ErrMemberExists   = errors.New("user is already a member of this website")
+	ErrInvalidRole    = errors.New("role must be owner, editor or viewer")
+	ErrLastOwner      = errors.New("a website must keep at least one owner")
+	ErrSoleOwner      = errors.New("user is the last owner of a website; add another owner or delete the website first")
+)
+
+// WebsiteRole is what a member may do on a website; see package access for the permissions of each role
+type WebsiteRole string
+
+// Website roles, from most to least trusted
+const (
+	WebsiteOwner  WebsiteRole = "owner"  // Manages the website and its members
+	WebsiteEditor WebsiteRole = "editor" // Edits and deletes pages and items
+	WebsiteViewer WebsiteRole = "viewer" // Reads the website
+)
+
+// Valid reports whether r is a known role
+func (r WebsiteRole) Valid() bool {
+	switch r {
+	case WebsiteOwner, WebsiteEditor, WebsiteViewer:
+		return true
+	}
+	return false
+}
+
+// WebsiteMember gives a user a role on a website
+type WebsiteMember struct {
+	WebsiteID int         `json:"website_id"`
+	UserID    int         `json:"user_id"`
+	Username  string      `json:"username"`
+	Role      WebsiteRole `json:"role"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// memberSelect is the column list shared by every membership query, read with scanMember
+const memberSelect = `
+		SELECT m.website_id, m.user_id, u.username, m.role, m.created_at, m.updated_at
+		FROM website_members m
+		JOIN users u ON u.id = m.user_id`
+
+// scanMember reads a row selected with memberSelect
+func scanMember(row rowScanner) (*WebsiteMember, error) {
+	var m WebsiteMember
+	if err := row.Scan(&m.WebsiteID, &m.UserID, &m.Username, &m.Role, &m.CreatedAt, &m.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetWebsitesByMember retrieves the websites a user is a member of, ordered by
+// name, each with the user's role
+func (s *SQLStore) GetWebsitesByMember(userID int) ([]*Website, error) {
+	rows, err := s.db.Query(`
+		SELECT w.id, w.name, w.domain, COALESCE(w.owner_id, 0), w.created_at, w.updated_at, m.role
+		FROM websites w
+		JOIN website_members m ON m.website_id = w.id
+		WHERE m.user_id = ?
+		ORDER BY w.name, w.id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	websites := []*Website{}
+	for rows.Next() {
+		var w Website
+		if err := rows.Scan(&w.ID, &w.Name, &w.Domain, &w.OwnerID, &w.CreatedAt, &w.UpdatedAt, &w.Role); err != nil {
+			return nil, err
+		}
+		websites = append(websites, &w)
+	}
+
+	return websites, rows.Err()
+}
+
+// GetWebsiteMembers retrieves the members of a website ordered by username
+func (s *SQLStore) GetWebsiteMembers(websiteID int) ([]*WebsiteMember, error) {
+	rows, err := s.db.Query(memberSelect+`
+		WHERE m.website_id = ?
+		ORDER BY u.username`,
+		websiteID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*WebsiteMember{}
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+// GetWebsiteMember retrieves a user's membership of a website
+func (s *SQLStore) GetWebsiteMember(websiteID, userID int) (*WebsiteMember, error) {
+	m, err := scanMember(s.db.QueryRow(memberSelect+`
+		WHERE m.website_id = ? AND m.user_id = ?`,
+		websiteID, userID,
+	))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrMemberNotFound
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+// AddWebsiteMember gives a user a role on a website
+func (s *SQLStore) AddWebsiteMember(websiteID, userID int, role WebsiteRole) (*WebsiteMember, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	if _, err := s.GetWebsite(websiteID); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetUserByID(userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetWebsiteMember(websiteID, userID); err == nil {
+		return nil, ErrMemberExists
+	} else if !errors.Is(err, ErrMemberNotFound) {
+		return nil, err
+	}
+
+	now := s.now()
+	_, err := s.db.Exec(
+		"INSERT INTO website_members (website_id, user_id, role, created_at, updated_at) VALUES (?, ?, ?, ?, ?)",
+		websiteID, userID, role, now, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetWebsiteMember(websiteID, userID)
+}
+
+// UpdateWebsiteMember changes a member's role. The last owner cannot be demoted.
+func (s *SQLStore) UpdateWebsiteMember(websiteID, userID int, role WebsiteRole) (*WebsiteMember, error) {
+	if !role.Valid() {
+		return nil, ErrInvalidRole
+	}
+	member, err := s.GetWebsiteMember(websiteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == WebsiteOwner && role != WebsiteOwner {
+		if err := s.checkOtherOwner(websiteID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = s.db.Exec(
+		"UPDATE website_members SET role = ?, updated_at = ? WHERE website_id = ? AND user_id = ?",
+		role, s.now(), websiteID, userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetWebsiteMember(websiteID, userID)
+}
+
+// RemoveWebsiteMember takes a user off a website. The last owner cannot be removed.
+func (s *SQLStore) RemoveWebsiteMember(websiteID, userID int) error {
+	member, err := s.GetWebsiteMember(websiteID, userID)
+	if err != nil {
+		return err
+	}
+	if member.Role == WebsiteOwner {
+		if err := s.checkOtherOwner(websiteID, userID); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.db.Exec("DELETE FROM website_members WHERE website_id = ? AND user_id = ?", websiteID, userID)
+	return err
+}
+
+// checkOtherOwner returns ErrLastOwner unless the website has an owner besides userID
+func (s *SQLStore) checkOtherOwner(websiteID, userID int) error {
+	var others bool
+	err := s.db.QueryRow(
+		"SELECT EXISTS(SELECT 1 FROM website_members WHERE website_id = ? AND role = ? AND user_id <> ?)",
+		websiteID, WebsiteOwner, userID,
+	).Scan(&others)
+	if err != nil {
+		return err
+	}
+	if !others {
+		return ErrLastOwner
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// backend/internal/moderation/policy.go
+package moderation
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"blog-app/internal/models"
+)
+
+// Policy decides who may moderate comments. Post authors moderate the
+// comments on their own posts, and users whose role allows it moderate every comment.
+type Policy struct{}
+
+// NewPolicy creates a moderation policy
+func NewPolicy() *Policy {
+	return &Policy{}
+}
+
+// ModeratesAll reports whether the user may moderate every comment
+func (p *Policy) ModeratesAll(user *models.User) bool {
+	return user.Role.Can(models.PermModerateComments)
+}
+
+// CanModerate reports whether the user may moderate comments on the post
+func (p *Policy) CanModerate(user *models.User, post *models.Post) bool {
+	return post.AuthorID == user.ID || p.ModeratesAll(user)
+}
+
+// ConfigFromEnv reads the scorer settings through getenv, falling back to DefaultConfig:
+//   - SPAM_MAX_LINKS: links allowed before a comment is penalised
+//   - SPAM_BLOCKLIST: comma-separated blocklisted words
+//   - COMMENT_RATE_LIMIT: comments allowed per user within COMMENT_RATE_WINDOW
+//   - COMMENT_RATE_WINDOW: duration such as "10m"
+func ConfigFromEnv(getenv func(string) string) (Config, error) {
+	cfg := DefaultConfig()
+
+	if v := getenv("SPAM_MAX_LINKS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return cfg, fmt.Errorf("invalid SPAM_MAX_LINKS %q", v)
+		}
+		cfg.MaxLinks = n
+	}
+	if v := getenv("SPAM_BLOCKLIST"); v != "" {
+		cfg.Blocklist = nil
+		for _, word := range strings.Split(v, ",") {
+			if word = strings.TrimSpace(word); word != "" {
+				cfg.Blocklist = append(cfg.Blocklist, word)
+			}
+		}
+	}
+	if v := getenv("COMMENT_RATE_LIMIT"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			return cfg, fmt.Errorf("invalid COMMENT_RATE_LIMIT %q", v)
+		}
+		cfg.RateLimit = n
+	}
+	if v := getenv("COMMENT_RATE_WINDOW"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("invalid COMMENT_RATE_WINDOW %q", v)
+		}
+		cfg.RateWindow = d
+	}
+
+	return cfg, nil
+}
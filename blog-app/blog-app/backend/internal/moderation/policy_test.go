@@ -0,0 +1,72 @@
+// backend/internal/moderation/policy_test.go
+package moderation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"blog-app/internal/models"
+)
+
+func TestCanModerate(t *testing.T) {
+	p := NewPolicy()
+	post := &models.Post{ID: 1, AuthorID: 10}
+
+	tests := []struct {
+		name string
+		user *models.User
+		want bool
+	}{
+		{"post author", &models.User{ID: 10, Role: models.RoleAuthor}, true},
+		{"another author", &models.User{ID: 11, Role: models.RoleAuthor}, false},
+		{"reader", &models.User{ID: 12, Role: models.RoleReader}, false},
+		{"editor", &models.User{ID: 13, Role: models.RoleEditor}, true},
+		{"admin", &models.User{ID: 14, Role: models.RoleAdmin}, true},
+	}
+	for _, tt := range tests {
+		if got := p.CanModerate(tt.user, post); got != tt.want {
+			t.Errorf("%s: CanModerate() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	env := map[string]string{
+		"SPAM_MAX_LINKS":      "4",
+		"SPAM_BLOCKLIST":      " casino, ,lottery ",
+		"COMMENT_RATE_LIMIT":  "3",
+		"COMMENT_RATE_WINDOW": "1h",
+	}
+	cfg, err := ConfigFromEnv(func(key string) string { return env[key] })
+	if err != nil {
+		t.Fatalf("ConfigFromEnv() error = %v", err)
+	}
+	want := Config{MaxLinks: 4, Blocklist: []string{"casino", "lottery"}, RateLimit: 3, RateWindow: time.Hour}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("ConfigFromEnv() = %+v, want %+v", cfg, want)
+	}
+
+	// Nothing set gives the defaults
+	cfg, err = ConfigFromEnv(func(string) string { return "" })
+	if err != nil || !reflect.DeepEqual(cfg, DefaultConfig()) {
+		t.Errorf("ConfigFromEnv() with nothing set = %+v, %v; want the defaults", cfg, err)
+	}
+
+	// Invalid values are reported
+	for key, value := range map[string]string{
+		"SPAM_MAX_LINKS":      "-1",
+		"COMMENT_RATE_LIMIT":  "0",
+		"COMMENT_RATE_WINDOW": "soon",
+	} {
+		getenv := func(k string) string {
+			if k == key {
+				return value
+			}
+			return ""
+		}
+		if _, err := ConfigFromEnv(getenv); err == nil {
+			t.Errorf("ConfigFromEnv() with %s=%q succeeded, want an error", key, value)
+		}
+	}
+}
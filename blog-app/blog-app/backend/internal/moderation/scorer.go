@@ -0,0 +1,193 @@
+// backend/internal/moderation/scorer.go
+package moderation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SpamThreshold is the score at which a new comment goes straight to the spam folder
+// instead of the moderation queue
+const SpamThreshold = 1.0
+
+// Candidate is a comment about to be stored
+type Candidate struct {
+	PostID   int
+	AuthorID int
+	Content  string
+}
+
+// Result is the verdict of a scorer. Scores add up across scorers.
+type Result struct {
+	Score   float64
+	Reasons []string
+}
+
+// IsSpam reports whether the result reaches SpamThreshold
+func (r Result) IsSpam() bool {
+	return r.Score >= SpamThreshold
+}
+
+// Scorer rates how likely a comment is to be spam.
+// Implementations must be safe for concurrent use.
+type Scorer interface {
+	Score(c Candidate) Result
+}
+
+// Chain runs several scorers and adds up their results
+type Chain []Scorer
+
+// Score implements Scorer
+func (ch Chain) Score(c Candidate) Result {
+	var total Result
+	for _, scorer := range ch {
+		r := scorer.Score(c)
+		total.Score += r.Score
+		total.Reasons = append(total.Reasons, r.Reasons...)
+	}
+	return total
+}
+
+// linkPattern matches URLs and bare www. addresses
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// LinkScorer penalises comments with more than MaxLinks links
+type LinkScorer struct {
+	MaxLinks int
+	PerLink  float64 // Score added for every link over the limit
+}
+
+// Score implements Scorer
+func (s LinkScorer) Score(c Candidate) Result {
+	links := len(linkPattern.FindAllString(c.Content, -1))
+	if links <= s.MaxLinks {
+		return Result{}
+	}
+	return Result{
+		Score:   float64(links-s.MaxLinks) * s.PerLink,
+		Reasons: []string{fmt.Sprintf("%d links", links)},
+	}
+}
+
+// BlocklistScorer penalises comments containing blocklisted words, ignoring case
+type BlocklistScorer struct {
+	Words   []string
+	PerWord float64 // Score added for every distinct blocklisted word found
+}
+
+// Score implements Scorer
+func (s BlocklistScorer) Score(c Candidate) Result {
+	content := strings.ToLower(c.Content)
+	var result Result
+	for _, word := range s.Words {
+		if word != "" && strings.Contains(content, strings.ToLower(word)) {
+			result.Score += s.PerWord
+			result.Reasons = append(result.Reasons, fmt.Sprintf("blocklisted word %q", word))
+		}
+	}
+	return result
+}
+
+// RateScorer penalises users posting more than Limit comments within Window.
+// It keeps its history in memory, so limits are per API instance.
+type RateScorer struct {
+	Limit   int
+	Window  time.Duration
+	Penalty float64 // Score added once the limit is exceeded
+
+	mu        sync.Mutex
+	recent    map[int][]time.Time // keyed by author ID
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewRateScorer creates a scorer allowing limit comments per user within window
+func NewRateScorer(limit int, window time.Duration, penalty float64) *RateScorer {
+	return &RateScorer{
+		Limit:   limit,
+		Window:  window,
+		Penalty: penalty,
+		recent:  make(map[int][]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Score implements Scorer. Every scored comment counts towards the limit.
+func (s *RateScorer) Score(c Candidate) Result {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	// Forget comments that have left the window
+	kept := s.inWindow(s.recent[c.AuthorID], now)
+	kept = append(kept, now)
+	s.recent[c.AuthorID] = kept
+
+	if len(kept) <= s.Limit {
+		return Result{}
+	}
+	return Result{
+		Score:   s.Penalty,
+		Reasons: []string{fmt.Sprintf("%d comments in %s", len(kept), s.Window)},
+	}
+}
+
+// sweep forgets the authors whose comments have all left the window, at most
+// once per window, so that the history only holds recent authors.
+// The caller must hold the lock.
+func (s *RateScorer) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < s.Window {
+		return
+	}
+	s.lastSweep = now
+	for author, times := range s.recent {
+		if len(s.inWindow(times, now)) == 0 {
+			delete(s.recent, author)
+		}
+	}
+}
+
+// inWindow returns the times that are still within the window
+func (s *RateScorer) inWindow(times []time.Time, now time.Time) []time.Time {
+	var kept []time.Time
+	for _, t := range times {
+		if now.Sub(t) < s.Window {
+			kept = append(kept, t)
+		}
+	}
+	return kept
+}
+
+// Config holds the settings of the default scorer
+type Config struct {
+	MaxLinks   int
+	Blocklist  []string
+	RateLimit  int
+	RateWindow time.Duration
+}
+
+// DefaultConfig returns the settings used when nothing is configured
+func DefaultConfig() Config {
+	return Config{
+		MaxLinks:   2,
+		Blocklist:  []string{"viagra", "casino", "crypto giveaway", "free money"},
+		RateLimit:  5,
+		RateWindow: 10 * time.Minute,
+	}
+}
+
+// NewScorer builds the default chain of local scorers. A single blocklisted
+// word or a burst of comments is enough to reach SpamThreshold on its own,
+// while every extra link only adds a third of it.
+func NewScorer(cfg Config) Scorer {
+	return Chain{
+		LinkScorer{MaxLinks: cfg.MaxLinks, PerLink: SpamThreshold / 3},
+		BlocklistScorer{Words: cfg.Blocklist, PerWord: SpamThreshold},
+		NewRateScorer(cfg.RateLimit, cfg.RateWindow, SpamThreshold),
+	}
+}
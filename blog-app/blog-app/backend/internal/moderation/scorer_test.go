@@ -0,0 +1,104 @@
+// backend/internal/moderation/scorer_test.go
+package moderation
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLinkScorer(t *testing.T) {
+	s := LinkScorer{MaxLinks: 1, PerLink: 0.5}
+
+	tests := []struct {
+		content string
+		want    float64
+	}{
+		{"no links at all", 0},
+		{"see https://example.com", 0},
+		{"see https://example.com and www.example.org", 0.5},
+		{"HTTP://a.example http://b.example www.c.example", 1},
+	}
+	for _, tt := range tests {
+		if got := s.Score(Candidate{Content: tt.content}).Score; got != tt.want {
+			t.Errorf("Score(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestBlocklistScorer(t *testing.T) {
+	s := BlocklistScorer{Words: []string{"casino", "free money", ""}, PerWord: 1}
+
+	// Words are matched ignoring case and counted once each
+	r := s.Score(Candidate{Content: "Win FREE MONEY at the casino, the best casino"})
+	if r.Score != 2 || len(r.Reasons) != 2 {
+		t.Errorf("Score() = %+v, want 2 for two words", r)
+	}
+	if r := s.Score(Candidate{Content: "A post about card games"}); r.Score != 0 {
+		t.Errorf("Score() of a clean comment = %+v, want 0", r)
+	}
+}
+
+func TestChain(t *testing.T) {
+	chain := Chain{
+		LinkScorer{MaxLinks: 0, PerLink: 0.25},
+		BlocklistScorer{Words: []string{"casino"}, PerWord: 1},
+	}
+
+	r := chain.Score(Candidate{Content: "casino at https://example.com"})
+	if r.Score != 1.25 || len(r.Reasons) != 2 || !r.IsSpam() {
+		t.Errorf("Score() = %+v, want 1.25 from both scorers", r)
+	}
+	if r := chain.Score(Candidate{Content: "https://example.com"}); r.IsSpam() {
+		t.Errorf("Score() of a single link = %+v, want it below SpamThreshold", r)
+	}
+}
+
+// fakeClock returns a clock for a RateScorer that only moves when advanced
+func fakeClock(s *RateScorer) func(time.Duration) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestRateScorer(t *testing.T) {
+	s := NewRateScorer(2, 10*time.Minute, 1)
+	advance := fakeClock(s)
+
+	// The limit is per author
+	for i := 0; i < 2; i++ {
+		if r := s.Score(Candidate{AuthorID: 1}); r.Score != 0 {
+			t.Fatalf("comment %d: Score() = %+v, want 0 within the limit", i+1, r)
+		}
+	}
+	if r := s.Score(Candidate{AuthorID: 2}); r.Score != 0 {
+		t.Errorf("another author's comment: Score() = %+v, want 0", r)
+	}
+	if r := s.Score(Candidate{AuthorID: 1}); r.Score != 1 {
+		t.Errorf("third comment: Score() = %+v, want the penalty", r)
+	}
+
+	// Comments that have left the window no longer count
+	advance(10 * time.Minute)
+	if r := s.Score(Candidate{AuthorID: 1}); r.Score != 0 {
+		t.Errorf("comment after the window: Score() = %+v, want 0", r)
+	}
+}
+
+func TestRateScorerForgetsQuietAuthors(t *testing.T) {
+	s := NewRateScorer(5, time.Minute, 1)
+	advance := fakeClock(s)
+
+	for author := 1; author <= 100; author++ {
+		s.Score(Candidate{AuthorID: author})
+	}
+	if len(s.recent) != 100 {
+		t.Fatalf("history holds %d authors, want 100", len(s.recent))
+	}
+
+	// The next comment after the window sweeps out everyone else
+	advance(time.Minute)
+	s.Score(Candidate{AuthorID: 1})
+	if len(s.recent) != 1 || len(s.recent[1]) != 1 {
+		t.Errorf("history after the window = %v, want only author 1's new comment", s.recent)
+	}
+}
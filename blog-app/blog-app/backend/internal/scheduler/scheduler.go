@@ -0,0 +1,130 @@
+// backend/internal/scheduler/scheduler.go
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/kballard/go-shellquote"
+
+	"blog-app/internal/models"
+)
+
+// Publisher publishes scheduled posts once their time has come.
+// It is implemented by every models.PostStore.
+type Publisher interface {
+	PublishDuePosts(now time.Time) ([]*models.Post, error)
+}
+
+// PublishHook is called after a run has published at least one post
+type PublishHook func(ctx context.Context, posts []*models.Post) error
+
+// Scheduler periodically publishes scheduled posts in the background.
+// All state lives in the store, so posts that fell due while the API was
+// down are published on the first run after a restart.
+type Scheduler struct {
+	publisher Publisher
+	interval  time.Duration
+	onPublish PublishHook
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// New creates a scheduler that checks for due posts every interval.
+// onPublish may be nil.
+func New(publisher Publisher, interval time.Duration, onPublish PublishHook) *Scheduler {
+	return &Scheduler{
+		publisher: publisher,
+		interval:  interval,
+		onPublish: onPublish,
+	}
+}
+
+// Start runs the scheduler in a background goroutine
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+
+	go s.loop(ctx)
+}
+
+// Stop signals the scheduler to exit and waits for a run in progress to finish,
+// or for ctx to expire
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop runs once immediately to catch up after a restart, then on every tick
+func (s *Scheduler) loop(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		s.run(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// run publishes the posts that are due and fires the publish hook
+func (s *Scheduler) run(ctx context.Context) {
+	posts, err := s.publisher.PublishDuePosts(time.Now())
+	for _, post := range posts {
+		log.Printf("Scheduler published post %d (%q)", post.ID, post.Title)
+	}
+	if err != nil {
+		log.Printf("Scheduler failed to publish due posts: %v", err)
+	}
+
+	if len(posts) == 0 || s.onPublish == nil {
+		return
+	}
+	if err := s.onPublish(ctx, posts); err != nil {
+		log.Printf("Scheduler publish hook failed: %v", err)
+	}
+}
+
+// CommandHook returns a hook that runs a command line, such as
+// "./static-gen -output /static", to rebuild the static site after publishing.
+// The line is split into words like a POSIX shell does, honouring quotes and
+// backslashes, but it is not run through a shell: there are no variables,
+// pipes or redirections. The command is killed if the scheduler stops while
+// it is running.
+func CommandHook(command string) (PublishHook, error) {
+	args, err := shellquote.Split(command)
+	if err != nil {
+		return nil, fmt.Errorf("invalid command %q: %w", command, err)
+	}
+	return func(ctx context.Context, posts []*models.Post) error {
+		if len(args) == 0 {
+			return nil
+		}
+
+		log.Printf("Scheduler running %q after publishing %d posts", command, len(posts))
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		return cmd.Run()
+	}, nil
+}
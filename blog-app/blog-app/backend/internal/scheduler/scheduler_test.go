@@ -0,0 +1,156 @@
+// backend/internal/scheduler/scheduler_test.go
+package scheduler
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"blog-app/internal/models"
+)
+
+// fakePublisher publishes the posts it holds on its first run
+type fakePublisher struct {
+	mu    sync.Mutex
+	posts []*models.Post
+	runs  int
+}
+
+func (p *fakePublisher) PublishDuePosts(now time.Time) ([]*models.Post, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.runs++
+	posts := p.posts
+	p.posts = nil
+	return posts, nil
+}
+
+func TestSchedulerPublishesDuePosts(t *testing.T) {
+	publisher := &fakePublisher{posts: []*models.Post{{ID: 1, Title: "Due"}}}
+	hooked := make(chan []*models.Post, 10)
+	hook := func(ctx context.Context, posts []*models.Post) error {
+		hooked <- posts
+		return nil
+	}
+
+	s := New(publisher, 10*time.Millisecond, hook)
+	s.Start()
+
+	// The first run happens straight away and fires the hook
+	select {
+	case posts := <-hooked:
+		if len(posts) != 1 || posts[0].ID != 1 {
+			t.Errorf("hook got %v, want post 1", posts)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("hook was not called after publishing")
+	}
+
+	// Later runs publish nothing and leave the hook alone
+	time.Sleep(50 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := s.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+
+	publisher.mu.Lock()
+	runs := publisher.runs
+	publisher.mu.Unlock()
+	if runs < 2 {
+		t.Errorf("publisher ran %d times, want it to run on every tick", runs)
+	}
+	if len(hooked) != 0 {
+		t.Errorf("hook called %d more times without new posts", len(hooked))
+	}
+}
+
+func TestSchedulerStopWithoutStart(t *testing.T) {
+	if err := New(&fakePublisher{}, time.Minute, nil).Stop(context.Background()); err != nil {
+		t.Errorf("Stop() error = %v", err)
+	}
+}
+
+func TestCommandHookSplitsLikeAShell(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    []string
+	}{
+		{"plain words", `-output /static`, []string{"-output", "/static"}},
+		{"double quotes", `-output "/srv/my site"`, []string{"-output", "/srv/my site"}},
+		{"single quotes", `-title 'a "quoted" $HOME'`, []string{"-title", `a "quoted" $HOME`}},
+		{"backslashes", `-output /srv/my\ site -sep \\`, []string{"-output", "/srv/my site", "-sep", `\`}},
+		{"no shell features", `a; rm -rf x | b > c`, []string{"a;", "rm", "-rf", "x", "|", "b", ">", "c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The shell prints each argument it is given on a line of its own
+			out := filepath.Join(t.TempDir(), "args")
+			hook, err := CommandHook(`sh -c 'printf "%s\n" "$@" > "$0"' ` + out + " " + tt.command)
+			if err != nil {
+				t.Fatalf("CommandHook() error = %v", err)
+			}
+			if err := hook(context.Background(), []*models.Post{{ID: 1}}); err != nil {
+				t.Fatalf("hook error = %v", err)
+			}
+
+			data, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("command did not run: %v", err)
+			}
+			got := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("command got arguments %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandHookErrors(t *testing.T) {
+	for _, command := range []string{`static-gen -output "/static`, `static-gen -output '/static`, `static-gen \`} {
+		if _, err := CommandHook(command); err == nil {
+			t.Errorf("CommandHook(%q) succeeded, want an error", command)
+		}
+	}
+
+	// An empty command does nothing
+	hook, err := CommandHook("  ")
+	if err != nil {
+		t.Fatalf("CommandHook() of an empty command error = %v", err)
+	}
+	if err := hook(context.Background(), nil); err != nil {
+		t.Errorf("empty hook error = %v", err)
+	}
+
+	// A failing command is reported
+	hook, err = CommandHook(`sh -c "exit 3"`)
+	if err != nil {
+		t.Fatalf("CommandHook() error = %v", err)
+	}
+	if err := hook(context.Background(), nil); err == nil {
+		t.Error("hook of a failing command succeeded")
+	}
+
+	// The command is killed when the scheduler stops
+	hook, err = CommandHook("sleep 10")
+	if err != nil {
+		t.Fatalf("CommandHook() error = %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := hook(ctx, nil); err == nil {
+		t.Error("hook of a cancelled command succeeded")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancelled command ran for %v", elapsed)
+	}
+}
@@ -0,0 +1,137 @@
+// backend/internal/search/index.go
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Ranking parameters. Words in the title count TitleWeight times as much as
+// words in the body; k1 and b are the usual BM25 constants.
+const (
+	TitleWeight = 2.0
+	k1          = 1.2
+	b           = 0.75
+)
+
+// Hit is a document matching a query
+type Hit struct {
+	ID    int
+	Score float64
+}
+
+// Index is an in-memory inverted index over documents made of a title and a
+// plain text body, ranked with BM25. It is safe for concurrent use.
+type Index struct {
+	mu          sync.RWMutex
+	postings    map[string]map[int]float64 // term -> document ID -> weighted frequency
+	lengths     map[int]float64            // document ID -> weighted length
+	totalLength float64
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{
+		postings: make(map[string]map[int]float64),
+		lengths:  make(map[int]float64),
+	}
+}
+
+// Add indexes a document, replacing any earlier version with the same ID.
+// text must already be plain text; see StripHTML.
+func (ix *Index) Add(id int, title, text string) {
+	freqs := make(map[string]float64)
+	length := 0.0
+	for _, t := range Tokenize(title) {
+		freqs[t] += TitleWeight
+		length += TitleWeight
+	}
+	for _, t := range Tokenize(text) {
+		freqs[t]++
+		length++
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.remove(id)
+	for t, f := range freqs {
+		docs, ok := ix.postings[t]
+		if !ok {
+			docs = make(map[int]float64)
+			ix.postings[t] = docs
+		}
+		docs[id] = f
+	}
+	ix.lengths[id] = length
+	ix.totalLength += length
+}
+
+// Remove drops a document from the index
+func (ix *Index) Remove(id int) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.remove(id)
+}
+
+// remove drops a document. The caller must hold the write lock.
+func (ix *Index) remove(id int) {
+	length, ok := ix.lengths[id]
+	if !ok {
+		return
+	}
+	for t, docs := range ix.postings {
+		if _, ok := docs[id]; ok {
+			delete(docs, id)
+			if len(docs) == 0 {
+				delete(ix.postings, t)
+			}
+		}
+	}
+	delete(ix.lengths, id)
+	ix.totalLength -= length
+}
+
+// Search returns every document containing at least one of the query's terms,
+// best match first
+func (ix *Index) Search(query string) []Hit {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	n := float64(len(ix.lengths))
+	if n == 0 {
+		return nil
+	}
+	avgLength := ix.totalLength / n
+
+	scores := make(map[int]float64)
+	seen := make(map[string]bool)
+	for _, t := range Tokenize(query) {
+		if seen[t] {
+			continue
+		}
+		seen[t] = true
+
+		docs := ix.postings[t]
+		df := float64(len(docs))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for id, f := range docs {
+			norm := 1 - b + b*ix.lengths[id]/avgLength
+			scores[id] += idf * f * (k1 + 1) / (f + k1*norm)
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score == hits[j].Score {
+			return hits[i].ID > hits[j].ID
+		}
+		return hits[i].Score > hits[j].Score
+	})
+	return hits
+}
@@ -0,0 +1,193 @@
+// backend/internal/search/text.go
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// skipContent lists the elements whose content is never shown to readers
+var skipContent = map[string]bool{"script": true, "style": true}
+
+// StripHTML reduces post content to the plain text a reader sees: tags are
+// removed along with script and style elements, entities are decoded and runs
+// of whitespace collapse to a single space
+func StripHTML(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		if !isTagStart(s, i) {
+			// Copy text up to the next possible tag
+			next := strings.IndexByte(s[i+1:], '<') + 1
+			if next == 0 {
+				next = len(s) - i
+			}
+			b.WriteString(s[i : i+next])
+			i += next
+			continue
+		}
+
+		end := strings.IndexByte(s[i:], '>')
+		if end < 0 {
+			// An unterminated tag is treated as text
+			b.WriteString(s[i:])
+			break
+		}
+		name := tagName(s[i+1 : i+end])
+		i += end + 1
+
+		// Tags separate words, e.g. "<p>one</p><p>two</p>"
+		b.WriteByte(' ')
+
+		if skipContent[name] {
+			closing := strings.Index(strings.ToLower(s[i:]), "</"+name)
+			if closing < 0 {
+				break
+			}
+			i += closing
+		}
+	}
+
+	return strings.Join(strings.Fields(html.UnescapeString(b.String())), " ")
+}
+
+// isTagStart reports whether s has a tag at i. A "<" that is not followed by
+// a letter, "/" or "!" is plain text, as in "a < b".
+func isTagStart(s string, i int) bool {
+	if s[i] != '<' || i+1 == len(s) {
+		return false
+	}
+	c := s[i+1]
+	return c == '/' || c == '!' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// tagName returns the lower-case element name of the inside of a tag,
+// or "" for closing tags, comments and doctypes
+func tagName(tag string) string {
+	end := strings.IndexFunc(tag, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if end < 0 {
+		end = len(tag)
+	}
+	return strings.ToLower(tag[:end])
+}
+
+// minTermLength is the shortest word that is indexed, in characters
+const minTermLength = 2
+
+// span is the byte range of a word within a text
+type span struct {
+	start, end int
+}
+
+// words returns the byte ranges of the letter and digit runs in s
+func words(s string) []span {
+	var spans []span
+	start := -1
+	for i, r := range s {
+		inWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case inWord && start < 0:
+			start = i
+		case !inWord && start >= 0:
+			spans = append(spans, span{start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		spans = append(spans, span{start, len(s)})
+	}
+	return spans
+}
+
+// term returns the indexed form of a word, or "" if it is too short to index
+func term(word string) string {
+	if utf8.RuneCountInString(word) < minTermLength {
+		return ""
+	}
+	return strings.ToLower(word)
+}
+
+// Tokenize splits text into lower-case search terms, in order and with repeats
+func Tokenize(s string) []string {
+	var terms []string
+	for _, w := range words(s) {
+		if t := term(s[w.start:w.end]); t != "" {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// Snippet returns an excerpt of about length bytes of plain text around the
+// first word matching one of the query terms. The excerpt is HTML-escaped and
+// matching words are wrapped in <mark> tags. Text cut off at either end is
+// replaced with an ellipsis.
+func Snippet(text string, terms []string, length int) string {
+	wanted := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		wanted[t] = true
+	}
+
+	spans := words(text)
+	isMatch := func(w span) bool { return wanted[term(text[w.start:w.end])] }
+
+	// Start a few words before the first match so it has some context,
+	// unless the match is already close enough to the beginning
+	first := -1
+	for i, w := range spans {
+		if isMatch(w) {
+			first = i
+			break
+		}
+	}
+	start := 0
+	if first > 0 && spans[first].end > length {
+		start = spans[first].start
+		for i := first - 1; i >= 0 && spans[first].start-spans[i].start <= length/4; i-- {
+			start = spans[i].start
+		}
+	}
+
+	// End after the last word that fits
+	end := len(text)
+	if end-start > length {
+		end = start
+		for _, w := range spans {
+			if w.start >= start && w.end-start <= length {
+				end = w.end
+			}
+		}
+		if end == start {
+			// A single word longer than the snippet is cut in the middle
+			end = start + length
+			for !utf8.RuneStart(text[end]) {
+				end--
+			}
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, w := range spans {
+		if w.start < start || w.end > end || !isMatch(w) {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:w.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[w.start:w.end]))
+		b.WriteString("</mark>")
+		pos = w.end
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
@@ -8,10 +8,13 @@ import { useAuth } from '../context/AuthContext';
 // Import ReactQuill dynamically to avoid SSR issues
 const ReactQuill = dynamic(() => import('react-quill-new'), { ssr: false });
 
+export type PostStatus = 'draft' | 'published' | 'scheduled' | 'archived';
+
 interface Post {
   id?: number;
   title: string;
   content: string;
+  status?: PostStatus;
   author_id?: number;
   author?: string;
   created_at?: string;
@@ -26,6 +29,7 @@ interface PostEditorProps {
 const PostEditor: React.FC<PostEditorProps> = ({ post, isEditing = false }) => {
   const [title, setTitle] = useState('');
   const [content, setContent] = useState('');
+  const [status, setStatus] = useState<PostStatus>('draft');
   const [isLoading, setIsLoading] = useState(false);
   const [error, setError] = useState<string | null>(null);
   const router = useRouter();
@@ -49,6 +53,7 @@ const PostEditor: React.FC<PostEditorProps> = ({ post, isEditing = false }) => {
     if (isEditing && post) {
       setTitle(post.title);
       setContent(post.content);
+      setStatus(post.status || 'draft');
     }
   }, [isEditing, post]);
 
@@ -71,10 +76,15 @@ const PostEditor: React.FC<PostEditorProps> = ({ post, isEditing = false }) => {
 
       if (isEditing && post?.id) {
         // Update existing post
-        await axios.put(`/posts/${post.id}`, { title, content });
+        // Leave the status alone unless it was changed, so scheduled posts stay scheduled
+        await axios.put(`/posts/${post.id}`, {
+          title,
+          content,
+          status: status !== post.status ? status : undefined,
+        });
       } else {
         // Create new post
-        await axios.post('/posts', { title, content });
+        await axios.post('/posts', { title, content, status });
       }
 
       router.push('/posts');
@@ -128,6 +138,24 @@ const PostEditor: React.FC<PostEditorProps> = ({ post, isEditing = false }) => {
           </div>
         </div>
 
+        <div className="mb-4">
+          <label htmlFor="status" className="block text-gray-700 font-bold mb-2">
+            Status
+          </label>
+          <select
+            id="status"
+            value={status}
+            onChange={(e) => setStatus(e.target.value as PostStatus)}
+            className="px-3 py-2 border border-gray-300 rounded focus:outline-none focus:ring-2 focus:ring-blue-500"
+          >
+            <option value="draft">Draft (only you and editors can see it)</option>
+            <option value="published">Published</option>
+            {/* Scheduled posts keep their status until the scheduler publishes them */}
+            {status === 'scheduled' && <option value="scheduled">Scheduled</option>}
+            {isEditing && <option value="archived">Archived</option>}
+          </select>
+        </div>
+
         <div className="flex justify-end gap-2">
           <button
             type="button"
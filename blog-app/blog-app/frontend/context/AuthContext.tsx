@@ -1,6 +1,6 @@
 // frontend/context/AuthContext.tsx
 import React, { createContext, useState, useContext, useEffect } from 'react';
-import axios from 'axios';
+import axios, { AxiosError, InternalAxiosRequestConfig } from 'axios';
 import { useRouter } from 'next/router';
 
 // Types
@@ -16,10 +16,98 @@ interface AuthContextType {
   loading: boolean;
   login: (email: string, password: string) => Promise<void>;
   register: (username: string, email: string, password: string) => Promise<void>;
-  logout: () => void;
+  logout: () => Promise<void>;
   isAuthenticated: boolean;
 }
 
+// Response of register, login and refresh
+interface Session {
+  token: string;
+  refresh_token: string;
+  user: User;
+}
+
+// A request that is retried once after refreshing the access token
+interface RetryableRequest extends InternalAxiosRequestConfig {
+  _retried?: boolean;
+}
+
+// Save the tokens and user data, and send the access token with every request
+const saveSession = ({ token, refresh_token, user }: Session) => {
+  localStorage.setItem('token', token);
+  localStorage.setItem('refresh_token', refresh_token);
+  localStorage.setItem('user', JSON.stringify(user));
+  axios.defaults.headers.common['Authorization'] = `Bearer ${token}`;
+};
+
+// Forget the tokens and user data
+const clearSession = () => {
+  localStorage.removeItem('token');
+  localStorage.removeItem('refresh_token');
+  localStorage.removeItem('user');
+  delete axios.defaults.headers.common['Authorization'];
+};
+
+// Requests that must not trigger a refresh when they fail with 401. Logging out
+// does, so that an expired access token still ends the session.
+const authPaths = ['/auth/login', '/auth/register', '/auth/refresh'];
+
+// The refresh in flight, shared by requests failing at the same time: each
+// refresh token works once, so a second refresh with it would log the user out
+let refreshing: Promise<Session> | null = null;
+
+const refreshSession = (): Promise<Session> => {
+  if (!refreshing) {
+    const refreshToken = localStorage.getItem('refresh_token');
+    refreshing = axios
+      .post<Session>('/auth/refresh', { refresh_token: refreshToken })
+      .then((response) => {
+        saveSession(response.data);
+        return response.data;
+      })
+      .finally(() => {
+        refreshing = null;
+      });
+  }
+  return refreshing;
+};
+
+// Told when the interceptor refreshes the session or gives up on it; set by AuthProvider
+let onSessionChange: (session: Session | null) => void = () => {};
+
+// Refresh the access token when it has expired and retry the request; when the
+// session cannot be refreshed, log out. This is registered when the module
+// loads so that it also covers the first requests of a page.
+axios.interceptors.response.use(
+  (response) => response,
+  async (error: AxiosError) => {
+    const request = error.config as RetryableRequest | undefined;
+    if (
+      error.response?.status !== 401 ||
+      !request ||
+      request._retried ||
+      authPaths.includes(request.url || '') ||
+      !localStorage.getItem('refresh_token')
+    ) {
+      throw error;
+    }
+    request._retried = true;
+
+    let session: Session;
+    try {
+      session = await refreshSession();
+    } catch {
+      clearSession();
+      onSessionChange(null);
+      throw error;
+    }
+
+    onSessionChange(session);
+    request.headers['Authorization'] = `Bearer ${session.token}`;
+    return axios(request);
+  }
+);
+
 // Create context
 const AuthContext = createContext<AuthContextType | undefined>(undefined);
 
@@ -46,9 +134,7 @@ export const AuthProvider: React.FC<{ children: React.ReactNode }> = ({ children
           }
         } catch (error) {
           // Invalid token
-          localStorage.removeItem('token');
-          localStorage.removeItem('user');
-          delete axios.defaults.headers.common['Authorization'];
+          clearSession();
         }
       }
       
@@ -58,22 +144,31 @@ export const AuthProvider: React.FC<{ children: React.ReactNode }> = ({ children
     checkAuth();
   }, []);
 
+  // Follow sessions refreshed or lost by the interceptor below
+  useEffect(() => {
+    onSessionChange = (session) => {
+      setUser(session ? session.user : null);
+      if (!session) {
+        router.push('/login');
+      }
+    };
+    return () => {
+      onSessionChange = () => {};
+    };
+  }, [router]);
+
   // Login
   const login = async (email: string, password: string) => {
     setLoading(true);
     
     try {
-      const response = await axios.post('/auth/login', { email, password });
-      const { token, user } = response.data;
-      
-      // Save token and user data
-      localStorage.setItem('token', token);
-      localStorage.setItem('user', JSON.stringify(user));
+      const response = await axios.post<Session>('/auth/login', { email, password });
+      const session: Session = response.data;
       
-      // Set default auth header
-      axios.defaults.headers.common['Authorization'] = `Bearer ${token}`;
+      // Save the tokens and user data
+      saveSession(session);
       
-      setUser(user);
+      setUser(session.user);
       setLoading(false);
       
       // Redirect to homepage
@@ -89,17 +184,13 @@ export const AuthProvider: React.FC<{ children: React.ReactNode }> = ({ children
     setLoading(true);
     
     try {
-      const response = await axios.post('/auth/register', { username, email, password });
-      const { token, user } = response.data;
+      const response = await axios.post<Session>('/auth/register', { username, email, password });
+      const session: Session = response.data;
       
-      // Save token and user data
-      localStorage.setItem('token', token);
-      localStorage.setItem('user', JSON.stringify(user));
+      // Save the tokens and user data
+      saveSession(session);
       
-      // Set default auth header
-      axios.defaults.headers.common['Authorization'] = `Bearer ${token}`;
-      
-      setUser(user);
+      setUser(session.user);
       setLoading(false);
       
       // Redirect to homepage
@@ -111,13 +202,17 @@ export const AuthProvider: React.FC<{ children: React.ReactNode }> = ({ children
   };
 
   // Logout
-  const logout = () => {
-    // Remove token and user data
-    localStorage.removeItem('token');
-    localStorage.removeItem('user');
+  const logout = async () => {
+    // Revoke the access token and the session of the refresh token; the user
+    // is logged out locally even if that fails
+    try {
+      await axios.post('/auth/logout', { refresh_token: localStorage.getItem('refresh_token') });
+    } catch (error) {
+      // The tokens may already have expired
+    }
     
-    // Remove auth header
-    delete axios.defaults.headers.common['Authorization'];
+    // Remove tokens and user data
+    clearSession();
     
     setUser(null);
     
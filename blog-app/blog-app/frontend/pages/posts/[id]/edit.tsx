@@ -3,13 +3,14 @@ import React, { useState, useEffect } from 'react';
 import Head from 'next/head';
 import { useRouter } from 'next/router';
 import axios from 'axios';
-import PostEditor from '../../../components/PostEditor';
+import PostEditor, { PostStatus } from '../../../components/PostEditor';
 import { useAuth } from '../../../context/AuthContext';
 
 interface Post {
   id: number;
   title: string;
   content: string;
+  status: PostStatus;
   author: string;
   author_id: number;
   created_at: string;
@@ -9,15 +9,23 @@ import { useRouter } from 'next/router';
 interface Post {
   id: number;
   title: string;
-  content: string;
+  excerpt: string;
+  reading_time: number;
   author: string;
   author_id: number;
   created_at: string;
   updated_at: string;
 }
 
+interface PostPage {
+  posts: Post[];
+  next_cursor?: string;
+}
+
 const PostsPage: React.FC = () => {
   const [posts, setPosts] = useState<Post[]>([]);
+  const [nextCursor, setNextCursor] = useState<string | null>(null);
+  const [loadingMore, setLoadingMore] = useState(false);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
   const { isAuthenticated, user } = useAuth();
@@ -31,8 +39,9 @@ const PostsPage: React.FC = () => {
       }
 
       try {
-        const response = await axios.get('/posts');
-        setPosts(response.data);
+        const response = await axios.get<PostPage>('/posts');
+        setPosts(response.data.posts);
+        setNextCursor(response.data.next_cursor || null);
         setError(null);
       } catch (err: any) {
         setError(err.response?.data || 'Failed to fetch posts');
@@ -51,6 +60,24 @@ const PostsPage: React.FC = () => {
     }
   }, [isAuthenticated, loading, router]);
 
+  // Fetch the next page of posts
+  const handleLoadMore = async () => {
+    if (!nextCursor) {
+      return;
+    }
+
+    setLoadingMore(true);
+    try {
+      const response = await axios.get<PostPage>('/posts', { params: { cursor: nextCursor } });
+      setPosts([...posts, ...response.data.posts]);
+      setNextCursor(response.data.next_cursor || null);
+    } catch (err: any) {
+      setError(err.response?.data || 'Failed to fetch posts');
+    } finally {
+      setLoadingMore(false);
+    }
+  };
+
   const handleDeletePost = async (id: number) => {
     if (window.confirm('Are you sure you want to delete this post?')) {
       try {
@@ -110,13 +137,10 @@ const PostsPage: React.FC = () => {
                     <span>By {post.author}</span>
                     <span className="mx-2">•</span>
                     <span>{new Date(post.created_at).toLocaleDateString()}</span>
+                    <span className="mx-2">•</span>
+                    <span>{post.reading_time} min read</span>
                   </div>
-                  <div 
-                    className="prose prose-sm mb-4 line-clamp-3"
-                    dangerouslySetInnerHTML={{ 
-                      __html: post.content.substring(0, 150) + '...' 
-                    }}
-                  />
+                  <p className="prose prose-sm mb-4 line-clamp-3">{post.excerpt}</p>
                   <div className="flex justify-between items-center">
                     <Link
                       href={`/posts/${post.id}`}
@@ -146,6 +170,18 @@ const PostsPage: React.FC = () => {
             ))}
           </div>
         )}
+
+        {nextCursor && (
+          <div className="text-center mt-6">
+            <button
+              onClick={handleLoadMore}
+              disabled={loadingMore}
+              className="px-4 py-2 bg-gray-200 text-gray-800 rounded hover:bg-gray-300 disabled:opacity-50"
+            >
+              {loadingMore ? 'Loading...' : 'Load more'}
+            </button>
+          </div>
+        )}
       </div>
     </>
   );